@@ -0,0 +1,94 @@
+package main
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// subBucketBits is the number of precision bits of each power of 2 range, ~3% relative error
+const subBucketBits = 5
+
+const (
+	numSubBuckets = 1 << subBucketBits
+	numBuckets    = (64 - subBucketBits + 1) * numSubBuckets
+)
+
+// histogram records latencies in nanoseconds with log-linear buckets, using a fixed amount of memory.
+// It is NOT thread safe, each worker has its own histogram, merged after finished
+type histogram struct {
+	counts [numBuckets]uint64
+	total  uint64
+	sum    uint64
+	max    uint64
+}
+
+func bucketIndex(v uint64) int {
+	if v < numSubBuckets {
+		return int(v)
+	}
+	shift := bits.Len64(v) - 1 - subBucketBits
+	return (shift+1)*numSubBuckets + int(v>>shift) - numSubBuckets
+}
+
+// bucketUpperBound returns the largest value belongs to the bucket
+func bucketUpperBound(index int) uint64 {
+	if index < numSubBuckets {
+		return uint64(index)
+	}
+	shift := index/numSubBuckets - 1
+	low := uint64(numSubBuckets+index%numSubBuckets) << shift
+	return low + (1 << shift) - 1
+}
+
+func (h *histogram) record(d time.Duration) {
+	v := uint64(0)
+	if d > 0 {
+		v = uint64(d)
+	}
+	h.counts[bucketIndex(v)]++
+	h.total++
+	h.sum += v
+	if v > h.max {
+		h.max = v
+	}
+}
+
+func (h *histogram) merge(other *histogram) {
+	for i, count := range other.counts {
+		h.counts[i] += count
+	}
+	h.total += other.total
+	h.sum += other.sum
+	if other.max > h.max {
+		h.max = other.max
+	}
+}
+
+func (h *histogram) mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return time.Duration(h.sum / h.total)
+}
+
+// percentile returns the upper bound of the bucket containing the **p** percentile, with **p** in [0, 100]
+func (h *histogram) percentile(p float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(p / 100 * float64(h.total)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	var count uint64
+	for i, c := range h.counts {
+		count += c
+		if count >= rank {
+			return time.Duration(min(bucketUpperBound(i), h.max))
+		}
+	}
+	return time.Duration(h.max)
+}
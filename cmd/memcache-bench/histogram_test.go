@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketIndex(t *testing.T) {
+	t.Run("exact for small values", func(t *testing.T) {
+		for v := uint64(0); v < 64; v++ {
+			assert.Equal(t, v, bucketUpperBound(bucketIndex(v)))
+		}
+	})
+
+	t.Run("contains value", func(t *testing.T) {
+		for _, v := range []uint64{64, 65, 1000, 123456, 987654321, 1 << 40, 1<<63 + 12345} {
+			index := bucketIndex(v)
+			assert.Less(t, index, numBuckets)
+			assert.GreaterOrEqual(t, bucketUpperBound(index), v)
+			if index > 0 {
+				assert.Less(t, bucketUpperBound(index-1), v)
+			}
+		}
+	})
+
+	t.Run("max value", func(t *testing.T) {
+		assert.Equal(t, numBuckets-1, bucketIndex(1<<64-1))
+		assert.Equal(t, uint64(1<<64-1), bucketUpperBound(numBuckets-1))
+	})
+}
+
+func TestHistogram(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var h histogram
+		assert.Equal(t, time.Duration(0), h.mean())
+		assert.Equal(t, time.Duration(0), h.percentile(99))
+	})
+
+	t.Run("percentiles", func(t *testing.T) {
+		var h histogram
+		for i := 1; i <= 1000; i++ {
+			h.record(time.Duration(i) * time.Microsecond)
+		}
+
+		assert.Equal(t, 500500*time.Nanosecond, h.mean())
+		assert.InEpsilon(t, float64(500*time.Microsecond), float64(h.percentile(50)), 0.04)
+		assert.InEpsilon(t, float64(990*time.Microsecond), float64(h.percentile(99)), 0.04)
+		assert.Equal(t, time.Millisecond, h.percentile(100))
+		assert.InEpsilon(t, float64(time.Microsecond), float64(h.percentile(0)), 0.04)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		var h1, h2 histogram
+		h1.record(10 * time.Microsecond)
+		h2.record(30 * time.Microsecond)
+		h2.record(-time.Microsecond)
+
+		h1.merge(&h2)
+		assert.Equal(t, uint64(3), h1.total)
+		assert.Equal(t, 40*time.Microsecond/3, h1.mean())
+		assert.Equal(t, 30*time.Microsecond, h1.percentile(100))
+		assert.Equal(t, time.Duration(0), h1.percentile(0))
+	})
+}
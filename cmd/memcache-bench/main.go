@@ -0,0 +1,218 @@
+// Command memcache-bench drives a configurable workload against a memcached server,
+// then reports the throughput and the latency percentiles, for tuning the options of memcache.Client.
+//
+// Usage:
+//
+//	memcache-bench -server=localhost:11211 -conns=4 -workers=64 -pipeline=8 -duration=30s \
+//		-keys=100000 -value-size=100:9,1000-10000:1 -get-ratio=0.9
+//
+// The -value-size flag is a comma separated list of "size[:weight]" entries, where size can be a range "min-max".
+// Keys are populated before the measurement unless -populate=false.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/QuangTung97/go-memcache/memcache"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+func parseConfig(args []string, stderr io.Writer) (benchConfig, bool, error) {
+	fs := flag.NewFlagSet("memcache-bench", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var conf benchConfig
+	fs.StringVar(&conf.server, "server", "localhost:11211", "address of the memcached server")
+	fs.IntVar(&conf.conns, "conns", 1, "number of connections of the client")
+	fs.IntVar(&conf.workers, "workers", 16, "number of concurrent goroutines issuing commands")
+	fs.IntVar(&conf.pipelineDepth, "pipeline", 1, "number of commands per pipeline batch of each worker")
+	fs.IntVar(&conf.writeLimit, "write-limit", 0, "value of memcache.WithWriteLimit, zero means the default")
+	fs.IntVar(&conf.maxCommandsPerBatch, "max-batch", 0,
+		"value of memcache.WithMaxCommandsPerBatch, zero means the default")
+
+	fs.DurationVar(&conf.duration, "duration", 10*time.Second, "duration of the measurement, zero means unlimited")
+	fs.Uint64Var(&conf.requests, "requests", 0, "max number of commands, zero means unlimited")
+
+	fs.IntVar(&conf.numKeys, "keys", 10000, "number of distinct keys")
+	fs.StringVar(&conf.keyPrefix, "key-prefix", "bench:", "prefix of the keys")
+	valueSizes := fs.String("value-size", "100", "value size distribution, e.g. 100:9,1000-10000:1")
+	fs.Float64Var(&conf.getRatio, "get-ratio", 0.9, "ratio of get commands, the others are set commands")
+	fs.BoolVar(&conf.populate, "populate", true, "set all the keys before the measurement")
+	fs.Int64Var(&conf.seed, "seed", time.Now().UnixNano(), "seed of the random generator")
+
+	jsonOutput := fs.Bool("json", false, "print the report as JSON")
+
+	if err := fs.Parse(args); err != nil {
+		return benchConfig{}, false, err
+	}
+	if fs.NArg() > 0 {
+		return benchConfig{}, false, fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	var err error
+	conf.valueSizes, err = parseSizeDistribution(*valueSizes)
+	if err != nil {
+		return benchConfig{}, false, err
+	}
+
+	if err := validateConfig(conf); err != nil {
+		return benchConfig{}, false, err
+	}
+	return conf, *jsonOutput, nil
+}
+
+func validateConfig(conf benchConfig) error {
+	switch {
+	case conf.conns <= 0:
+		return errors.New("conns must > 0")
+	case conf.workers <= 0:
+		return errors.New("workers must > 0")
+	case conf.pipelineDepth <= 0:
+		return errors.New("pipeline must > 0")
+	case conf.numKeys <= 0:
+		return errors.New("keys must > 0")
+	case conf.getRatio < 0 || conf.getRatio > 1:
+		return errors.New("get-ratio must be in [0, 1]")
+	case conf.duration <= 0 && conf.requests == 0:
+		return errors.New("one of duration or requests must be limited")
+	default:
+		return nil
+	}
+}
+
+type latencyReport struct {
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P99  time.Duration `json:"p99"`
+	P999 time.Duration `json:"p999"`
+	Max  time.Duration `json:"max"`
+}
+
+type report struct {
+	Server        string `json:"server"`
+	Conns         int    `json:"conns"`
+	Workers       int    `json:"workers"`
+	PipelineDepth int    `json:"pipeline_depth"`
+
+	Elapsed    time.Duration `json:"elapsed"`
+	Ops        uint64        `json:"ops"`
+	Throughput float64       `json:"throughput"` // ops per second
+
+	Gets     uint64  `json:"gets"`
+	Sets     uint64  `json:"sets"`
+	HitRatio float64 `json:"hit_ratio"`
+	Errors   uint64  `json:"errors"`
+	FirstErr string  `json:"first_error,omitempty"`
+
+	Latency latencyReport `json:"latency"` // in nanoseconds
+}
+
+func newReport(conf benchConfig, result *workerResult, elapsed time.Duration) report {
+	r := report{
+		Server:        conf.server,
+		Conns:         conf.conns,
+		Workers:       conf.workers,
+		PipelineDepth: conf.pipelineDepth,
+
+		Elapsed: elapsed,
+		Ops:     result.gets + result.sets,
+
+		Gets:   result.gets,
+		Sets:   result.sets,
+		Errors: result.errors,
+
+		Latency: latencyReport{
+			Mean: result.latency.mean(),
+			P50:  result.latency.percentile(50),
+			P90:  result.latency.percentile(90),
+			P99:  result.latency.percentile(99),
+			P999: result.latency.percentile(99.9),
+			Max:  result.latency.percentile(100),
+		},
+	}
+	if elapsed > 0 {
+		r.Throughput = float64(r.Ops) / elapsed.Seconds()
+	}
+	if r.Gets > 0 {
+		r.HitRatio = float64(result.hits) / float64(r.Gets)
+	}
+	if result.firstErr != nil {
+		r.FirstErr = result.firstErr.Error()
+	}
+	return r
+}
+
+func (r report) writeText(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "server: %s, conns: %d, workers: %d, pipeline depth: %d\n",
+		r.Server, r.Conns, r.Workers, r.PipelineDepth)
+	_, _ = fmt.Fprintf(w, "elapsed: %v, ops: %d, throughput: %.1f ops/s\n",
+		r.Elapsed.Round(time.Millisecond), r.Ops, r.Throughput)
+	_, _ = fmt.Fprintf(w, "gets: %d, sets: %d, hit ratio: %.2f%%, errors: %d\n",
+		r.Gets, r.Sets, r.HitRatio*100, r.Errors)
+	if r.FirstErr != "" {
+		_, _ = fmt.Fprintf(w, "first error: %s\n", r.FirstErr)
+	}
+	_, _ = fmt.Fprintf(w, "latency: mean=%v p50=%v p90=%v p99=%v p99.9=%v max=%v\n",
+		r.Latency.Mean, r.Latency.P50, r.Latency.P90, r.Latency.P99, r.Latency.P999, r.Latency.Max)
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	conf, jsonOutput, err := parseConfig(args, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitUsage
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	client, err := memcache.New(conf.server, conf.conns, conf.clientOptions()...)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	defer func() { _ = client.Close() }()
+
+	b := newBenchmark(conf, client)
+	if conf.populate {
+		if err := b.populate(ctx); err != nil {
+			_, _ = fmt.Fprintln(stderr, "populate keys:", err)
+			return exitError
+		}
+	}
+
+	result, elapsed := b.run(ctx)
+	r := newReport(conf, result, elapsed)
+
+	if jsonOutput {
+		_ = json.NewEncoder(stdout).Encode(r)
+	} else {
+		r.writeText(stdout)
+	}
+
+	if r.Errors > 0 {
+		return exitError
+	}
+	return exitOK
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
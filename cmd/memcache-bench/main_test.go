@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/QuangTung97/go-memcache/memcache/memcachetest"
+)
+
+func TestRun(t *testing.T) {
+	s, err := memcachetest.NewServer()
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = s.Close() }()
+
+	t.Run("json report", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), []string{
+			"-server=" + s.Addr(),
+			"-conns=2",
+			"-workers=4",
+			"-pipeline=3",
+			"-requests=1000",
+			"-duration=0",
+			"-keys=50",
+			"-value-size=10:3,100-200:1",
+			"-get-ratio=0.8",
+			"-max-batch=2",
+			"-json",
+		}, &stdout, &stderr)
+
+		assert.Equal(t, exitOK, code)
+		assert.Equal(t, "", stderr.String())
+
+		var r report
+		assert.Equal(t, nil, json.Unmarshal(stdout.Bytes(), &r))
+
+		assert.Equal(t, uint64(1000), r.Ops)
+		assert.Equal(t, r.Ops, r.Gets+r.Sets)
+		assert.InDelta(t, 800, r.Gets, 100)
+		assert.Equal(t, 1.0, r.HitRatio)
+		assert.Equal(t, uint64(0), r.Errors)
+		assert.Greater(t, r.Throughput, 0.0)
+		assert.LessOrEqual(t, r.Latency.P50, r.Latency.P99)
+		assert.LessOrEqual(t, r.Latency.P99, r.Latency.Max)
+	})
+
+	t.Run("text report without populate", func(t *testing.T) {
+		s.FlushAll()
+
+		var stdout, stderr bytes.Buffer
+		code := run(context.Background(), []string{
+			"-server=" + s.Addr(),
+			"-requests=100",
+			"-get-ratio=1",
+			"-populate=false",
+		}, &stdout, &stderr)
+
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout.String(), "gets: 100, sets: 0, hit ratio: 0.00%, errors: 0\n")
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"-workers=0"},
+			{"-get-ratio=1.5"},
+			{"-value-size=abc"},
+			{"-duration=0"},
+			{"extra"},
+		} {
+			var stdout, stderr bytes.Buffer
+			code := run(context.Background(), args, &stdout, &stderr)
+			assert.Equal(t, exitUsage, code, args)
+			assert.NotEqual(t, "", stderr.String(), args)
+		}
+	})
+}
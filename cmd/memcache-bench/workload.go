@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/QuangTung97/go-memcache/memcache"
+)
+
+type sizeChoice struct {
+	min    int
+	max    int
+	weight float64
+}
+
+// sizeDistribution is a weighted list of value size ranges, e.g. "100:9,1000-10000:1" means
+// 90% of values are 100 bytes and 10% are uniformly distributed between 1000 and 10000 bytes
+type sizeDistribution struct {
+	choices     []sizeChoice
+	totalWeight float64
+}
+
+func parseSizeRange(s string) (minSize int, maxSize int, err error) {
+	minStr, maxStr, isRange := strings.Cut(s, "-")
+	minSize, err = strconv.Atoi(minStr)
+	if err != nil || minSize < 0 {
+		return 0, 0, fmt.Errorf("invalid value size: %s", s)
+	}
+	if !isRange {
+		return minSize, minSize, nil
+	}
+
+	maxSize, err = strconv.Atoi(maxStr)
+	if err != nil || maxSize < minSize {
+		return 0, 0, fmt.Errorf("invalid value size range: %s", s)
+	}
+	return minSize, maxSize, nil
+}
+
+func parseSizeDistribution(s string) (sizeDistribution, error) {
+	var d sizeDistribution
+	for _, entry := range strings.Split(s, ",") {
+		sizeStr, weightStr, hasWeight := strings.Cut(strings.TrimSpace(entry), ":")
+
+		minSize, maxSize, err := parseSizeRange(sizeStr)
+		if err != nil {
+			return sizeDistribution{}, err
+		}
+
+		weight := 1.0
+		if hasWeight {
+			weight, err = strconv.ParseFloat(weightStr, 64)
+			if err != nil || weight <= 0 {
+				return sizeDistribution{}, fmt.Errorf("invalid value size weight: %s", entry)
+			}
+		}
+
+		d.choices = append(d.choices, sizeChoice{min: minSize, max: maxSize, weight: weight})
+		d.totalWeight += weight
+	}
+	return d, nil
+}
+
+func (d sizeDistribution) maxSize() int {
+	result := 0
+	for _, c := range d.choices {
+		result = max(result, c.max)
+	}
+	return result
+}
+
+func (d sizeDistribution) pick(rnd *rand.Rand) int {
+	w := rnd.Float64() * d.totalWeight
+	c := d.choices[len(d.choices)-1]
+	for _, choice := range d.choices {
+		if w < choice.weight {
+			c = choice
+			break
+		}
+		w -= choice.weight
+	}
+	return c.min + rnd.Intn(c.max-c.min+1)
+}
+
+type benchConfig struct {
+	server string
+
+	conns               int
+	workers             int
+	pipelineDepth       int
+	writeLimit          int
+	maxCommandsPerBatch int
+
+	duration time.Duration
+	requests uint64
+
+	numKeys    int
+	keyPrefix  string
+	valueSizes sizeDistribution
+	getRatio   float64
+	populate   bool
+	seed       int64
+}
+
+func (conf benchConfig) clientOptions() []memcache.Option {
+	var options []memcache.Option
+	if conf.writeLimit > 0 {
+		options = append(options, memcache.WithWriteLimit(conf.writeLimit))
+	}
+	if conf.maxCommandsPerBatch > 0 {
+		options = append(options, memcache.WithMaxCommandsPerBatch(conf.maxCommandsPerBatch))
+	}
+	return options
+}
+
+type workerResult struct {
+	gets   uint64
+	sets   uint64
+	hits   uint64
+	errors uint64
+
+	firstErr error
+	latency  histogram
+}
+
+func (r *workerResult) addError(err error) {
+	r.errors++
+	if r.firstErr == nil {
+		r.firstErr = err
+	}
+}
+
+func (r *workerResult) merge(other *workerResult) {
+	r.gets += other.gets
+	r.sets += other.sets
+	r.hits += other.hits
+	r.errors += other.errors
+	if r.firstErr == nil {
+		r.firstErr = other.firstErr
+	}
+	r.latency.merge(&other.latency)
+}
+
+type benchmark struct {
+	conf   benchConfig
+	client *memcache.Client
+
+	// values are prefixes of this random data
+	data []byte
+
+	issued atomic.Uint64
+}
+
+func newBenchmark(conf benchConfig, client *memcache.Client) *benchmark {
+	data := make([]byte, conf.valueSizes.maxSize())
+	rnd := rand.New(rand.NewSource(conf.seed))
+	for i := range data {
+		data[i] = 'a' + byte(rnd.Intn(26))
+	}
+
+	return &benchmark{
+		conf:   conf,
+		client: client,
+		data:   data,
+	}
+}
+
+func (b *benchmark) key(index int) string {
+	return b.conf.keyPrefix + strconv.Itoa(index)
+}
+
+// acquire returns the number of commands the next batch can issue, zero when the requests limit is reached
+func (b *benchmark) acquire() int {
+	depth := b.conf.pipelineDepth
+	if b.conf.requests == 0 {
+		return depth
+	}
+
+	end := b.issued.Add(uint64(depth))
+	begin := end - uint64(depth)
+	if begin >= b.conf.requests {
+		return 0
+	}
+	return int(min(end, b.conf.requests) - begin)
+}
+
+// populate sets every key once, so that the gets of the benchmark can hit
+func (b *benchmark) populate(ctx context.Context) error {
+	var wg sync.WaitGroup
+	errs := make([]error, b.conf.workers)
+
+	for w := 0; w < b.conf.workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			errs[w] = b.populateWorker(ctx, w)
+		}(w)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (b *benchmark) populateWorker(ctx context.Context, w int) error {
+	rnd := rand.New(rand.NewSource(b.conf.seed + int64(w) + 1))
+
+	p := b.client.Pipeline()
+	defer p.Finish()
+
+	fns := make([]func() (memcache.MSetResponse, error), 0, b.conf.pipelineDepth)
+	for i := w; i < b.conf.numKeys && ctx.Err() == nil; {
+		fns = fns[:0]
+		for ; i < b.conf.numKeys && len(fns) < b.conf.pipelineDepth; i += b.conf.workers {
+			value := b.data[:b.conf.valueSizes.pick(rnd)]
+			fns = append(fns, p.MSet(b.key(i), value, memcache.MSetOptions{}))
+		}
+		for _, fn := range fns {
+			if _, err := fn(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type pendingOp struct {
+	get func() (memcache.MGetResponse, error)
+	set func() (memcache.MSetResponse, error)
+}
+
+func (b *benchmark) issueOp(p *memcache.Pipeline, rnd *rand.Rand) pendingOp {
+	key := b.key(rnd.Intn(b.conf.numKeys))
+	if rnd.Float64() < b.conf.getRatio {
+		return pendingOp{get: p.MGet(key, memcache.MGetOptions{})}
+	}
+	value := b.data[:b.conf.valueSizes.pick(rnd)]
+	return pendingOp{set: p.MSet(key, value, memcache.MSetOptions{})}
+}
+
+func (r *workerResult) waitOp(op pendingOp) {
+	if op.get != nil {
+		r.gets++
+		resp, err := op.get()
+		if err != nil {
+			r.addError(err)
+			return
+		}
+		if resp.Type == memcache.MGetResponseTypeVA {
+			r.hits++
+		}
+		return
+	}
+
+	r.sets++
+	if _, err := op.set(); err != nil {
+		r.addError(err)
+	}
+}
+
+// runWorker issues batches of **pipelineDepth** commands, the latency of each command is measured
+// from the start of its batch to the time its response is returned
+func (b *benchmark) runWorker(ctx context.Context, w int) *workerResult {
+	rnd := rand.New(rand.NewSource(b.conf.seed + int64(b.conf.workers+w) + 1))
+	result := &workerResult{}
+
+	p := b.client.Pipeline()
+	defer p.Finish()
+
+	ops := make([]pendingOp, 0, b.conf.pipelineDepth)
+	for ctx.Err() == nil {
+		n := b.acquire()
+		if n == 0 {
+			break
+		}
+
+		start := time.Now()
+		ops = ops[:0]
+		for i := 0; i < n; i++ {
+			ops = append(ops, b.issueOp(p, rnd))
+		}
+		for _, op := range ops {
+			result.waitOp(op)
+			result.latency.record(time.Since(start))
+		}
+	}
+	return result
+}
+
+// run runs the workers until the duration elapsed or the requests limit is reached
+func (b *benchmark) run(ctx context.Context) (*workerResult, time.Duration) {
+	if b.conf.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, b.conf.duration)
+		defer cancel()
+	}
+
+	results := make([]*workerResult, b.conf.workers)
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for w := 0; w < b.conf.workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			results[w] = b.runWorker(ctx, w)
+		}(w)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	total := &workerResult{}
+	for _, r := range results {
+		total.merge(r)
+	}
+	return total, elapsed
+}
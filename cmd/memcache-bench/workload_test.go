@@ -0,0 +1,75 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSizeDistribution(t *testing.T) {
+	t.Run("fixed", func(t *testing.T) {
+		d, err := parseSizeDistribution("100")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, sizeDistribution{
+			choices:     []sizeChoice{{min: 100, max: 100, weight: 1}},
+			totalWeight: 1,
+		}, d)
+		assert.Equal(t, 100, d.maxSize())
+	})
+
+	t.Run("weighted ranges", func(t *testing.T) {
+		d, err := parseSizeDistribution("100:9, 1000-10000:1")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, sizeDistribution{
+			choices: []sizeChoice{
+				{min: 100, max: 100, weight: 9},
+				{min: 1000, max: 10000, weight: 1},
+			},
+			totalWeight: 10,
+		}, d)
+		assert.Equal(t, 10000, d.maxSize())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, s := range []string{"", "abc", "-1", "100-50", "100-x", "100:0", "100:x"} {
+			_, err := parseSizeDistribution(s)
+			assert.Error(t, err, s)
+		}
+	})
+}
+
+func TestSizeDistribution_Pick(t *testing.T) {
+	d, err := parseSizeDistribution("10:3,1000-2000:1")
+	assert.Equal(t, nil, err)
+
+	rnd := rand.New(rand.NewSource(1))
+	small := 0
+	for i := 0; i < 10000; i++ {
+		size := d.pick(rnd)
+		if size == 10 {
+			small++
+			continue
+		}
+		assert.GreaterOrEqual(t, size, 1000)
+		assert.LessOrEqual(t, size, 2000)
+	}
+	assert.InDelta(t, 7500, small, 300)
+}
+
+func TestBenchmark_Acquire(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		b := &benchmark{conf: benchConfig{pipelineDepth: 4}}
+		assert.Equal(t, 4, b.acquire())
+		assert.Equal(t, 4, b.acquire())
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		b := &benchmark{conf: benchConfig{pipelineDepth: 4, requests: 10}}
+		assert.Equal(t, 4, b.acquire())
+		assert.Equal(t, 4, b.acquire())
+		assert.Equal(t, 2, b.acquire())
+		assert.Equal(t, 0, b.acquire())
+		assert.Equal(t, 0, b.acquire())
+	})
+}
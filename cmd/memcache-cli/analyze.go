@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/QuangTung97/go-memcache/memcache/keyspace"
+	"github.com/QuangTung97/go-memcache/memcache/stats"
+)
+
+const totalGroupName = "TOTAL"
+
+func runAnalyze(c *cli, args []string) error {
+	flags := newFlagSet("analyze", c.stderr)
+	separator := flags.String("separator", ":", "separator of the key prefixes")
+	depth := flags.Int("depth", 1, "number of segments of the key prefixes")
+	var patterns []*regexp.Regexp
+	flags.Func("pattern", "group the keys by regular expressions instead of prefixes, can be repeated",
+		func(s string) error {
+			pattern, err := regexp.Compile(s)
+			if err != nil {
+				return err
+			}
+			patterns = append(patterns, pattern)
+			return nil
+		},
+	)
+	classList := flags.String("classes", "", "comma separated list of slab classes, empty means all")
+	maxGroups := flags.Int("max-groups", 1000, "max number of groups, the others are grouped as "+keyspace.OtherGroup)
+	top := flags.Int("top", 20, "number of the largest groups printed, zero means all")
+	jsonOutput := flags.Bool("json", false, "print the report as JSON")
+	if err := parseArgs(flags, args, "", 0, 0); err != nil {
+		return err
+	}
+
+	classes, err := parseSlabClasses(*classList)
+	if err != nil {
+		_, _ = fmt.Fprintln(c.stderr, err)
+		return errUsage
+	}
+
+	options := []keyspace.Option{
+		keyspace.WithPrefix(*separator, *depth),
+		keyspace.WithMaxGroups(*maxGroups),
+	}
+	if len(patterns) > 0 {
+		options = append(options, keyspace.WithPatterns(patterns...))
+	}
+	analyzer := keyspace.NewAnalyzer(options...)
+
+	err = c.withStats(func(addr string, client *stats.Client) error {
+		dumpOptions := stats.MetaDumpOptions{Classes: classes}
+		return client.MetaDump(context.Background(), dumpOptions, func(key stats.MetaDumpKey) bool {
+			analyzer.Add(key)
+			return true
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	report := analyzer.Report()
+	if *top > 0 && len(report.Groups) > *top {
+		report.Groups = report.Groups[:*top]
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return printReport(c.stdout, report)
+}
+
+func formatPercent(ratio float64) string {
+	return strconv.FormatFloat(ratio*100, 'f', 1, 64) + "%"
+}
+
+func bucketLabels(buckets []keyspace.Bucket) []string {
+	labels := make([]string, 0, len(buckets))
+	for _, b := range buckets {
+		labels = append(labels, b.Label)
+	}
+	return labels
+}
+
+func bucketCounts(buckets []keyspace.Bucket) []string {
+	counts := make([]string, 0, len(buckets))
+	for _, b := range buckets {
+		counts = append(counts, strconv.FormatUint(b.Count, 10))
+	}
+	return counts
+}
+
+// printReport prints the summary, the TTL histograms and the idle time histograms as three tables
+func printReport(out io.Writer, report keyspace.Report) error {
+	total := report.Total
+	total.Name = totalGroupName
+	groups := append(report.Groups[:len(report.Groups):len(report.Groups)], total)
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	printRow := func(columns ...string) {
+		_, _ = fmt.Fprintln(w, strings.Join(columns, "\t"))
+	}
+
+	printRow("GROUP", "ITEMS", "BYTES", "NEVER FETCHED", "NO EXPIRY")
+	for _, g := range groups {
+		printRow(
+			g.Name,
+			strconv.FormatUint(g.Items, 10),
+			strconv.FormatUint(g.Bytes, 10),
+			formatPercent(g.NeverFetchedRatio),
+			strconv.FormatUint(g.NoExpiry, 10),
+		)
+	}
+
+	printRow()
+	printRow(append([]string{"TTL"}, bucketLabels(total.TTL)...)...)
+	for _, g := range groups {
+		printRow(append([]string{g.Name}, bucketCounts(g.TTL)...)...)
+	}
+
+	printRow()
+	printRow(append([]string{"IDLE"}, bucketLabels(total.Idle)...)...)
+	for _, g := range groups {
+		printRow(append([]string{g.Name}, bucketCounts(g.Idle)...)...)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/QuangTung97/go-memcache/memcache"
+	"github.com/QuangTung97/go-memcache/memcache/snapshot"
+	"github.com/QuangTung97/go-memcache/memcache/stats"
+)
+
+var errKeyNotFound = errors.New("key not found")
+
+// parseArgs parses the flags of a command, then checks the number of positional arguments
+func parseArgs(fs *flag.FlagSet, args []string, usage string, minArgs int, maxArgs int) error {
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(fs.Output(), "Usage: memcache-cli %s [flags] %s\n", fs.Name(), usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() < minArgs || fs.NArg() > maxArgs {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+func runGet(c *cli, args []string) error {
+	fs := newFlagSet("get", c.stderr)
+	if err := parseArgs(fs, args, "<key>", 1, 1); err != nil {
+		return err
+	}
+	key := fs.Arg(0)
+
+	return c.withPipeline(func(addr string, p *memcache.Pipeline) error {
+		resp, err := p.MGet(key, memcache.MGetOptions{})()
+		if err != nil {
+			return err
+		}
+		if resp.Type != memcache.MGetResponseTypeVA {
+			return errKeyNotFound
+		}
+		c.printf(addr, "%s\n", resp.Data)
+		return nil
+	})
+}
+
+func formatMSetResponseType(t memcache.MSetResponseType) string {
+	switch t {
+	case memcache.MSetResponseTypeHD:
+		return "STORED"
+	case memcache.MSetResponseTypeNS:
+		return "NOT_STORED"
+	case memcache.MSetResponseTypeEX:
+		return "EXISTS"
+	case memcache.MSetResponseTypeNF:
+		return "NOT_FOUND"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+func runSet(c *cli, args []string) error {
+	fs := newFlagSet("set", c.stderr)
+	ttl := fs.Uint("ttl", 0, "TTL in seconds, zero means no expiration")
+	cas := fs.Uint64("cas", 0, "only set when the CAS value of the key matches")
+	if err := parseArgs(fs, args, "<key> [value]", 1, 2); err != nil {
+		return err
+	}
+	key := fs.Arg(0)
+
+	var value []byte
+	if fs.NArg() == 2 {
+		value = []byte(fs.Arg(1))
+	} else {
+		data, err := io.ReadAll(c.stdin)
+		if err != nil {
+			return err
+		}
+		value = data
+	}
+
+	return c.withPipeline(func(addr string, p *memcache.Pipeline) error {
+		resp, err := p.MSet(key, value, memcache.MSetOptions{
+			CAS: *cas,
+			TTL: uint32(*ttl),
+		})()
+		if err != nil {
+			return err
+		}
+		c.printf(addr, "%s\n", formatMSetResponseType(resp.Type))
+		return nil
+	})
+}
+
+func formatMDelResponseType(t memcache.MDelResponseType) string {
+	switch t {
+	case memcache.MDelResponseTypeHD:
+		return "DELETED"
+	case memcache.MDelResponseTypeNF:
+		return "NOT_FOUND"
+	case memcache.MDelResponseTypeEX:
+		return "EXISTS"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+func runDel(c *cli, args []string) error {
+	fs := newFlagSet("del", c.stderr)
+	cas := fs.Uint64("cas", 0, "only delete when the CAS value of the key matches")
+	if err := parseArgs(fs, args, "<key>", 1, 1); err != nil {
+		return err
+	}
+	key := fs.Arg(0)
+
+	return c.withPipeline(func(addr string, p *memcache.Pipeline) error {
+		resp, err := p.MDel(key, memcache.MDelOptions{CAS: *cas})()
+		if err != nil {
+			return err
+		}
+		c.printf(addr, "%s\n", formatMDelResponseType(resp.Type))
+		return nil
+	})
+}
+
+func runTouch(c *cli, args []string) error {
+	fs := newFlagSet("touch", c.stderr)
+	if err := parseArgs(fs, args, "<key> <ttl>", 2, 2); err != nil {
+		return err
+	}
+	key := fs.Arg(0)
+
+	ttl, err := strconv.ParseUint(fs.Arg(1), 10, 32)
+	if err != nil || ttl == 0 {
+		_, _ = fmt.Fprintf(c.stderr, "invalid ttl: %s, must be a positive number of seconds\n", fs.Arg(1))
+		return errUsage
+	}
+
+	return c.withPipeline(func(addr string, p *memcache.Pipeline) error {
+		resp, err := p.MTouch(key, uint32(ttl))()
+		if err != nil {
+			return err
+		}
+		if resp.Type != memcache.MGetResponseTypeHD {
+			c.printf(addr, "NOT_FOUND\n")
+			return nil
+		}
+		c.printf(addr, "TOUCHED\n")
+		return nil
+	})
+}
+
+func runVersion(c *cli, args []string) error {
+	fs := newFlagSet("version", c.stderr)
+	if err := parseArgs(fs, args, "", 0, 0); err != nil {
+		return err
+	}
+
+	return c.withPipeline(func(addr string, p *memcache.Pipeline) error {
+		resp, err := p.Version()()
+		if err != nil {
+			return err
+		}
+		c.printf(addr, "%s\n", resp.Version)
+		return nil
+	})
+}
+
+func getStats(client *stats.Client, kind string) (any, error) {
+	switch kind {
+	case "":
+		return client.GetGeneralStats()
+	case "slabs":
+		return client.GetSlabsStats()
+	case "items":
+		return client.GetSlabItemsStats()
+	default:
+		return client.GetSettingsStats()
+	}
+}
+
+func runStats(c *cli, args []string) error {
+	fs := newFlagSet("stats", c.stderr)
+	if err := parseArgs(fs, args, "[slabs|items|settings]", 0, 1); err != nil {
+		return err
+	}
+	kind := fs.Arg(0)
+	switch kind {
+	case "", "slabs", "items", "settings":
+	default:
+		fs.Usage()
+		return errUsage
+	}
+
+	results := map[string]any{}
+	err := c.withStats(func(addr string, client *stats.Client) error {
+		result, err := getStats(client, kind)
+		if err != nil {
+			return err
+		}
+		results[addr] = result
+		return nil
+	})
+
+	// keyed by the server addresses when there is more than one server
+	var output any = results
+	if len(c.conf.servers) == 1 {
+		output = results[c.conf.servers[0]]
+	}
+	if len(results) > 0 {
+		data, jsonErr := json.MarshalIndent(output, "", "  ")
+		if jsonErr != nil {
+			return jsonErr
+		}
+		_, _ = fmt.Fprintf(c.stdout, "%s\n", data)
+	}
+	return err
+}
+
+type dumpLine struct {
+	Server string `json:"server,omitempty"`
+
+	Key   string `json:"key"`
+	Exp   int64  `json:"exp"`
+	LA    int64  `json:"la"`
+	CAS   uint64 `json:"cas"`
+	Fetch bool   `json:"fetch"`
+	Class uint32 `json:"cls"`
+	Size  uint32 `json:"size"`
+}
+
+func parseSlabClasses(s string) ([]uint32, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var classes []uint32
+	for _, part := range strings.Split(s, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slab class: %s", part)
+		}
+		classes = append(classes, uint32(id))
+	}
+	return classes, nil
+}
+
+func runDump(c *cli, args []string) error {
+	fs := newFlagSet("dump", c.stderr)
+	classList := fs.String("classes", "", "comma separated list of slab classes, empty means all")
+	hash := fs.Bool("hash", false, "walk the hash table instead of the LRUs")
+	if err := parseArgs(fs, args, "", 0, 0); err != nil {
+		return err
+	}
+
+	classes, err := parseSlabClasses(*classList)
+	if err != nil {
+		_, _ = fmt.Fprintln(c.stderr, err)
+		return errUsage
+	}
+	options := stats.MetaDumpOptions{
+		Classes: classes,
+		Hash:    *hash,
+	}
+
+	enc := json.NewEncoder(c.stdout)
+	return c.withStats(func(addr string, client *stats.Client) error {
+		server := ""
+		if len(c.conf.servers) > 1 {
+			server = addr
+		}
+
+		var encodeErr error
+		err := client.MetaDump(context.Background(), options, func(key stats.MetaDumpKey) bool {
+			encodeErr = enc.Encode(dumpLine{
+				Server: server,
+
+				Key:   key.Key,
+				Exp:   key.Exp,
+				LA:    key.LA,
+				CAS:   key.CAS,
+				Fetch: key.Fetch,
+				Class: key.Class,
+				Size:  key.Size,
+			})
+			return encodeErr == nil
+		})
+		if encodeErr != nil {
+			return encodeErr
+		}
+		return err
+	})
+}
+
+func runFlush(c *cli, args []string) error {
+	fs := newFlagSet("flush", c.stderr)
+	delay := fs.Duration("delay", 0, "invalidates the items after the delay (with seconds precision)")
+	if err := parseArgs(fs, args, "", 0, 0); err != nil {
+		return err
+	}
+
+	return c.forEachServer(func(addr string) error {
+		client := stats.NewAdminClient(addr, c.statsOptions()...)
+		defer func() { _ = client.Close() }()
+
+		if err := client.FlushAll(*delay); err != nil {
+			return err
+		}
+		c.printf(addr, "OK\n")
+		return nil
+	})
+}
+
+// singleServer returns the only server, for the commands that can NOT run on multiple servers
+func (c *cli) singleServer(name string) (string, error) {
+	if len(c.conf.servers) != 1 {
+		_, _ = fmt.Fprintf(c.stderr, "%s requires exactly one server\n", name)
+		return "", errUsage
+	}
+	return c.conf.servers[0], nil
+}
+
+func createOutput(c *cli, file string) (io.WriteCloser, error) {
+	if file == "-" {
+		return nopWriteCloser{Writer: c.stdout}, nil
+	}
+	return os.Create(file)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func runExport(c *cli, args []string) error {
+	fs := newFlagSet("export", c.stderr)
+	batchSize := fs.Int("batch", 100, "number of keys fetched per pipeline")
+	if err := parseArgs(fs, args, "<file|->", 1, 1); err != nil {
+		return err
+	}
+	addr, err := c.singleServer("export")
+	if err != nil {
+		return err
+	}
+
+	client, err := c.newMemcacheClient(addr)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = client.Close() }()
+
+	dumper := stats.New(addr, c.statsOptions()...)
+	defer func() { _ = dumper.Close() }()
+
+	out, err := createOutput(c, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	result, err := snapshot.Export(context.Background(), dumper, client, out, snapshot.WithBatchSize(*batchSize))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// stdout can be the snapshot file
+	_, _ = fmt.Fprintf(c.stderr, "dumped: %d, written: %d, missed: %d\n", result.Dumped, result.Written, result.Missed)
+	return nil
+}
+
+func runRestore(c *cli, args []string) error {
+	fs := newFlagSet("restore", c.stderr)
+	batchSize := fs.Int("batch", 100, "number of items set per pipeline")
+	rate := fs.Int("rate", 0, "max number of items restored per second, zero means unlimited")
+	ignoreElapsed := fs.Bool("ignore-elapsed", false, "do NOT subtract the time elapsed since the export")
+	if err := parseArgs(fs, args, "<file|->", 1, 1); err != nil {
+		return err
+	}
+	addr, err := c.singleServer("restore")
+	if err != nil {
+		return err
+	}
+
+	options := []snapshot.Option{
+		snapshot.WithBatchSize(*batchSize),
+		snapshot.WithRateLimit(*rate),
+	}
+	if *ignoreElapsed {
+		options = append(options, snapshot.WithIgnoreElapsed())
+	}
+
+	in := c.stdin
+	if file := fs.Arg(0); file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+
+	client, err := c.newMemcacheClient(addr)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = client.Close() }()
+
+	result, err := snapshot.Restore(context.Background(), client, in, options...)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(c.stdout, "read: %d, stored: %d, expired: %d, not stored: %d\n",
+		result.Read, result.Stored, result.Expired, result.NotStored)
+	return nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/QuangTung97/go-memcache/memcache"
+	"github.com/QuangTung97/go-memcache/memcache/netconn"
+	"github.com/QuangTung97/go-memcache/memcache/stats"
+)
+
+type cli struct {
+	conf     config
+	dialFunc netconn.DialFunc
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func newTLSConfig(flags tlsFlags) (*tls.Config, error) {
+	conf := &tls.Config{
+		ServerName:         flags.serverName,
+		InsecureSkipVerify: flags.skipVerify,
+	}
+
+	if flags.caFile != "" {
+		data, err := os.ReadFile(flags.caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in: %s", flags.caFile)
+		}
+		conf.RootCAs = pool
+	}
+
+	if flags.certFile != "" || flags.keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(flags.certFile, flags.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
+
+// newDialFunc does the TLS handshake before the authentication, see netconn.NewTLSDialFunc
+func newDialFunc(conf config) (netconn.DialFunc, error) {
+	dialFunc := netconn.DialFunc(net.DialTimeout)
+
+	if conf.tls.enabled {
+		tlsConf, err := newTLSConfig(conf.tls)
+		if err != nil {
+			return nil, err
+		}
+		dialFunc = netconn.NewTLSDialFunc(dialFunc, tlsConf)
+	}
+
+	if conf.username != "" {
+		auth, err := netconn.NewPasswordAuth(conf.username, conf.password)
+		if err != nil {
+			return nil, err
+		}
+		dialFunc = auth.GetDialFunc(dialFunc)
+	}
+	return dialFunc, nil
+}
+
+func newCLI(conf config, stdin io.Reader, stdout io.Writer, stderr io.Writer) (*cli, error) {
+	dialFunc, err := newDialFunc(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &cli{
+		conf:     conf,
+		dialFunc: dialFunc,
+
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}, nil
+}
+
+func (c *cli) netConnOptions() []netconn.Option {
+	return []netconn.Option{
+		netconn.WithDialFunc(c.dialFunc),
+		netconn.WithConnectTimeout(c.conf.timeout),
+		netconn.WithReadTimeout(c.conf.timeout),
+		netconn.WithWriteTimeout(c.conf.timeout),
+	}
+}
+
+func (c *cli) newMemcacheClient(addr string) (*memcache.Client, error) {
+	return memcache.New(addr, 1, memcache.WithNetConnOptions(c.netConnOptions()...))
+}
+
+func (c *cli) statsOptions() []stats.Option {
+	return []stats.Option{
+		stats.WithNetConnOptions(c.netConnOptions()...),
+	}
+}
+
+// prefix is prepended to the output lines of a server, empty when there is only one server
+func (c *cli) prefix(addr string) string {
+	if len(c.conf.servers) == 1 {
+		return ""
+	}
+	return addr + ": "
+}
+
+// forEachServer runs **fn** on every server, even when some of them failed
+func (c *cli) forEachServer(fn func(addr string) error) error {
+	var errList []error
+	for _, addr := range c.conf.servers {
+		if err := fn(addr); err != nil {
+			if len(c.conf.servers) > 1 {
+				err = fmt.Errorf("%s: %w", addr, err)
+			}
+			errList = append(errList, err)
+		}
+	}
+	return errors.Join(errList...)
+}
+
+// withPipeline runs **fn** on every server with a pipeline of a new client
+func (c *cli) withPipeline(fn func(addr string, p *memcache.Pipeline) error) error {
+	return c.forEachServer(func(addr string) error {
+		client, err := c.newMemcacheClient(addr)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = client.Close() }()
+
+		p := client.Pipeline()
+		defer p.Finish()
+
+		return fn(addr, p)
+	})
+}
+
+// withStats runs **fn** on every server with a new stats client
+func (c *cli) withStats(fn func(addr string, client *stats.Client) error) error {
+	return c.forEachServer(func(addr string) error {
+		client := stats.New(addr, c.statsOptions()...)
+		defer func() { _ = client.Close() }()
+		return fn(addr, client)
+	})
+}
+
+func (c *cli) printf(addr string, format string, args ...any) {
+	_, _ = io.WriteString(c.stdout, c.prefix(addr))
+	_, _ = fmt.Fprintf(c.stdout, format, args...)
+}
@@ -0,0 +1,168 @@
+// Command memcache-cli runs everyday cache operations against one or more memcached servers.
+//
+// Usage:
+//
+//	memcache-cli [flags] <command> [args]
+//
+// Commands:
+//
+//	get <key>                            prints the value of the key
+//	set [-ttl N] [-cas N] <key> [value]  sets the value, read from stdin if the value is omitted
+//	del [-cas N] <key>                   deletes the key
+//	touch <key> <ttl>                    updates the TTL (in seconds) of the key
+//	version                              prints the version of the servers
+//	stats [slabs|items|settings]         prints the stats of the servers as JSON
+//	dump [-classes 1,2] [-hash]          dumps the keys of the servers as JSON lines (metadump)
+//	flush [-delay 30s]                   invalidates all the items of the servers
+//	export [-batch N] <file|->           exports the items of the server to a snapshot file
+//	restore [-rate N] <file|->           restores a snapshot file into the server
+//	migrate -to <addr> [-checkpoint F]   copies the items of the server to another server
+//	analyze [-depth N] [-pattern RE]     reports the items of the servers grouped by key prefix or pattern
+//
+// Every command, except export, restore & migrate, runs on each server of the -servers flag in order.
+// When there is more than one server, the output lines are prefixed by the server address,
+// the stats are keyed by the server address and the dumped keys contain a "server" field.
+// The keys of all servers are aggregated in one report by analyze.
+//
+// Authentication is enabled by -username (with -password or $MEMCACHE_PASSWORD), TLS by -tls.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+const passwordEnv = "MEMCACHE_PASSWORD"
+
+var errUsage = errors.New("invalid usage")
+
+type config struct {
+	servers  []string
+	username string
+	password string
+	timeout  time.Duration
+	tls      tlsFlags
+}
+
+type tlsFlags struct {
+	enabled    bool
+	caFile     string
+	certFile   string
+	keyFile    string
+	serverName string
+	skipVerify bool
+}
+
+type commandFunc func(cli *cli, args []string) error
+
+var commands = map[string]commandFunc{
+	"get":     runGet,
+	"set":     runSet,
+	"del":     runDel,
+	"touch":   runTouch,
+	"version": runVersion,
+	"stats":   runStats,
+	"dump":    runDump,
+	"flush":   runFlush,
+	"export":  runExport,
+	"restore": runRestore,
+	"migrate": runMigrate,
+	"analyze": runAnalyze,
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+func parseConfig(args []string, stderr io.Writer) (config, []string, error) {
+	fs := newFlagSet("memcache-cli", stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, "Usage: memcache-cli [flags] <command> [args]")
+		_, _ = fmt.Fprintln(stderr,
+			"Commands: get, set, del, touch, version, stats, dump, flush, export, restore, migrate, analyze")
+		fs.PrintDefaults()
+	}
+
+	var conf config
+	servers := fs.String("servers", "localhost:11211", "comma separated list of memcached addresses")
+	fs.StringVar(&conf.username, "username", "", "username for SASL password authentication")
+	fs.StringVar(&conf.password, "password", "", "password for authentication, defaults to $"+passwordEnv)
+	fs.DurationVar(&conf.timeout, "timeout", 5*time.Second, "connect, read & write timeout")
+
+	fs.BoolVar(&conf.tls.enabled, "tls", false, "connect using TLS")
+	fs.StringVar(&conf.tls.caFile, "tls-ca", "", "PEM file of the CA for verifying the servers")
+	fs.StringVar(&conf.tls.certFile, "tls-cert", "", "PEM file of the client certificate")
+	fs.StringVar(&conf.tls.keyFile, "tls-key", "", "PEM file of the client private key")
+	fs.StringVar(&conf.tls.serverName, "tls-server-name", "", "server name for verifying the certificates")
+	fs.BoolVar(&conf.tls.skipVerify, "tls-skip-verify", false, "do NOT verify the server certificates")
+
+	if err := fs.Parse(args); err != nil {
+		return config{}, nil, errUsage
+	}
+
+	for _, addr := range strings.Split(*servers, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			conf.servers = append(conf.servers, addr)
+		}
+	}
+	if len(conf.servers) == 0 {
+		_, _ = fmt.Fprintln(stderr, "missing memcached servers")
+		return config{}, nil, errUsage
+	}
+
+	if conf.password == "" {
+		conf.password = os.Getenv(passwordEnv)
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return config{}, nil, errUsage
+	}
+	return conf, fs.Args(), nil
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	conf, args, err := parseConfig(args, stderr)
+	if err != nil {
+		return exitUsage
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		_, _ = fmt.Fprintf(stderr, "unknown command: %s\n", args[0])
+		return exitUsage
+	}
+
+	c, err := newCLI(conf, stdin, stdout, stderr)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	err = cmd(c, args[1:])
+	if errors.Is(err, errUsage) {
+		return exitUsage
+	}
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	return exitOK
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/QuangTung97/go-memcache/memcache/memcachetest"
+)
+
+type runResult struct {
+	code   int
+	stdout string
+	stderr string
+}
+
+func runTest(stdin string, args ...string) runResult {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return runResult{
+		code:   code,
+		stdout: stdout.String(),
+		stderr: stderr.String(),
+	}
+}
+
+func newServerTest(t *testing.T, options ...memcachetest.Option) *memcachetest.Server {
+	s, err := memcachetest.NewServer(options...)
+	if err != nil {
+		panic(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestRun_Key_Commands(t *testing.T) {
+	s := newServerTest(t)
+	servers := "-servers=" + s.Addr()
+
+	r := runTest("", servers, "get", "KEY01")
+	assert.Equal(t, runResult{code: exitError, stderr: "key not found\n"}, r)
+
+	r = runTest("", servers, "set", "-ttl", "100", "KEY01", "value 01")
+	assert.Equal(t, runResult{code: exitOK, stdout: "STORED\n"}, r)
+
+	r = runTest("", servers, "get", "KEY01")
+	assert.Equal(t, runResult{code: exitOK, stdout: "value 01\n"}, r)
+
+	r = runTest("from stdin", servers, "set", "KEY01")
+	assert.Equal(t, runResult{code: exitOK, stdout: "STORED\n"}, r)
+
+	r = runTest("", servers, "get", "KEY01")
+	assert.Equal(t, runResult{code: exitOK, stdout: "from stdin\n"}, r)
+
+	r = runTest("", servers, "set", "-cas", "12345", "KEY01", "value 02")
+	assert.Equal(t, runResult{code: exitOK, stdout: "EXISTS\n"}, r)
+
+	r = runTest("", servers, "touch", "KEY01", "300")
+	assert.Equal(t, runResult{code: exitOK, stdout: "TOUCHED\n"}, r)
+
+	r = runTest("", servers, "del", "KEY01")
+	assert.Equal(t, runResult{code: exitOK, stdout: "DELETED\n"}, r)
+
+	r = runTest("", servers, "del", "KEY01")
+	assert.Equal(t, runResult{code: exitOK, stdout: "NOT_FOUND\n"}, r)
+
+	r = runTest("", servers, "touch", "KEY01", "300")
+	assert.Equal(t, runResult{code: exitOK, stdout: "NOT_FOUND\n"}, r)
+}
+
+func TestRun_Multiple_Servers(t *testing.T) {
+	s1 := newServerTest(t, memcachetest.WithVersion("1.6.21"))
+	s2 := newServerTest(t, memcachetest.WithVersion("1.6.37"))
+	servers := "-servers=" + s1.Addr() + "," + s2.Addr()
+
+	r := runTest("", servers, "version")
+	assert.Equal(t, runResult{
+		code:   exitOK,
+		stdout: s1.Addr() + ": 1.6.21\n" + s2.Addr() + ": 1.6.37\n",
+	}, r)
+
+	r = runTest("", servers, "set", "KEY01", "value")
+	assert.Equal(t, exitOK, r.code)
+
+	r = runTest("", servers, "flush", "-delay", "0s")
+	assert.Equal(t, runResult{
+		code:   exitOK,
+		stdout: s1.Addr() + ": OK\n" + s2.Addr() + ": OK\n",
+	}, r)
+
+	r = runTest("", servers, "get", "KEY01")
+	assert.Equal(t, runResult{
+		code:   exitError,
+		stderr: s1.Addr() + ": key not found\n" + s2.Addr() + ": key not found\n",
+	}, r)
+}
+
+func TestRun_Password_Auth(t *testing.T) {
+	s := newServerTest(t, memcachetest.WithPasswordAuth("user01", "password01"))
+
+	t.Run("success", func(t *testing.T) {
+		t.Setenv(passwordEnv, "password01")
+
+		r := runTest("", "-servers="+s.Addr(), "-username=user01", "set", "KEY01", "value")
+		assert.Equal(t, runResult{code: exitOK, stdout: "STORED\n"}, r)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		r := runTest("", "-servers="+s.Addr(), "-username=user01", "-password=invalid", "version")
+		assert.Equal(t, exitError, r.code)
+		assert.Equal(t, "", r.stdout)
+		assert.NotEqual(t, "", r.stderr)
+	})
+}
+
+func TestRun_Usage_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "no command", args: nil},
+		{name: "unknown command", args: []string{"incr", "KEY01"}},
+		{name: "missing key", args: []string{"get"}},
+		{name: "too many args", args: []string{"del", "KEY01", "KEY02"}},
+		{name: "zero ttl", args: []string{"touch", "KEY01", "0"}},
+		{name: "unknown stats", args: []string{"stats", "detail"}},
+		{name: "invalid classes", args: []string{"dump", "-classes", "1,x"}},
+		{name: "empty servers", args: []string{"-servers=", "version"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := runTest("", tc.args...)
+			assert.Equal(t, exitUsage, r.code)
+			assert.Equal(t, "", r.stdout)
+			assert.NotEqual(t, "", r.stderr)
+		})
+	}
+}
+
+func TestParseSlabClasses(t *testing.T) {
+	classes, err := parseSlabClasses("")
+	assert.Equal(t, nil, err)
+	assert.Nil(t, classes)
+
+	classes, err = parseSlabClasses("1, 3,12")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []uint32{1, 3, 12}, classes)
+}
+
+func TestRun_Dump(t *testing.T) {
+	s := newServerTest(t)
+	servers := "-servers=" + s.Addr()
+
+	r := runTest("", servers, "set", "KEY01", "value01")
+	assert.Equal(t, exitOK, r.code)
+
+	r = runTest("", servers, "dump")
+	assert.Equal(t, exitOK, r.code)
+	assert.Contains(t, r.stdout, `{"key":"KEY01","exp":-1,`)
+	assert.Contains(t, r.stdout, `"fetch":false,"cls":1,"size":68}`)
+
+	r = runTest("", servers, "dump", "-classes=2")
+	assert.Equal(t, runResult{code: exitOK}, r)
+}
+
+func TestRun_Export_Restore(t *testing.T) {
+	source := newServerTest(t)
+	dest := newServerTest(t)
+
+	r := runTest("", "-servers="+source.Addr(), "set", "-ttl=3600", "KEY01", "value01")
+	assert.Equal(t, exitOK, r.code)
+	r = runTest("", "-servers="+source.Addr(), "set", "KEY02", "value02")
+	assert.Equal(t, exitOK, r.code)
+
+	file := filepath.Join(t.TempDir(), "snapshot")
+
+	r = runTest("", "-servers="+source.Addr(), "export", file)
+	assert.Equal(t, runResult{code: exitOK, stderr: "dumped: 2, written: 2, missed: 0\n"}, r)
+
+	r = runTest("", "-servers="+dest.Addr(), "restore", "-rate=1000", file)
+	assert.Equal(t, runResult{code: exitOK, stdout: "read: 2, stored: 2, expired: 0, not stored: 0\n"}, r)
+
+	r = runTest("", "-servers="+dest.Addr(), "get", "KEY01")
+	assert.Equal(t, runResult{code: exitOK, stdout: "value01\n"}, r)
+
+	t.Run("stdout and stdin", func(t *testing.T) {
+		r := runTest("", "-servers="+source.Addr(), "export", "-")
+		assert.Equal(t, exitOK, r.code)
+
+		r = runTest(r.stdout, "-servers="+dest.Addr(), "restore", "-")
+		assert.Equal(t, runResult{code: exitOK, stdout: "read: 2, stored: 2, expired: 0, not stored: 0\n"}, r)
+	})
+
+	t.Run("multiple servers", func(t *testing.T) {
+		r := runTest("", "-servers="+source.Addr()+","+dest.Addr(), "export", file)
+		assert.Equal(t, runResult{code: exitUsage, stderr: "export requires exactly one server\n"}, r)
+	})
+}
+
+func TestRun_Migrate(t *testing.T) {
+	source := newServerTest(t)
+	dest := newServerTest(t)
+
+	r := runTest("", "-servers="+source.Addr(), "set", "KEY01", "value01")
+	assert.Equal(t, exitOK, r.code)
+	r = runTest("", "-servers="+source.Addr(), "set", "KEY02", "value02")
+	assert.Equal(t, exitOK, r.code)
+	r = runTest("", "-servers="+dest.Addr(), "set", "KEY02", "newer02")
+	assert.Equal(t, exitOK, r.code)
+
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	r = runTest("", "-servers="+source.Addr(), "migrate", "-to="+dest.Addr(), "-checkpoint="+checkpoint)
+	assert.Equal(t, runResult{
+		code:   exitOK,
+		stdout: "dumped: 2, copied: 1, exists: 1, missed: 0, not stored: 0, deleted: 0\n",
+		stderr: "class 1 completed, dumped: 2, copied: 1, exists: 1, missed: 0, not stored: 0, deleted: 0\n",
+	}, r)
+
+	data, err := os.ReadFile(checkpoint)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "{\"done\":[1]}\n", string(data))
+
+	r = runTest("", "-servers="+dest.Addr(), "get", "KEY01")
+	assert.Equal(t, runResult{code: exitOK, stdout: "value01\n"}, r)
+	r = runTest("", "-servers="+dest.Addr(), "get", "KEY02")
+	assert.Equal(t, runResult{code: exitOK, stdout: "newer02\n"}, r)
+
+	t.Run("resume from checkpoint", func(t *testing.T) {
+		r := runTest("", "-servers="+source.Addr(), "migrate", "-to="+dest.Addr(), "-checkpoint="+checkpoint)
+		assert.Equal(t, runResult{
+			code:   exitOK,
+			stdout: "dumped: 0, copied: 0, exists: 0, missed: 0, not stored: 0, deleted: 0\n",
+		}, r)
+	})
+
+	t.Run("delete source", func(t *testing.T) {
+		r := runTest("", "-servers="+source.Addr(), "migrate", "-to="+dest.Addr(), "-delete-source")
+		assert.Equal(t, exitOK, r.code)
+		assert.Equal(t, "dumped: 2, copied: 0, exists: 2, missed: 0, not stored: 0, deleted: 2\n", r.stdout)
+
+		r = runTest("", "-servers="+source.Addr(), "get", "KEY01")
+		assert.Equal(t, runResult{code: exitError, stderr: "key not found\n"}, r)
+	})
+
+	t.Run("missing destination", func(t *testing.T) {
+		r := runTest("", "-servers="+source.Addr(), "migrate")
+		assert.Equal(t, runResult{code: exitUsage, stderr: "migrate requires the -to flag\n"}, r)
+	})
+}
+
+func TestRun_Analyze(t *testing.T) {
+	s1 := newServerTest(t)
+	s2 := newServerTest(t)
+	servers := "-servers=" + s1.Addr() + "," + s2.Addr()
+
+	r := runTest("", "-servers="+s1.Addr(), "set", "user:1", "value01")
+	assert.Equal(t, exitOK, r.code)
+	r = runTest("", "-servers="+s2.Addr(), "set", "user:2", "value02")
+	assert.Equal(t, exitOK, r.code)
+	r = runTest("", "-servers="+s2.Addr(), "set", "-ttl=30", "product:1", "value03")
+	assert.Equal(t, exitOK, r.code)
+	r = runTest("", "-servers="+s2.Addr(), "get", "product:1")
+	assert.Equal(t, exitOK, r.code)
+
+	r = runTest("", servers, "analyze")
+	assert.Equal(t, runResult{
+		code: exitOK,
+		stdout: "" +
+			"GROUP     ITEMS  BYTES  NEVER FETCHED  NO EXPIRY\n" +
+			"user:     2      138    100.0%         2\n" +
+			"product:  1      72     0.0%           0\n" +
+			"TOTAL     3      210    66.7%          2\n" +
+			"\n" +
+			"TTL       <=1m  <=10m  <=1h  <=6h  <=1d  <=7d  >7d\n" +
+			"user:     0     0      0     0     0     0     0\n" +
+			"product:  1     0      0     0     0     0     0\n" +
+			"TOTAL     1     0      0     0     0     0     0\n" +
+			"\n" +
+			"IDLE      <=1m  <=10m  <=1h  <=6h  <=1d  <=7d  >7d\n" +
+			"user:     2     0      0     0     0     0     0\n" +
+			"product:  1     0      0     0     0     0     0\n" +
+			"TOTAL     3     0      0     0     0     0     0\n",
+	}, r)
+
+	r = runTest("", servers, "analyze", "-pattern", `^user:\d+$`, "-top=1", "-json")
+	assert.Equal(t, exitOK, r.code)
+	assert.Contains(t, r.stdout, `"name": "^user:\\d+$",`)
+	assert.NotContains(t, r.stdout, `"name": "(other)"`)
+
+	r = runTest("", servers, "analyze", "-pattern", `user:(`)
+	assert.Equal(t, exitUsage, r.code)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/QuangTung97/go-memcache/memcache/migrate"
+	"github.com/QuangTung97/go-memcache/memcache/stats"
+)
+
+// readCheckpoint returns the empty checkpoint if the file does NOT exist
+func readCheckpoint(file string) (migrate.Checkpoint, error) {
+	var checkpoint migrate.Checkpoint
+	if file == "" {
+		return checkpoint, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return checkpoint, err
+	}
+
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return checkpoint, fmt.Errorf("invalid checkpoint file %s: %w", file, err)
+	}
+	return checkpoint, nil
+}
+
+// writeCheckpoint replaces the checkpoint file atomically, such that it is never partially written
+func writeCheckpoint(file string, checkpoint migrate.Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+func formatProgress(p migrate.Progress) string {
+	return fmt.Sprintf("dumped: %d, copied: %d, exists: %d, missed: %d, not stored: %d, deleted: %d",
+		p.Dumped, p.Copied, p.Exists, p.Missed, p.NotStored, p.Deleted)
+}
+
+func runMigrate(c *cli, args []string) error {
+	flags := newFlagSet("migrate", c.stderr)
+	to := flags.String("to", "", "address of the destination server")
+	batchSize := flags.Int("batch", 100, "number of keys copied per pipeline")
+	checkpointFile := flags.String("checkpoint", "", "file to resume from and to save the completed slab classes to")
+	deleteSource := flags.Bool("delete-source", false, "delete the copied keys from the source server")
+	if err := parseArgs(flags, args, "-to <addr>", 0, 0); err != nil {
+		return err
+	}
+	if *to == "" {
+		_, _ = fmt.Fprintln(c.stderr, "migrate requires the -to flag")
+		return errUsage
+	}
+	addr, err := c.singleServer("migrate")
+	if err != nil {
+		return err
+	}
+
+	checkpoint, err := readCheckpoint(*checkpointFile)
+	if err != nil {
+		return err
+	}
+
+	source, err := c.newMemcacheClient(addr)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = source.Close() }()
+
+	dest, err := c.newMemcacheClient(*to)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = dest.Close() }()
+
+	dumper := stats.New(addr, c.statsOptions()...)
+	defer func() { _ = dumper.Close() }()
+
+	var saveErr error
+	done := len(checkpoint.Done)
+
+	options := []migrate.Option{
+		migrate.WithBatchSize(*batchSize),
+		migrate.WithCheckpoint(checkpoint),
+		migrate.WithProgressHandler(func(p migrate.Progress) {
+			if len(p.Checkpoint.Done) == done {
+				return
+			}
+			done = len(p.Checkpoint.Done)
+
+			_, _ = fmt.Fprintf(c.stderr, "class %d completed, %s\n", p.Class, formatProgress(p))
+			if *checkpointFile != "" && saveErr == nil {
+				saveErr = writeCheckpoint(*checkpointFile, p.Checkpoint)
+			}
+		}),
+	}
+	if *deleteSource {
+		options = append(options, migrate.WithDeleteSource())
+	}
+
+	m := migrate.NewMigrator(dumper, source, dest, options...)
+	result, err := m.Run(context.Background())
+	if err != nil {
+		return err
+	}
+	if saveErr != nil {
+		return saveErr
+	}
+
+	_, _ = fmt.Fprintln(c.stdout, formatProgress(result))
+	return nil
+}
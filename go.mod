@@ -1,6 +1,6 @@
 module github.com/QuangTung97/go-memcache
 
-go 1.19
+go 1.21
 
 require (
 	github.com/matryer/moq v0.3.0
@@ -15,12 +15,35 @@ type cmdBuilder struct {
 type MGetOptions struct {
 	N   uint32 // option N of mg command
 	CAS bool
+
+	// TTL is option T of mg command, updates the TTL of the item when found (touch), only when > 0
+	TTL uint32
 }
 
+// MSetMode is option M of ms command, the zero value is the default mode (set)
+type MSetMode byte
+
+const (
+	// MSetModeAdd only stores if the key does NOT exist, otherwise replies NS
+	MSetModeAdd MSetMode = 'E'
+	// MSetModeReplace only stores if the key exists, otherwise replies NS
+	MSetModeReplace MSetMode = 'R'
+	// MSetModeAppend appends the data to the existing value
+	MSetModeAppend MSetMode = 'A'
+	// MSetModePrepend prepends the data to the existing value
+	MSetModePrepend MSetMode = 'P'
+)
+
+// MaxRelativeTTL is the max TTL in seconds memcached considers relative, larger values are unix timestamps
+const MaxRelativeTTL = 30 * 24 * 3600
+
 // MSetOptions ...
 type MSetOptions struct {
-	CAS uint64
-	TTL uint32
+	CAS  uint64
+	TTL  uint32
+	Mode MSetMode
+
+	ClientFlags uint32 // option F of ms command, opaque flags stored with the item
 }
 
 // MDelOptions ...
@@ -78,6 +101,15 @@ func (b *cmdBuilder) internalIncreaseCount() {
 }
 
 func (b *cmdBuilder) addMGet(key string, opts MGetOptions) {
+	b.addMGetWithReturnFlags(key, opts, " v\r\n")
+}
+
+// addMGetMeta is similar to addMGet, but also returns the remaining TTL & the client flags of the item
+func (b *cmdBuilder) addMGetMeta(key string, opts MGetOptions) {
+	b.addMGetWithReturnFlags(key, opts, " t f v\r\n")
+}
+
+func (b *cmdBuilder) addMGetWithReturnFlags(key string, opts MGetOptions, returnFlags string) {
 	b.internalIncreaseCount()
 	b.mgetCount++
 
@@ -93,7 +125,23 @@ func (b *cmdBuilder) addMGet(key string, opts MGetOptions) {
 		b.cmd.requestData = appendNumber(b.cmd.requestData, uint64(opts.N))
 	}
 
-	b.cmd.requestData = append(b.cmd.requestData, " v\r\n"...)
+	if opts.TTL > 0 {
+		b.cmd.requestData = append(b.cmd.requestData, " T"...)
+		b.cmd.requestData = appendNumber(b.cmd.requestData, uint64(opts.TTL))
+	}
+
+	b.cmd.requestData = append(b.cmd.requestData, returnFlags...)
+}
+
+// addMTouch uses the mg command without the flag v, the value is NOT returned
+func (b *cmdBuilder) addMTouch(key string, ttl uint32) {
+	b.internalIncreaseCount()
+
+	b.cmd.requestData = append(b.cmd.requestData, "mg "...)
+	b.cmd.requestData = append(b.cmd.requestData, key...)
+	b.cmd.requestData = append(b.cmd.requestData, " T"...)
+	b.cmd.requestData = appendNumber(b.cmd.requestData, uint64(ttl))
+	b.cmd.requestData = append(b.cmd.requestData, "\r\n"...)
 }
 
 func (b *cmdBuilder) addMSet(key string, data []byte, opts MSetOptions) {
@@ -114,6 +162,16 @@ func (b *cmdBuilder) addMSet(key string, data []byte, opts MSetOptions) {
 		b.cmd.requestData = appendNumber(b.cmd.requestData, uint64(opts.TTL))
 	}
 
+	if opts.Mode != 0 {
+		b.cmd.requestData = append(b.cmd.requestData, " M"...)
+		b.cmd.requestData = append(b.cmd.requestData, byte(opts.Mode))
+	}
+
+	if opts.ClientFlags > 0 {
+		b.cmd.requestData = append(b.cmd.requestData, " F"...)
+		b.cmd.requestData = appendNumber(b.cmd.requestData, uint64(opts.ClientFlags))
+	}
+
 	b.cmd.requestData = append(b.cmd.requestData, "\r\n"...)
 
 	dataLen := uint64(len(data))
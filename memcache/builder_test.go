@@ -83,6 +83,35 @@ func TestBuilder_AddMGet_With_N_And_CAS(t *testing.T) {
 	assert.Equal(t, 4, cap(cmd.responseBinaries))
 }
 
+func TestBuilder_AddMGet_With_TTL(t *testing.T) {
+	b := newCmdBuilder()
+	b.addMGet("some:key", MGetOptions{N: 13, TTL: 300})
+	cmd := b.finish()
+
+	assert.Equal(t, 1, cmd.cmdCount)
+	assert.Equal(t, "mg some:key N13 T300 v\r\n", string(cmd.requestData))
+}
+
+func TestBuilder_AddMGet_Meta(t *testing.T) {
+	b := newCmdBuilder()
+	b.addMGetMeta("some:key", MGetOptions{CAS: true})
+	b.addMGet("key02", MGetOptions{})
+	cmd := b.finish()
+
+	assert.Equal(t, 2, cmd.cmdCount)
+	assert.Equal(t, "mg some:key c t f v\r\nmg key02 v\r\n", string(cmd.requestData))
+}
+
+func TestBuilder_AddMTouch(t *testing.T) {
+	b := newCmdBuilder()
+	b.addMTouch("some:key", 300)
+	b.addMGet("key02", MGetOptions{})
+	cmd := b.finish()
+
+	assert.Equal(t, 2, cmd.cmdCount)
+	assert.Equal(t, "mg some:key T300\r\nmg key02 v\r\n", string(cmd.requestData))
+}
+
 func traverseRequestBinaries(cmd *commandListData) []requestBinaryEntry {
 	result := make([]requestBinaryEntry, 0)
 	for current := cmd.requestBinaries; current != nil; current = current.next {
@@ -159,6 +188,30 @@ func TestBuilder_AddMSet_With_TTL(t *testing.T) {
 	}, traverseRequestBinaries(cmd))
 }
 
+func TestBuilder_AddMSet_With_Mode(t *testing.T) {
+	b := newCmdBuilder()
+	b.addMSet("some:key", []byte("SOME-VALUE"), MSetOptions{
+		TTL:  23,
+		Mode: MSetModeAdd,
+	})
+	cmd := b.finish()
+
+	assert.Equal(t, 1, cmd.cmdCount)
+	assert.Equal(t, "ms some:key 10 T23 ME\r\n", string(cmd.requestData))
+}
+
+func TestBuilder_AddMSet_With_Client_Flags(t *testing.T) {
+	b := newCmdBuilder()
+	b.addMSet("some:key", []byte("SOME-VALUE"), MSetOptions{
+		TTL:         23,
+		ClientFlags: 1234,
+	})
+	cmd := b.finish()
+
+	assert.Equal(t, 1, cmd.cmdCount)
+	assert.Equal(t, "ms some:key 10 T23 F1234\r\n", string(cmd.requestData))
+}
+
 func TestBuilder_AddMSet_Multi(t *testing.T) {
 	b := newCmdBuilder()
 	b.addMSet("some:key", []byte("SOME-VALUE"), MSetOptions{
@@ -13,6 +13,7 @@ const (
 	commandTypeMDel
 	commandTypeFlushAll
 	commandTypeVersion
+	commandTypeMGetMeta
 )
 
 // =====================
@@ -64,6 +65,9 @@ type commandListData struct {
 
 	conn *senderConnection // TCP connection to read response data from
 
+	traced   bool   // sampled for protocol tracing
+	recordID uint64 // batch id of traffic recording, zero means not recorded
+
 	lastErr error
 	ch      chan error
 }
@@ -2,6 +2,7 @@ package memcache
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/QuangTung97/go-memcache/memcache/netconn"
@@ -17,6 +18,20 @@ type clientConn struct {
 
 	maxCommandsPerBatch int
 
+	index         int
+	stateCallback ConnStateCallback
+	stateNotifyCh chan<- struct{}
+
+	statusMut      sync.Mutex
+	state          ConnState
+	lastErr        error
+	lastProbeTime  time.Time
+	reconnectCount uint64
+
+	available atomic.Bool // the state is connected or connecting, for selecting without locking
+
+	retired atomic.Bool // removed from the pool by Client.SetNumConns
+
 	// following fields are used for shutdown process only
 	mut       sync.Mutex
 	closed    bool // to avoid closing closeChan more than once
@@ -37,41 +52,63 @@ func newConn(
 ) *clientConn {
 	opts := computeOptions(options...)
 
-	nc, err := netconn.DialNewConn(addr, opts.connOptions...)
-	if err != nil {
-		opts.dialErrorLogger(err)
-		nc = netconn.ErrorNetConn(err)
-	}
-
 	c := &clientConn{
-		core:      newCoreConnection(nc, opts),
 		closeChan: make(chan struct{}),
 
 		cmdPool: cmdPool,
 
 		maxCommandsPerBatch: opts.maxCommandsPerBatch,
+
+		index:         opts.connIndex,
+		stateCallback: opts.connStateCallback,
+		stateNotifyCh: opts.stateNotifyCh,
+
+		state: ConnStateConnecting,
+	}
+	c.available.Store(true)
+
+	var nc netconn.NetConn
+	if opts.isLazyDial() {
+		nc = newLazyNetConn(func() (netconn.NetConn, error) {
+			return c.dialFirstConn(addr, opts)
+		})
+	} else {
+		var err error
+		nc, err = c.dialFirstConn(addr, opts)
+		if err != nil {
+			nc = netconn.ErrorNetConn(err)
+		}
 	}
+	c.core = newCoreConnection(nc, opts)
 
 	// start the background goroutine for reconnecting when the underling connection is broken
 	c.wg.Add(1)
 	go func() {
 		defer c.wg.Done()
 
+		attempt := 0
 		for {
 			closed := c.core.waitForError()
 			if closed {
 				return
 			}
+			if c.getState() == ConnStateConnected {
+				c.setReconnecting(c.core.lastError())
+			}
 
-			nc, err = netconn.DialNewConn(addr, opts.connOptions...)
+			nc, err := netconn.DialNewConn(addr, opts.connOptions...)
 			if err != nil {
 				opts.dialErrorLogger(err)
+				c.setReconnecting(err)
 
-				sleepWithCloseChan(opts.retryDuration, c.closeChan)
+				sleepWithCloseChan(opts.backoffPolicy.Backoff(attempt), c.closeChan)
+				attempt++
 				continue
 			}
+			attempt = 0
 
 			c.core.resetNetConn(nc)
+			c.setReconnected()
 			continue
 		}
 	}()
@@ -79,10 +116,35 @@ func newConn(
 	return c
 }
 
+func (c *clientConn) dialFirstConn(addr string, opts *memcacheOptions) (netconn.NetConn, error) {
+	nc, err := netconn.DialNewConn(addr, opts.connOptions...)
+	if err != nil {
+		opts.dialErrorLogger(err)
+		c.setReconnecting(err)
+		return netconn.NetConn{}, err
+	}
+	c.setConnected()
+	return nc, nil
+}
+
+// probe sends a version command and records the result
+func (c *clientConn) probe() {
+	pipe := newPipeline(c, nil)
+	_, err := pipe.Version()()
+	pipe.Finish()
+
+	c.recordProbe(err, time.Now())
+}
+
 func (c *clientConn) pushCommand(cmd *commandListData) {
 	c.core.publish(cmd)
 }
 
+// tryPushCommand does NOT complete the command when the connection is closed
+func (c *clientConn) tryPushCommand(cmd *commandListData) (closed bool) {
+	return c.core.sender.sendBuf.push(cmd)
+}
+
 func (c *clientConn) shutdown() {
 	c.mut.Lock()
 	if !c.closed {
@@ -91,6 +153,7 @@ func (c *clientConn) shutdown() {
 	}
 	c.mut.Unlock()
 
+	c.setClosed()
 	c.core.sender.closeSendJob()
 }
 
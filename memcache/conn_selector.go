@@ -0,0 +1,109 @@
+package memcache
+
+import (
+	"math/rand"
+)
+
+// ConnSelectionStrategy specifies how a Pipeline chooses its TCP connection
+type ConnSelectionStrategy int
+
+const (
+	// ConnSelectionRoundRobin picks connections in turn regardless of their states, this is the default strategy
+	ConnSelectionRoundRobin ConnSelectionStrategy = iota
+
+	// ConnSelectionLeastOutstanding picks the connection with the fewest commands not yet received responses,
+	// including the commands still waiting to be written
+	ConnSelectionLeastOutstanding
+
+	// ConnSelectionPowerOfTwoChoices picks two random connections and uses the one with fewer outstanding commands
+	ConnSelectionPowerOfTwoChoices
+)
+
+// isAvailable returns false when the connection is in error state (reconnecting or closed).
+// With the least outstanding & power of two choices strategies, these connections are skipped,
+// unless all connections are in error state.
+func (c *clientConn) isAvailable() bool {
+	return c.available.Load()
+}
+
+func selectRoundRobin(conns []*clientConn, next uint64) *clientConn {
+	return conns[next%uint64(len(conns))]
+}
+
+// selectNextAvailable is the round-robin selection skipping the connections NOT available
+func selectNextAvailable(conns []*clientConn, next uint64) *clientConn {
+	n := uint64(len(conns))
+	for i := uint64(0); i < n; i++ {
+		conn := conns[(next+i)%n]
+		if conn.isAvailable() {
+			return conn
+		}
+	}
+	return conns[next%n]
+}
+
+func selectLeastOutstanding(conns []*clientConn, next uint64) *clientConn {
+	n := uint64(len(conns))
+
+	var result *clientConn
+	var minCount uint64
+
+	// start from the round-robin position, for spreading out when the counts are equal
+	for i := uint64(0); i < n; i++ {
+		conn := conns[(next+i)%n]
+		if !conn.isAvailable() {
+			continue
+		}
+		count := conn.core.pendingCount()
+		if result == nil || count < minCount {
+			result = conn
+			minCount = count
+		}
+	}
+
+	if result == nil {
+		return conns[next%n]
+	}
+	return result
+}
+
+func selectPowerOfTwoChoices(conns []*clientConn, next uint64, randFunc func(n int) int) *clientConn {
+	if len(conns) == 1 {
+		return conns[0]
+	}
+
+	first := randFunc(len(conns))
+	second := randFunc(len(conns) - 1)
+	if second >= first {
+		second++
+	}
+
+	a := conns[first]
+	b := conns[second]
+
+	if !a.isAvailable() && !b.isAvailable() {
+		return selectNextAvailable(conns, next)
+	}
+	if !a.isAvailable() {
+		return b
+	}
+	if !b.isAvailable() {
+		return a
+	}
+
+	if b.core.pendingCount() < a.core.pendingCount() {
+		return b
+	}
+	return a
+}
+
+func selectConn(strategy ConnSelectionStrategy, conns []*clientConn, next uint64) *clientConn {
+	switch strategy {
+	case ConnSelectionLeastOutstanding:
+		return selectLeastOutstanding(conns, next)
+	case ConnSelectionPowerOfTwoChoices:
+		return selectPowerOfTwoChoices(conns, next, rand.Intn)
+	default:
+		return selectRoundRobin(conns, next)
+	}
+}
@@ -0,0 +1,209 @@
+package memcache
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSelectorTestConn(available bool, inFlight uint64) *clientConn {
+	return newSelectorTestConnWithQueued(available, inFlight, 0)
+}
+
+// newSelectorTestConnWithQueued creates a connection with **queued** commands pushed but not yet written
+func newSelectorTestConnWithQueued(available bool, inFlight uint64, queued uint64) *clientConn {
+	c := &clientConn{
+		core: &coreConnection{
+			sender: &sender{},
+		},
+	}
+	c.available.Store(available)
+	c.core.sender.sendBuf.pushedCount.Add(inFlight + queued)
+	c.core.sender.selector.writeLimiter.addWriteCount(inFlight)
+	return c
+}
+
+func TestSelectRoundRobin(t *testing.T) {
+	t.Run("all available", func(t *testing.T) {
+		conns := []*clientConn{
+			newSelectorTestConn(true, 0),
+			newSelectorTestConn(true, 0),
+			newSelectorTestConn(true, 0),
+		}
+
+		assert.Same(t, conns[1], selectRoundRobin(conns, 1))
+		assert.Same(t, conns[2], selectRoundRobin(conns, 2))
+		assert.Same(t, conns[0], selectRoundRobin(conns, 3))
+	})
+
+	t.Run("not skip not available", func(t *testing.T) {
+		conns := []*clientConn{
+			newSelectorTestConn(true, 0),
+			newSelectorTestConn(false, 0),
+			newSelectorTestConn(true, 0),
+		}
+
+		assert.Same(t, conns[1], selectRoundRobin(conns, 1))
+		assert.Same(t, conns[2], selectRoundRobin(conns, 2))
+	})
+}
+
+func TestSelectNextAvailable(t *testing.T) {
+	t.Run("skip not available", func(t *testing.T) {
+		conns := []*clientConn{
+			newSelectorTestConn(true, 0),
+			newSelectorTestConn(false, 0),
+			newSelectorTestConn(true, 0),
+		}
+
+		assert.Same(t, conns[2], selectNextAvailable(conns, 1))
+		assert.Same(t, conns[2], selectNextAvailable(conns, 2))
+		assert.Same(t, conns[0], selectNextAvailable(conns, 3))
+	})
+
+	t.Run("all not available", func(t *testing.T) {
+		conns := []*clientConn{
+			newSelectorTestConn(false, 0),
+			newSelectorTestConn(false, 0),
+		}
+
+		assert.Same(t, conns[1], selectNextAvailable(conns, 1))
+		assert.Same(t, conns[0], selectNextAvailable(conns, 2))
+	})
+}
+
+func TestSelectLeastOutstanding(t *testing.T) {
+	t.Run("min in flight", func(t *testing.T) {
+		conns := []*clientConn{
+			newSelectorTestConn(true, 5),
+			newSelectorTestConn(true, 2),
+			newSelectorTestConn(true, 7),
+		}
+
+		assert.Same(t, conns[1], selectLeastOutstanding(conns, 0))
+		assert.Same(t, conns[1], selectLeastOutstanding(conns, 2))
+	})
+
+	t.Run("include commands not yet written", func(t *testing.T) {
+		conns := []*clientConn{
+			newSelectorTestConnWithQueued(true, 0, 10),
+			newSelectorTestConnWithQueued(true, 3, 0),
+			newSelectorTestConnWithQueued(true, 1, 4),
+		}
+
+		assert.Same(t, conns[1], selectLeastOutstanding(conns, 0))
+	})
+
+	t.Run("equal counts start from round robin position", func(t *testing.T) {
+		conns := []*clientConn{
+			newSelectorTestConn(true, 3),
+			newSelectorTestConn(true, 3),
+			newSelectorTestConn(true, 3),
+		}
+
+		assert.Same(t, conns[0], selectLeastOutstanding(conns, 0))
+		assert.Same(t, conns[1], selectLeastOutstanding(conns, 1))
+		assert.Same(t, conns[2], selectLeastOutstanding(conns, 2))
+	})
+
+	t.Run("skip not available", func(t *testing.T) {
+		conns := []*clientConn{
+			newSelectorTestConn(true, 5),
+			newSelectorTestConn(false, 0),
+			newSelectorTestConn(true, 4),
+		}
+
+		assert.Same(t, conns[2], selectLeastOutstanding(conns, 0))
+	})
+
+	t.Run("all not available", func(t *testing.T) {
+		conns := []*clientConn{
+			newSelectorTestConn(false, 0),
+			newSelectorTestConn(false, 0),
+		}
+
+		assert.Same(t, conns[1], selectLeastOutstanding(conns, 1))
+	})
+}
+
+func TestSelectPowerOfTwoChoices(t *testing.T) {
+	newRandFunc := func(values ...int) func(n int) int {
+		return func(n int) int {
+			v := values[0]
+			values = values[1:]
+			return v
+		}
+	}
+
+	conns := []*clientConn{
+		newSelectorTestConn(true, 5),
+		newSelectorTestConn(true, 2),
+		newSelectorTestConn(false, 0),
+		newSelectorTestConn(false, 0),
+	}
+
+	t.Run("fewer in flight", func(t *testing.T) {
+		assert.Same(t, conns[1], selectPowerOfTwoChoices(conns, 0, newRandFunc(0, 0)))
+		assert.Same(t, conns[1], selectPowerOfTwoChoices(conns, 0, newRandFunc(1, 0)))
+	})
+
+	t.Run("skip not available", func(t *testing.T) {
+		assert.Same(t, conns[0], selectPowerOfTwoChoices(conns, 0, newRandFunc(0, 1)))
+		assert.Same(t, conns[0], selectPowerOfTwoChoices(conns, 0, newRandFunc(2, 0)))
+	})
+
+	t.Run("both not available", func(t *testing.T) {
+		assert.Same(t, conns[0], selectPowerOfTwoChoices(conns, 2, newRandFunc(2, 2)))
+	})
+
+	t.Run("single conn", func(t *testing.T) {
+		assert.Same(t, conns[2], selectPowerOfTwoChoices(conns[2:3], 0, nil))
+	})
+
+	t.Run("include commands not yet written", func(t *testing.T) {
+		queuedConns := []*clientConn{
+			newSelectorTestConnWithQueued(true, 0, 10),
+			newSelectorTestConnWithQueued(true, 3, 0),
+		}
+		assert.Same(t, queuedConns[1], selectPowerOfTwoChoices(queuedConns, 0, newRandFunc(0, 0)))
+	})
+}
+
+func TestClient_Selection_Strategy_Skip_Broken_Connection(t *testing.T) {
+	strategies := []ConnSelectionStrategy{
+		ConnSelectionLeastOutstanding,
+		ConnSelectionPowerOfTwoChoices,
+	}
+
+	for _, strategy := range strategies {
+		counter := 0
+		dialFunc := func(network, address string, timeout time.Duration) (net.Conn, error) {
+			counter++
+			if counter == 1 {
+				return nil, errors.New("cannot connect to memcached")
+			}
+			return net.DialTimeout(network, address, timeout)
+		}
+
+		c, err := New("localhost:11211", 3,
+			WithDialFunc(dialFunc),
+			WithDialErrorLogger(func(err error) {}),
+			WithRetryDuration(time.Minute),
+			WithConnSelectionStrategy(strategy),
+		)
+		assert.Equal(t, nil, err)
+
+		for i := 0; i < 20; i++ {
+			p := c.Pipeline()
+			_, err := p.Version()()
+			assert.Equal(t, nil, err)
+			p.Finish()
+		}
+
+		err = c.Close()
+		assert.Equal(t, nil, err)
+	}
+}
@@ -1,8 +1,12 @@
 package memcache
 
 import (
+	"errors"
+	"net"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -88,3 +92,75 @@ func TestConn_Shutdown(t *testing.T) {
 	c.shutdown()
 	c.waitCloseCompleted()
 }
+
+type backoffRecorder struct {
+	mut      sync.Mutex
+	attempts []int
+}
+
+func (r *backoffRecorder) Backoff(attempt int) time.Duration {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	r.attempts = append(r.attempts, attempt)
+	return time.Millisecond
+}
+
+func (r *backoffRecorder) getAttempts() []int {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return append([]int(nil), r.attempts...)
+}
+
+func TestConn_Reconnect_With_Backoff_Policy(t *testing.T) {
+	var mut sync.Mutex
+	dialCount := 0
+	var connection net.Conn
+
+	dialFunc := func(network, address string, timeout time.Duration) (net.Conn, error) {
+		mut.Lock()
+		defer mut.Unlock()
+
+		dialCount++
+		if dialCount == 1 || dialCount == 5 {
+			conn, err := net.Dial(network, address)
+			if err != nil {
+				panic(err)
+			}
+			connection = conn
+			return conn, nil
+		}
+		return nil, errors.New("cannot connect to memcached")
+	}
+
+	recorder := &backoffRecorder{}
+
+	c := newConn("localhost:11211", newPipelineCommandListPool(),
+		WithDialFunc(dialFunc),
+		WithDialErrorLogger(func(err error) {}),
+		WithBackoffPolicy(recorder),
+	)
+	defer func() {
+		c.shutdown()
+		c.waitCloseCompleted()
+	}()
+
+	connFlushAll(c)
+
+	mut.Lock()
+	_ = connection.Close()
+	mut.Unlock()
+
+	cmd1 := newCommandFromString("mg key01 v\r\n")
+	c.pushCommand(cmd1)
+	cmd1.waitCompleted()
+
+	time.Sleep(30 * time.Millisecond)
+
+	// 3 failed dials before connected again
+	assert.Equal(t, []int{0, 1, 2}, recorder.getAttempts())
+
+	cmd2 := newCommandFromString("mg key01 v\r\n")
+	c.pushCommand(cmd2)
+	cmd2.waitCompleted()
+	assert.Equal(t, "EN\r\n", string(cmd2.responseData))
+}
@@ -20,7 +20,10 @@ type coreConnection struct {
 }
 
 func newCoreConnection(nc netconn.NetConn, options *memcacheOptions) *coreConnection {
-	cmdSender := newSender(nc, 7, options.writeLimit)
+	cmdSender := newSenderWithHooks(nc, 7, options.writeLimit, senderHooks{
+		tracer:   newProtocolTracer(options.traceConf, options.connIndex),
+		recorder: newConnTrafficRecorder(options.trafficRecorder, options.connIndex),
+	})
 
 	c := &coreConnection{
 		responseReader: newResponseReader(),
@@ -52,6 +55,18 @@ func (c *coreConnection) waitForError() (closed bool) {
 	return c.sender.waitForError()
 }
 
+func (c *coreConnection) lastError() error {
+	return c.sender.lastError()
+}
+
+func (c *coreConnection) inFlightCount() uint64 {
+	return c.sender.selector.writeLimiter.inFlightCount()
+}
+
+func (c *coreConnection) pendingCount() uint64 {
+	return c.sender.selector.writeLimiter.pendingCount(&c.sender.sendBuf)
+}
+
 func (c *coreConnection) recvCommands() {
 	defer c.wg.Done()
 
@@ -66,7 +81,15 @@ func (c *coreConnection) recvCommands() {
 
 			c.responseReader.reset()
 		}
-		c.cmdList.current().setCompleted(err)
+
+		current := c.cmdList.current()
+		if current.traced {
+			c.sender.hooks.tracer.traceResponse(current, err)
+		}
+		if current.recordID > 0 {
+			c.sender.hooks.recorder.recordResponse(current, err)
+		}
+		current.setCompleted(err)
 		c.cmdList.next()
 	}
 }
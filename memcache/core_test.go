@@ -163,7 +163,7 @@ func TestCoreConnection_Reader_Returns_Incorrect_Data__Response_Reader_Returns_E
 	c.waitReceiverShutdown()
 
 	limiter := &c.sender.selector.writeLimiter
-	assert.Equal(t, uint64(1), limiter.cmdWriteCount)
+	assert.Equal(t, uint64(1), limiter.cmdWriteCount.Load())
 	assert.Equal(t, uint64(1), limiter.cmdReadCount.Load())
 }
 
@@ -15,6 +15,27 @@ var _ error = ErrBrokenPipe{}
 // ErrConnClosed ...
 var ErrConnClosed = errors.New("memcache: connection closed")
 
+// ErrNotReady is returned by New with WithWaitForReady, when not enough connections are established in time
+type ErrNotReady struct {
+	NumReady int
+	MinConns int
+
+	LastDialError error // the last error of a not ready connection, can be nil
+	CtxErr        error
+}
+
+func (e *ErrNotReady) Error() string {
+	return fmt.Sprintf(
+		"memcache: not ready, %d of %d connections established: %v, last error: %v",
+		e.NumReady, e.MinConns, e.CtxErr, e.LastDialError,
+	)
+}
+
+// Unwrap returns the error of the context
+func (e *ErrNotReady) Unwrap() error {
+	return e.CtxErr
+}
+
 func (e ErrBrokenPipe) Error() string {
 	return fmt.Sprintf("broken pipe: %s", e.reason)
 }
@@ -36,6 +57,12 @@ func NewServerError(msg string) error {
 // ObjectTooBigErrorMsg ...
 const ObjectTooBigErrorMsg = "object too large for cache"
 
+// IsObjectTooBig checks whether the error is returned by the server for an item larger than its max item size
+func IsObjectTooBig(err error) bool {
+	var serverErr ErrServerError
+	return errors.As(err, &serverErr) && serverErr.Message == ObjectTooBigErrorMsg
+}
+
 // IsServerError ...
 func IsServerError(err error) bool {
 	_, ok := err.(ErrServerError)
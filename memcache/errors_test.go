@@ -2,6 +2,7 @@ package memcache
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -32,3 +33,11 @@ func TestIsErrorMessage(t *testing.T) {
 	b = IsServerError(NewServerError("some error"))
 	assert.Equal(t, true, b)
 }
+
+func TestIsObjectTooBig(t *testing.T) {
+	assert.Equal(t, false, IsObjectTooBig(nil))
+	assert.Equal(t, false, IsObjectTooBig(NewServerError("some error")))
+	assert.Equal(t, false, IsObjectTooBig(NewClientError(ObjectTooBigErrorMsg)))
+	assert.Equal(t, true, IsObjectTooBig(NewServerError(ObjectTooBigErrorMsg)))
+	assert.Equal(t, true, IsObjectTooBig(fmt.Errorf("wrapped: %w", NewServerError(ObjectTooBigErrorMsg))))
+}
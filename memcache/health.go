@@ -7,7 +7,7 @@ import (
 )
 
 type healthCheckService struct {
-	conns []*clientConn
+	getConns func() []*clientConn
 
 	getNextFunc func() uint64
 	addNextFunc func(delta uint64) uint64
@@ -23,13 +23,13 @@ type healthCheckService struct {
 }
 
 func newHealthCheckService(
-	conns []*clientConn,
+	getConns func() []*clientConn,
 	getNextFunc func() uint64,
 	addNextFunc func(delta uint64) uint64,
 	sleepDuration time.Duration,
 ) *healthCheckService {
 	return &healthCheckService{
-		conns: conns,
+		getConns: getConns,
 
 		getNextFunc: getNextFunc,
 		addNextFunc: addNextFunc,
@@ -43,7 +43,8 @@ func newHealthCheckService(
 }
 
 func (s *healthCheckService) runSingleLoop() {
-	connLen := uint64(len(s.conns))
+	conns := s.getConns()
+	connLen := uint64(len(conns))
 	nextBound := s.prevSequence + connLen
 
 	nextSeq := s.getNextFunc()
@@ -56,11 +57,9 @@ func (s *healthCheckService) runSingleLoop() {
 		s.prevSequence = s.addNextFunc(1)
 
 		index := s.prevSequence % connLen
-		conn := s.conns[index]
+		conn := conns[index]
 
-		pipe := newPipeline(conn, nil)
-		_, _ = pipe.Version()()
-		pipe.Finish()
+		conn.probe()
 	}
 }
 
@@ -57,7 +57,7 @@ func newHealthCheckTest(sleepDuration time.Duration) *healthCheckTest {
 	}
 
 	s.svc = newHealthCheckService(
-		conns,
+		func() []*clientConn { return conns },
 		func() uint64 {
 			s.nextCalls++
 			return s.nextVal
@@ -186,7 +186,7 @@ func initConnWriteLimiter(l *connWriteLimiter, limit int) {
 type connWriteLimiter struct {
 	writeLimit uint64
 
-	cmdWriteCount uint64
+	cmdWriteCount atomic.Uint64 // only modified by the sender goroutine
 
 	readMut      sync.Mutex
 	readCond     *sync.Cond
@@ -196,13 +196,34 @@ type connWriteLimiter struct {
 }
 
 func (l *connWriteLimiter) addWriteCount(num uint64) {
-	l.cmdWriteCount += num
+	l.cmdWriteCount.Add(num)
+}
+
+// inFlightCount returns the number of commands had been written but not yet received responses
+func (l *connWriteLimiter) inFlightCount() uint64 {
+	readCount := l.cmdReadCount.Load()
+	writeCount := l.cmdWriteCount.Load()
+	if writeCount < readCount {
+		return 0
+	}
+	return writeCount - readCount
+}
+
+// pendingCount returns the number of commands pushed to **b** but not yet received responses,
+// including the ones still waiting to be written
+func (l *connWriteLimiter) pendingCount(b *sendBuffer) uint64 {
+	readCount := l.cmdReadCount.Load()
+	pushedCount := b.pushedCount.Load()
+	if pushedCount < readCount {
+		return 0
+	}
+	return pushedCount - readCount
 }
 
 //revive:disable-next-line:flag-parameter
 func (l *connWriteLimiter) allowMoreWrite(num uint64, waiting bool) bool {
 	l.justWaited = false
-	newWriteCount := l.cmdWriteCount + num
+	newWriteCount := l.cmdWriteCount.Load() + num
 
 	if newWriteCount <= l.cmdReadCount.Load()+l.writeLimit {
 		return true
@@ -739,7 +739,7 @@ func TestConnWriteLimiter(t *testing.T) {
 
 		wg.Wait()
 
-		fmt.Println("WRITE COUNT:", l.cmdWriteCount)
+		fmt.Println("WRITE COUNT:", l.cmdWriteCount.Load())
 		fmt.Println("READ COUNT:", l.cmdReadCount.Load())
 	})
 }
@@ -0,0 +1,213 @@
+// Package keyspace aggregates the keys of the metadump command by key families (prefixes or patterns),
+// to find the families wasting the memory of the servers, e.g. large, never fetched or idle items.
+package keyspace
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/QuangTung97/go-memcache/memcache/stats"
+)
+
+// OtherGroup is the group of the keys NOT matching any pattern or exceeding the max number of groups
+const OtherGroup = "(other)"
+
+// Bucket is a bucket of a histogram, counts the values <= UpperBound.
+// The last bucket of a histogram has zero UpperBound and counts the values larger than all other buckets
+type Bucket struct {
+	UpperBound time.Duration `json:"-"`
+	Label      string        `json:"label"`
+	Count      uint64        `json:"count"`
+}
+
+// GroupReport is the aggregated stats of a group of keys
+type GroupReport struct {
+	Name  string `json:"name"`
+	Items uint64 `json:"items"`
+	Bytes uint64 `json:"bytes"`
+
+	NeverFetched      uint64  `json:"never_fetched"`
+	NeverFetchedRatio float64 `json:"never_fetched_ratio"`
+
+	NoExpiry uint64   `json:"no_expiry"` // number of items without TTL, NOT in the TTL histogram
+	TTL      []Bucket `json:"ttl"`       // histogram of the remaining TTLs
+	Idle     []Bucket `json:"idle"`      // histogram of the times since the last access
+}
+
+// Report is the result of an Analyzer
+type Report struct {
+	Total  GroupReport   `json:"total"`
+	Groups []GroupReport `json:"groups"` // sorted by bytes, largest first
+}
+
+// Analyzer aggregates metadump keys, NOT safe for concurrent use
+type Analyzer struct {
+	conf   config
+	now    time.Time
+	total  *GroupReport
+	groups map[string]*GroupReport
+}
+
+// NewAnalyzer creates an Analyzer, the current time is taken at creation
+func NewAnalyzer(options ...Option) *Analyzer {
+	a := &Analyzer{
+		conf:   computeConfig(options...),
+		groups: map[string]*GroupReport{},
+	}
+	a.now = a.conf.nowFunc()
+	a.total = a.newGroup("")
+	return a
+}
+
+func newBuckets(bounds []time.Duration) []Bucket {
+	buckets := make([]Bucket, 0, len(bounds)+1)
+	for _, bound := range bounds {
+		buckets = append(buckets, Bucket{
+			UpperBound: bound,
+			Label:      "<=" + formatDuration(bound),
+		})
+	}
+
+	last := "any"
+	if len(bounds) > 0 {
+		last = ">" + formatDuration(bounds[len(bounds)-1])
+	}
+	return append(buckets, Bucket{Label: last})
+}
+
+var durationUnits = []struct {
+	unit   time.Duration
+	suffix string
+}{
+	{unit: 24 * time.Hour, suffix: "d"},
+	{unit: time.Hour, suffix: "h"},
+	{unit: time.Minute, suffix: "m"},
+	{unit: time.Second, suffix: "s"},
+}
+
+// formatDuration formats in the largest unit dividing the duration, e.g. 7d, 6h, 90m or 30s
+func formatDuration(d time.Duration) string {
+	for _, u := range durationUnits {
+		if d >= u.unit && d%u.unit == 0 {
+			return strconv.FormatInt(int64(d/u.unit), 10) + u.suffix
+		}
+	}
+	return d.String()
+}
+
+func (a *Analyzer) newGroup(name string) *GroupReport {
+	return &GroupReport{
+		Name: name,
+		TTL:  newBuckets(a.conf.ttlBuckets),
+		Idle: newBuckets(a.conf.idleBuckets),
+	}
+}
+
+// groupName returns the group of the key, without the max number of groups limit
+func (a *Analyzer) groupName(key string) string {
+	if len(a.conf.patterns) > 0 {
+		for _, pattern := range a.conf.patterns {
+			if pattern.MatchString(key) {
+				return pattern.String()
+			}
+		}
+		return OtherGroup
+	}
+
+	end := 0
+	for i := 0; i < a.conf.depth; i++ {
+		index := strings.Index(key[end:], a.conf.separator)
+		if index < 0 {
+			break
+		}
+		end += index + len(a.conf.separator)
+	}
+	if end == 0 {
+		return OtherGroup
+	}
+	return key[:end]
+}
+
+func (a *Analyzer) getGroup(key string) *GroupReport {
+	name := a.groupName(key)
+	g, ok := a.groups[name]
+	if ok {
+		return g
+	}
+
+	if len(a.groups) >= a.conf.maxGroups {
+		name = OtherGroup
+		if g, ok := a.groups[name]; ok {
+			return g
+		}
+	}
+
+	g = a.newGroup(name)
+	a.groups[name] = g
+	return g
+}
+
+func addToBuckets(buckets []Bucket, d time.Duration) {
+	for i := range buckets[:len(buckets)-1] {
+		if d <= buckets[i].UpperBound {
+			buckets[i].Count++
+			return
+		}
+	}
+	buckets[len(buckets)-1].Count++
+}
+
+func (a *Analyzer) addToGroup(g *GroupReport, key stats.MetaDumpKey) {
+	g.Items++
+	g.Bytes += uint64(key.Size)
+	if !key.Fetch {
+		g.NeverFetched++
+	}
+
+	if key.Exp < 0 {
+		g.NoExpiry++
+	} else {
+		ttl := time.Unix(key.Exp, 0).Sub(a.now)
+		addToBuckets(g.TTL, max(ttl, 0))
+	}
+
+	idle := a.now.Sub(time.Unix(key.LA, 0))
+	addToBuckets(g.Idle, max(idle, 0))
+}
+
+// Add aggregates a key returned by the metadump command, e.g. the **scanFunc** of stats.Client.MetaDump
+func (a *Analyzer) Add(key stats.MetaDumpKey) {
+	a.addToGroup(a.total, key)
+	a.addToGroup(a.getGroup(key.Key), key)
+}
+
+func cloneGroup(g *GroupReport) GroupReport {
+	result := *g
+	result.TTL = append([]Bucket(nil), g.TTL...)
+	result.Idle = append([]Bucket(nil), g.Idle...)
+	if result.Items > 0 {
+		result.NeverFetchedRatio = float64(result.NeverFetched) / float64(result.Items)
+	}
+	return result
+}
+
+// Report returns the aggregated stats of the keys added so far
+func (a *Analyzer) Report() Report {
+	groups := make([]GroupReport, 0, len(a.groups))
+	for _, g := range a.groups {
+		groups = append(groups, cloneGroup(g))
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Bytes != groups[j].Bytes {
+			return groups[i].Bytes > groups[j].Bytes
+		}
+		return groups[i].Name < groups[j].Name
+	})
+
+	return Report{
+		Total:  cloneGroup(a.total),
+		Groups: groups,
+	}
+}
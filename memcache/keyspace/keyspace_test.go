@@ -0,0 +1,217 @@
+package keyspace
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/QuangTung97/go-memcache/memcache/stats"
+)
+
+var testNow = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newKey(key string, size uint32, fetch bool, exp time.Duration, idle time.Duration) stats.MetaDumpKey {
+	k := stats.MetaDumpKey{
+		Key:   key,
+		Exp:   -1,
+		LA:    testNow.Add(-idle).Unix(),
+		Fetch: fetch,
+		Class: 1,
+		Size:  size,
+	}
+	if exp >= 0 {
+		k.Exp = testNow.Add(exp).Unix()
+	}
+	return k
+}
+
+func newTestAnalyzer(options ...Option) *Analyzer {
+	options = append(options,
+		WithNowFunc(func() time.Time { return testNow }),
+		WithTTLBuckets(time.Minute, time.Hour),
+		WithIdleBuckets(10*time.Second),
+	)
+	return NewAnalyzer(options...)
+}
+
+func TestAnalyzer(t *testing.T) {
+	t.Run("group by prefix", func(t *testing.T) {
+		a := newTestAnalyzer()
+
+		a.Add(newKey("user:1", 100, true, 30*time.Second, 5*time.Second))
+		a.Add(newKey("user:2", 200, false, 2*time.Hour, 20*time.Second))
+		a.Add(newKey("product:1", 400, false, -1, 0))
+		a.Add(newKey("config", 50, true, 30*time.Minute, 0))
+
+		assert.Equal(t, Report{
+			Total: GroupReport{
+				Items:             4,
+				Bytes:             750,
+				NeverFetched:      2,
+				NeverFetchedRatio: 0.5,
+				NoExpiry:          1,
+				TTL: []Bucket{
+					{UpperBound: time.Minute, Label: "<=1m", Count: 1},
+					{UpperBound: time.Hour, Label: "<=1h", Count: 1},
+					{Label: ">1h", Count: 1},
+				},
+				Idle: []Bucket{
+					{UpperBound: 10 * time.Second, Label: "<=10s", Count: 3},
+					{Label: ">10s", Count: 1},
+				},
+			},
+			Groups: []GroupReport{
+				{
+					Name:              "product:",
+					Items:             1,
+					Bytes:             400,
+					NeverFetched:      1,
+					NeverFetchedRatio: 1,
+					NoExpiry:          1,
+					TTL: []Bucket{
+						{UpperBound: time.Minute, Label: "<=1m"},
+						{UpperBound: time.Hour, Label: "<=1h"},
+						{Label: ">1h"},
+					},
+					Idle: []Bucket{
+						{UpperBound: 10 * time.Second, Label: "<=10s", Count: 1},
+						{Label: ">10s"},
+					},
+				},
+				{
+					Name:              "user:",
+					Items:             2,
+					Bytes:             300,
+					NeverFetched:      1,
+					NeverFetchedRatio: 0.5,
+					TTL: []Bucket{
+						{UpperBound: time.Minute, Label: "<=1m", Count: 1},
+						{UpperBound: time.Hour, Label: "<=1h"},
+						{Label: ">1h", Count: 1},
+					},
+					Idle: []Bucket{
+						{UpperBound: 10 * time.Second, Label: "<=10s", Count: 1},
+						{Label: ">10s", Count: 1},
+					},
+				},
+				{
+					Name:  OtherGroup,
+					Items: 1,
+					Bytes: 50,
+					TTL: []Bucket{
+						{UpperBound: time.Minute, Label: "<=1m"},
+						{UpperBound: time.Hour, Label: "<=1h", Count: 1},
+						{Label: ">1h"},
+					},
+					Idle: []Bucket{
+						{UpperBound: 10 * time.Second, Label: "<=10s", Count: 1},
+						{Label: ">10s"},
+					},
+				},
+			},
+		}, a.Report())
+	})
+
+	t.Run("prefix depth", func(t *testing.T) {
+		a := newTestAnalyzer(WithPrefix("/", 2))
+
+		a.Add(newKey("user/1/profile", 100, true, -1, 0))
+		a.Add(newKey("user/1/posts", 100, true, -1, 0))
+		a.Add(newKey("user/2", 300, true, -1, 0))
+
+		report := a.Report()
+		assert.Equal(t, []string{"user/", "user/1/"}, groupNames(report))
+		assert.Equal(t, uint64(2), report.Groups[1].Items)
+	})
+
+	t.Run("group by patterns", func(t *testing.T) {
+		a := newTestAnalyzer(WithPatterns(
+			regexp.MustCompile(`^user:\d+:profile$`),
+			regexp.MustCompile(`^user:`),
+		))
+
+		a.Add(newKey("user:1:profile", 100, true, -1, 0))
+		a.Add(newKey("user:1:posts", 200, true, -1, 0))
+		a.Add(newKey("product:1", 50, true, -1, 0))
+
+		assert.Equal(t, []string{`^user:`, `^user:\d+:profile$`, OtherGroup}, groupNames(a.Report()))
+	})
+
+	t.Run("max groups", func(t *testing.T) {
+		a := newTestAnalyzer(WithMaxGroups(2))
+
+		a.Add(newKey("a:1", 100, true, -1, 0))
+		a.Add(newKey("b:1", 100, true, -1, 0))
+		a.Add(newKey("c:1", 100, true, -1, 0))
+		a.Add(newKey("d:1", 100, true, -1, 0))
+		a.Add(newKey("a:2", 100, true, -1, 0))
+
+		report := a.Report()
+		assert.Equal(t, []string{"(other)", "a:", "b:"}, groupNames(report))
+		assert.Equal(t, uint64(2), report.Groups[0].Items)
+		assert.Equal(t, uint64(5), report.Total.Items)
+	})
+
+	t.Run("unprefixed keys do not use up max groups", func(t *testing.T) {
+		a := newTestAnalyzer(WithMaxGroups(3))
+
+		for _, key := range []string{"session12345", "session67890", "token01", "token02"} {
+			a.Add(newKey(key, 10, true, -1, 0))
+		}
+		for _, key := range []string{"user:1", "user:2", "product:1", "product:2"} {
+			a.Add(newKey(key, 100, true, -1, 0))
+		}
+		a.Add(newKey("order:1", 100, true, -1, 0))
+
+		report := a.Report()
+		assert.Equal(t, []string{"product:", "user:", OtherGroup}, groupNames(report))
+		assert.Equal(t, []uint64{2, 2, 5}, []uint64{
+			report.Groups[0].Items, report.Groups[1].Items, report.Groups[2].Items,
+		})
+	})
+
+	t.Run("expired and accessed in the future", func(t *testing.T) {
+		a := newTestAnalyzer()
+
+		a.Add(newKey("key01", 100, true, 0, -10*time.Second))
+		k := newKey("key02", 100, true, 0, 0)
+		k.Exp = testNow.Add(-time.Minute).Unix()
+		a.Add(k)
+
+		report := a.Report()
+		assert.Equal(t, uint64(2), report.Total.TTL[0].Count)
+		assert.Equal(t, uint64(2), report.Total.Idle[0].Count)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		a := NewAnalyzer(WithIdleBuckets())
+
+		assert.Equal(t, Report{
+			Total: GroupReport{
+				TTL: newBuckets(DefaultBuckets),
+				Idle: []Bucket{
+					{Label: "any"},
+				},
+			},
+			Groups: []GroupReport{},
+		}, a.Report())
+	})
+}
+
+func groupNames(report Report) []string {
+	var names []string
+	for _, g := range report.Groups {
+		names = append(names, g.Name)
+	}
+	return names
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "7d", formatDuration(7*24*time.Hour))
+	assert.Equal(t, "6h", formatDuration(6*time.Hour))
+	assert.Equal(t, "90m", formatDuration(90*time.Minute))
+	assert.Equal(t, "30s", formatDuration(30*time.Second))
+	assert.Equal(t, "500ms", formatDuration(500*time.Millisecond))
+}
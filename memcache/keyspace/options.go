@@ -0,0 +1,95 @@
+package keyspace
+
+import (
+	"regexp"
+	"time"
+)
+
+// Option is an option of the Analyzer
+type Option func(conf *config)
+
+type config struct {
+	separator   string
+	depth       int
+	patterns    []*regexp.Regexp
+	maxGroups   int
+	ttlBuckets  []time.Duration
+	idleBuckets []time.Duration
+	nowFunc     func() time.Time
+}
+
+// DefaultBuckets are the default upper bounds of the TTL & idle time histograms
+var DefaultBuckets = []time.Duration{
+	time.Minute,
+	10 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+func computeConfig(options ...Option) config {
+	conf := config{
+		separator:   ":",
+		depth:       1,
+		maxGroups:   1000,
+		ttlBuckets:  DefaultBuckets,
+		idleBuckets: DefaultBuckets,
+		nowFunc:     time.Now,
+	}
+	for _, fn := range options {
+		fn(&conf)
+	}
+	return conf
+}
+
+// WithPrefix groups the keys by the first **depth** segments split by **separator**,
+// e.g. "user:" for the key "user:123:profile" by default (separator ":" and depth 1).
+// Keys having fewer segments are grouped by the longest prefix found, keys without any separator are in the OtherGroup
+func WithPrefix(separator string, depth int) Option {
+	return func(conf *config) {
+		if separator != "" && depth > 0 {
+			conf.separator = separator
+			conf.depth = depth
+		}
+	}
+}
+
+// WithPatterns groups the keys by the first matched pattern, the name of the group is the pattern.
+// Keys NOT matching any pattern are in the OtherGroup. Prefix grouping is NOT used when patterns are specified
+func WithPatterns(patterns ...*regexp.Regexp) Option {
+	return func(conf *config) {
+		conf.patterns = patterns
+	}
+}
+
+// WithMaxGroups limits the number of groups, the keys of the new groups after the limit are in the OtherGroup.
+// Default is 1000
+func WithMaxGroups(n int) Option {
+	return func(conf *config) {
+		if n > 0 {
+			conf.maxGroups = n
+		}
+	}
+}
+
+// WithTTLBuckets specifies the upper bounds of the remaining TTL histogram, in increasing order
+func WithTTLBuckets(bounds ...time.Duration) Option {
+	return func(conf *config) {
+		conf.ttlBuckets = bounds
+	}
+}
+
+// WithIdleBuckets specifies the upper bounds of the idle time (since the last access) histogram, in increasing order
+func WithIdleBuckets(bounds ...time.Duration) Option {
+	return func(conf *config) {
+		conf.idleBuckets = bounds
+	}
+}
+
+// WithNowFunc specifies the current time the TTLs and the idle times are computed relative to, default is time.Now
+func WithNowFunc(fn func() time.Time) Option {
+	return func(conf *config) {
+		conf.nowFunc = fn
+	}
+}
@@ -0,0 +1,107 @@
+package memcache
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/QuangTung97/go-memcache/memcache/netconn"
+)
+
+// lazyNetConn defers dialing until the first Write / Read.
+// The dial is done at most once, reconnecting is still the job of the background goroutine of clientConn.
+type lazyNetConn struct {
+	dialFunc func() (netconn.NetConn, error)
+
+	once sync.Once
+	used atomic.Bool
+
+	mut    sync.Mutex
+	closed bool
+	nc     netconn.NetConn
+	err    error
+}
+
+var _ netconn.FlushWriter = &lazyNetConn{}
+
+func newLazyNetConn(dialFunc func() (netconn.NetConn, error)) netconn.NetConn {
+	c := &lazyNetConn{
+		dialFunc: dialFunc,
+	}
+	return netconn.NetConn{
+		Writer: c,
+		Reader: c,
+		Closer: c,
+	}
+}
+
+func (c *lazyNetConn) isClosed() bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.closed
+}
+
+func (c *lazyNetConn) doConnect() {
+	if c.isClosed() {
+		c.err = ErrConnClosed
+		return
+	}
+
+	nc, err := c.dialFunc()
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if err == nil && c.closed {
+		_ = nc.Closer.Close()
+		err = ErrConnClosed
+	}
+	c.nc = nc
+	c.err = err
+}
+
+func (c *lazyNetConn) connect() error {
+	c.used.Store(true)
+	c.once.Do(c.doConnect)
+	return c.err
+}
+
+func (c *lazyNetConn) Write(p []byte) (int, error) {
+	if err := c.connect(); err != nil {
+		return 0, err
+	}
+	return c.nc.Writer.Write(p)
+}
+
+// Flush without any previous Write does NOT trigger dialing
+func (c *lazyNetConn) Flush() error {
+	if !c.used.Load() {
+		return nil
+	}
+	if err := c.connect(); err != nil {
+		return err
+	}
+	return c.nc.Writer.Flush()
+}
+
+func (c *lazyNetConn) Read(p []byte) (int, error) {
+	if err := c.connect(); err != nil {
+		return 0, err
+	}
+	return c.nc.Reader.Read(p)
+}
+
+// Close does NOT wait for the dialing in progress, the new connection will be closed right after dialed
+func (c *lazyNetConn) Close() error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	if c.nc.Closer == nil {
+		return nil
+	}
+	return c.nc.Closer.Close()
+}
@@ -0,0 +1,198 @@
+package memcache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/QuangTung97/go-memcache/memcache/netconn"
+)
+
+func newCountingDialFunc(dialErr error) (netconn.DialFunc, *atomic.Int64) {
+	var counter atomic.Int64
+	return func(network, address string, timeout time.Duration) (net.Conn, error) {
+		counter.Add(1)
+		if dialErr != nil {
+			return nil, dialErr
+		}
+		return net.DialTimeout(network, address, timeout)
+	}, &counter
+}
+
+func TestClient_Lazy_Dial(t *testing.T) {
+	t.Run("dial on first use", func(t *testing.T) {
+		dialFunc, counter := newCountingDialFunc(nil)
+
+		c, err := New("localhost:11211", 2, WithLazyDial(), WithDialFunc(dialFunc))
+		assert.Equal(t, nil, err)
+		defer func() { _ = c.Close() }()
+
+		assert.Equal(t, int64(0), counter.Load())
+		assert.Equal(t, []ConnStatus{
+			{State: ConnStateConnecting},
+			{State: ConnStateConnecting},
+		}, c.Status())
+
+		p := c.Pipeline()
+		defer p.Finish()
+
+		resp, err := p.Version()()
+		assert.Equal(t, nil, err)
+		assert.NotEqual(t, "", resp.Version)
+
+		assert.Equal(t, int64(1), counter.Load())
+
+		status := c.Status()
+		assert.Equal(t, ConnStateConnecting, status[0].State)
+		assert.Equal(t, ConnStateConnected, status[1].State)
+	})
+
+	t.Run("closed without using", func(t *testing.T) {
+		dialFunc, counter := newCountingDialFunc(nil)
+
+		c, err := New("localhost:11211", 2, WithLazyDial(), WithDialFunc(dialFunc))
+		assert.Equal(t, nil, err)
+
+		err = c.Close()
+		assert.Equal(t, nil, err)
+
+		assert.Equal(t, int64(0), counter.Load())
+		assert.Equal(t, []ConnStatus{
+			{State: ConnStateClosed},
+			{State: ConnStateClosed},
+		}, c.Status())
+	})
+
+	t.Run("dial error then reconnect", func(t *testing.T) {
+		dialErr := errors.New("cannot connect to memcached")
+
+		var counter atomic.Int64
+		dialFunc := func(network, address string, timeout time.Duration) (net.Conn, error) {
+			if counter.Add(1) == 1 {
+				return nil, dialErr
+			}
+			return net.DialTimeout(network, address, timeout)
+		}
+
+		c, err := New("localhost:11211", 1,
+			WithLazyDial(),
+			WithDialFunc(dialFunc),
+			WithDialErrorLogger(func(err error) {}),
+			WithRetryDuration(10*time.Millisecond),
+		)
+		assert.Equal(t, nil, err)
+		defer func() { _ = c.Close() }()
+
+		p := c.Pipeline()
+		defer p.Finish()
+
+		_, err = p.Version()()
+		assert.Equal(t, dialErr, err)
+
+		time.Sleep(20 * time.Millisecond)
+
+		_, err = p.Version()()
+		assert.Equal(t, nil, err)
+
+		assert.Equal(t, int64(2), counter.Load())
+		assert.Equal(t, []ConnStatus{
+			{State: ConnStateConnected, LastError: dialErr, ReconnectCount: 1},
+		}, c.Status())
+	})
+}
+
+func TestClient_Wait_For_Ready(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		dialFunc, counter := newCountingDialFunc(nil)
+
+		c, err := New("localhost:11211", 3,
+			WithDialFunc(dialFunc),
+			WithWaitForReady(context.Background(), 0),
+		)
+		assert.Equal(t, nil, err)
+		defer func() { _ = c.Close() }()
+
+		assert.Equal(t, int64(3), counter.Load())
+		for _, st := range c.Status() {
+			assert.Equal(t, ConnStateConnected, st.State)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		dialErr := errors.New("cannot connect to memcached")
+		dialFunc, _ := newCountingDialFunc(dialErr)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		c, err := New("localhost:11211", 2,
+			WithDialFunc(dialFunc),
+			WithDialErrorLogger(func(err error) {}),
+			WithRetryDuration(5*time.Millisecond),
+			WithWaitForReady(ctx, 1),
+		)
+		assert.Nil(t, c)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+		assert.Equal(t, &ErrNotReady{
+			NumReady:      0,
+			MinConns:      1,
+			LastDialError: dialErr,
+			CtxErr:        context.DeadlineExceeded,
+		}, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Equal(t,
+			"memcache: not ready, 0 of 1 connections established: "+
+				"context deadline exceeded, last error: cannot connect to memcached",
+			err.Error(),
+		)
+	})
+
+	t.Run("min conns", func(t *testing.T) {
+		var counter atomic.Int64
+		dialFunc := func(network, address string, timeout time.Duration) (net.Conn, error) {
+			if counter.Add(1) == 1 {
+				return net.DialTimeout(network, address, timeout)
+			}
+			return nil, errors.New("cannot connect to memcached")
+		}
+
+		c, err := New("localhost:11211", 2,
+			WithDialFunc(dialFunc),
+			WithDialErrorLogger(func(err error) {}),
+			WithWaitForReady(context.Background(), 1),
+		)
+		assert.Equal(t, nil, err)
+		defer func() { _ = c.Close() }()
+
+		numReady, _ := c.countReadyConns()
+		assert.Equal(t, 1, numReady)
+	})
+
+	t.Run("connections added by set num conns", func(t *testing.T) {
+		dialFunc, counter := newCountingDialFunc(nil)
+
+		c, err := New("localhost:11211", 1,
+			WithDialFunc(dialFunc),
+			WithWaitForReady(context.Background(), 0),
+		)
+		assert.Equal(t, nil, err)
+		defer func() { _ = c.Close() }()
+
+		assert.Equal(t, nil, c.SetNumConns(3))
+
+		// dialed right away, instead of lazily without anyone waiting
+		assert.Equal(t, int64(3), counter.Load())
+		for _, conn := range c.getConns() {
+			assert.Equal(t, ConnStateConnected, conn.status().State)
+		}
+		assert.Nil(t, c.getConns()[1].stateNotifyCh)
+		assert.Nil(t, c.getConns()[2].stateNotifyCh)
+	})
+}
@@ -1,65 +1,202 @@
 package memcache
 
 import (
+	"context"
 	"errors"
+	"sync"
 	"sync/atomic"
+
+	"github.com/QuangTung97/go-memcache/memcache/netconn"
 )
 
 // Client represents a pool of TCP connections to a memcached server
 type Client struct {
-	conns []*clientConn // pool of TCP connections
-	next  atomic.Uint64 // increase by one for each time a new **Pipeline** is created
+	conns atomic.Pointer[[]*clientConn] // pool of TCP connections, copy on write when resizing
+	next  atomic.Uint64                 // increase by one for each time a new **Pipeline** is created
 
 	health *healthCheckService
+
+	newConnFunc       func(index int) *clientConn
+	selectionStrategy ConnSelectionStrategy
+
+	// protect resizing & closing
+	resizeMut sync.Mutex
+	closed    bool
 }
 
 // New creates a Client that contains a pool of TCP connections.
 // Number of TCP connections specified by **numConns** param.
+// The **addr** can be a TCP address (host:port) or a unix domain socket (unix:///path/to/socket).
 func New(addr string, numConns int, options ...Option) (*Client, error) {
 	if numConns <= 0 {
 		return nil, errors.New("numConns must > 0")
 	}
 
 	cmdPool := newPipelineCommandListPool(options...)
+	opts := computeOptions(options...)
+	if err := netconn.ValidateOptions(opts.connOptions...); err != nil {
+		return nil, err
+	}
 
-	conns := make([]*clientConn, 0, numConns)
-	for i := 0; i < numConns; i++ {
-		c := newConn(addr, cmdPool, options...)
-		conns = append(conns, c)
+	var notifyCh chan struct{}
+	var startupOptions []Option
+	if opts.waitForReadyCtx != nil {
+		notifyCh = make(chan struct{}, 1)
+		startupOptions = append(startupOptions, withStateNotify(notifyCh))
+	}
+
+	newConnWithOptions := func(index int, extraOptions ...Option) *clientConn {
+		connOptions := make([]Option, 0, len(options)+1+len(extraOptions))
+		connOptions = append(connOptions, options...)
+		connOptions = append(connOptions, withConnIndex(index))
+		connOptions = append(connOptions, extraOptions...)
+		return newConn(addr, cmdPool, connOptions...)
 	}
 
 	client := &Client{
-		conns: conns,
+		selectionStrategy: opts.selectionStrategy,
 	}
 
-	opts := computeOptions(options...)
+	// the connections added by SetNumConns do NOT take part in the wait for ready of New
+	client.newConnFunc = func(index int) *clientConn {
+		return newConnWithOptions(index, withoutWaitForReady())
+	}
+
+	conns := make([]*clientConn, 0, numConns)
+	for i := 0; i < numConns; i++ {
+		conns = append(conns, newConnWithOptions(i, startupOptions...))
+	}
+	client.conns.Store(&conns)
 
 	client.health = newHealthCheckService(
-		conns,
+		client.getConns,
 		client.next.Load,
 		client.next.Add,
 		opts.healthCheckDuration,
 	)
 	client.health.runInBackground()
 
+	if opts.waitForReadyCtx != nil {
+		err := client.waitForReady(opts.waitForReadyCtx, opts.minReadyConns, notifyCh)
+		if err != nil {
+			_ = client.Close()
+			return nil, err
+		}
+	}
+
 	return client, nil
 }
 
+func (c *Client) countReadyConns() (numReady int, lastErr error) {
+	for _, conn := range c.getConns() {
+		st := conn.status()
+		if st.State == ConnStateConnected {
+			numReady++
+		} else if st.LastError != nil {
+			lastErr = st.LastError
+		}
+	}
+	return numReady, lastErr
+}
+
+func (c *Client) waitForReady(ctx context.Context, minConns int, notifyCh <-chan struct{}) error {
+	conns := c.getConns()
+	if minConns <= 0 || minConns > len(conns) {
+		minConns = len(conns)
+	}
+
+	// trigger the lazy dialing concurrently
+	for _, conn := range conns {
+		go conn.probe()
+	}
+
+	for {
+		numReady, lastErr := c.countReadyConns()
+		if numReady >= minConns {
+			return nil
+		}
+
+		select {
+		case <-notifyCh:
+		case <-ctx.Done():
+			return &ErrNotReady{
+				NumReady:      numReady,
+				MinConns:      minConns,
+				LastDialError: lastErr,
+				CtxErr:        ctx.Err(),
+			}
+		}
+	}
+}
+
 // Close shuts down Client.
 // It waits for all the background goroutines to finish before returning
 func (c *Client) Close() error {
 	c.health.shutdown()
 
-	for _, conn := range c.conns {
+	c.resizeMut.Lock()
+	defer c.resizeMut.Unlock()
+
+	c.closed = true
+
+	conns := c.getConns()
+	for _, conn := range conns {
+		conn.shutdown()
+	}
+	for _, conn := range conns {
+		conn.waitCloseCompleted()
+	}
+	return nil
+}
+
+// SetNumConns grows or shrinks the pool of TCP connections.
+// New connections are dialed the same way as in New, except that WithWaitForReady only applies to New.
+// Removed connections stop receiving new pipelines, their in-flight commands are finished before closing.
+// It blocks until all the removed connections are closed.
+func (c *Client) SetNumConns(numConns int) error {
+	if numConns <= 0 {
+		return errors.New("numConns must > 0")
+	}
+
+	c.resizeMut.Lock()
+	defer c.resizeMut.Unlock()
+
+	if c.closed {
+		return ErrConnClosed
+	}
+
+	oldConns := c.getConns()
+	if numConns >= len(oldConns) {
+		newConns := make([]*clientConn, 0, numConns)
+		newConns = append(newConns, oldConns...)
+		for i := len(oldConns); i < numConns; i++ {
+			newConns = append(newConns, c.newConnFunc(i))
+		}
+		c.conns.Store(&newConns)
+		return nil
+	}
+
+	newConns := make([]*clientConn, numConns)
+	copy(newConns, oldConns)
+	c.conns.Store(&newConns)
+
+	// must be marked after the new connections are stored, for pipelines to select non-retired ones
+	removed := oldConns[numConns:]
+	for _, conn := range removed {
+		conn.retired.Store(true)
 		conn.shutdown()
 	}
-	for _, conn := range c.conns {
+	for _, conn := range removed {
 		conn.waitCloseCompleted()
 	}
 	return nil
 }
 
+func (c *Client) getConns() []*clientConn {
+	return *c.conns.Load()
+}
+
 func (c *Client) getNextConn() *clientConn {
 	next := c.next.Add(1)
-	return c.conns[next%uint64(len(c.conns))]
+	return selectConn(c.selectionStrategy, c.getConns(), next)
 }
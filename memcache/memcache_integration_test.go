@@ -1,11 +1,13 @@
 package memcache
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -23,6 +25,27 @@ func TestClient_New_With_NumConns_Zero(t *testing.T) {
 	assert.Nil(t, c)
 }
 
+func TestClient_New_TLS_With_Custom_Dial_Func(t *testing.T) {
+	auth, err := netconn.NewPasswordAuth("user01", "password01")
+	assert.Equal(t, nil, err)
+
+	var dialCount atomic.Int64
+	dialFunc := func(network, address string, timeout time.Duration) (net.Conn, error) {
+		dialCount.Add(1)
+		return net.DialTimeout(network, address, timeout)
+	}
+
+	c, err := New("localhost:11211", 1,
+		WithTLSConfig(&tls.Config{MinVersion: tls.VersionTLS12}),
+		WithDialFunc(auth.GetDialFunc(dialFunc)),
+	)
+	assert.Equal(t, netconn.ErrTLSWithCustomDialFunc, err)
+	assert.Nil(t, c)
+
+	// never connected, the credentials can NOT be sent before the TLS handshake
+	assert.Equal(t, int64(0), dialCount.Load())
+}
+
 func TestClient_New_Connect_Error(t *testing.T) {
 	dialFunc := func(network, address string, timeout time.Duration) (net.Conn, error) {
 		return nil, errors.New("cannot connect to memcached")
@@ -427,10 +450,14 @@ func TestClient_Connect_To_Memcached_Need_Password_And_Wrong_Pass_Provided(t *te
 	data := []byte("some data")
 
 	_, err = pipe.MSet(key, data, MSetOptions{})()
-	assert.Equal(t, netconn.ErrInvalidUsernamePassword, err)
+	assert.True(t, errors.Is(err, netconn.ErrInvalidUsernamePassword))
+	assert.Equal(t, &netconn.AuthRejectedError{
+		Username: "user",
+		Response: "CLIENT_ERROR authentication failure",
+	}, err)
 
 	resp, err := pipe.MGet(key, MGetOptions{})()
-	assert.Equal(t, netconn.ErrInvalidUsernamePassword, err)
+	assert.True(t, errors.Is(err, netconn.ErrInvalidUsernamePassword))
 	assert.Equal(t, MGetResponse{}, resp)
 }
 
@@ -557,8 +584,8 @@ func TestClient__With_WriteLimit__And_Max_Command_Count(t *testing.T) {
 
 	_ = c.Close()
 
-	send := c.conns[0].core.sender
-	assert.Equal(t, uint64(8), send.selector.writeLimiter.cmdWriteCount)
+	send := c.getConns()[0].core.sender
+	assert.Equal(t, uint64(8), send.selector.writeLimiter.cmdWriteCount.Load())
 	assert.Equal(t, uint64(8), send.selector.writeLimiter.cmdReadCount.Load())
 }
 
@@ -592,3 +619,189 @@ func TestClient_NewPipeline_Without_Exec_Anything__Conn_Seq_Should_Not_Change(t
 
 	assert.Equal(t, uint64(0), c.next.Load())
 }
+
+func mgetTwoKeys(c *Client) (numErrors int64) {
+	p := c.Pipeline()
+	defer p.Finish()
+
+	fn1 := p.MGet("key01", MGetOptions{})
+	fn2 := p.MGet("key02", MGetOptions{})
+	if _, err := fn1(); err != nil {
+		numErrors++
+	}
+	if _, err := fn2(); err != nil {
+		numErrors++
+	}
+	return numErrors
+}
+
+func TestClient_SetNumConns(t *testing.T) {
+	t.Run("grow and shrink", func(t *testing.T) {
+		c, err := New("localhost:11211", 2)
+		assert.Equal(t, nil, err)
+		defer func() { _ = c.Close() }()
+
+		err = c.SetNumConns(4)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 4, len(c.Status()))
+
+		for i := 0; i < 4; i++ {
+			p := c.Pipeline()
+			_, err := p.Version()()
+			assert.Equal(t, nil, err)
+			p.Finish()
+		}
+
+		oldConns := c.getConns()
+
+		err = c.SetNumConns(1)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []ConnStatus{
+			{State: ConnStateConnected},
+		}, c.Status())
+
+		assert.Same(t, oldConns[0], c.getConns()[0])
+		for _, conn := range oldConns[1:] {
+			assert.Equal(t, true, conn.retired.Load())
+			assert.Equal(t, ConnStateClosed, conn.status().State)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		c, err := New("localhost:11211", 2)
+		assert.Equal(t, nil, err)
+
+		err = c.SetNumConns(0)
+		assert.Equal(t, errors.New("numConns must > 0"), err)
+
+		err = c.Close()
+		assert.Equal(t, nil, err)
+
+		err = c.SetNumConns(3)
+		assert.Equal(t, ErrConnClosed, err)
+	})
+
+	t.Run("pipeline holding removed connection", func(t *testing.T) {
+		c, err := New("localhost:11211", 2)
+		assert.Equal(t, nil, err)
+		defer func() { _ = c.Close() }()
+
+		p := c.Pipeline()
+		defer p.Finish()
+
+		pipelineFlushAll(p)
+		assert.Same(t, c.getConns()[1], p.conn)
+
+		err = c.SetNumConns(1)
+		assert.Equal(t, nil, err)
+
+		_, err = p.MSet("key01", []byte("value01"), MSetOptions{})()
+		assert.Equal(t, nil, err)
+
+		resp, err := p.MGet("key01", MGetOptions{})()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "value01", string(resp.Data))
+
+		assert.Same(t, c.getConns()[0], p.conn)
+	})
+}
+
+func TestClient_SetNumConns__Concurrent_Pipelines(t *testing.T) {
+	c, err := New("localhost:11211", 4)
+	assert.Equal(t, nil, err)
+	defer func() { _ = c.Close() }()
+
+	var errCount atomic.Int64
+	var wg sync.WaitGroup
+	for th := 0; th < 8; th++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				errCount.Add(mgetTwoKeys(c))
+			}
+		}()
+	}
+
+	for _, n := range []int{1, 5, 2, 8, 3} {
+		err := c.SetNumConns(n)
+		assert.Equal(t, nil, err)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int64(0), errCount.Load())
+	assert.Equal(t, 3, len(c.Status()))
+}
+
+// startUnixProxy forwards connections of a unix domain socket to the memcached TCP address
+func startUnixProxy(t *testing.T, tcpAddr string) string {
+	sockPath := filepath.Join(t.TempDir(), "memcached.sock")
+
+	lis, err := net.Listen("unix", sockPath)
+	if err != nil {
+		panic(err)
+	}
+
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		_ = lis.Close()
+		wg.Wait()
+	})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+
+			backend, err := net.Dial("tcp", tcpAddr)
+			if err != nil {
+				panic(err)
+			}
+
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				_, _ = io.Copy(backend, conn)
+				_ = backend.Close()
+			}()
+			go func() {
+				defer wg.Done()
+				_, _ = io.Copy(conn, backend)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	return "unix://" + sockPath
+}
+
+func TestClient_Unix_Socket(t *testing.T) {
+	addr := startUnixProxy(t, "localhost:11211")
+
+	c, err := New(addr, 2)
+	assert.Equal(t, nil, err)
+	defer func() { _ = c.Close() }()
+
+	p := c.Pipeline()
+	defer p.Finish()
+
+	pipelineFlushAll(p)
+
+	_, err = p.MSet("key01", []byte("unix value"), MSetOptions{})()
+	assert.Equal(t, nil, err)
+
+	resp, err := p.MGet("key01", MGetOptions{})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "unix value", string(resp.Data))
+
+	assert.Equal(t, []ConnStatus{
+		{State: ConnStateConnected},
+		{State: ConnStateConnected},
+	}, c.Status())
+}
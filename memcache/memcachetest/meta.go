@@ -0,0 +1,355 @@
+package memcachetest
+
+import (
+	"strconv"
+	"time"
+)
+
+func isValidKey(key string) bool {
+	return len(key) > 0 && len(key) <= maxKeyLength
+}
+
+// metaFlag is a single flag of a meta command, e.g. T30 => {name: 'T', token: "30"}
+type metaFlag struct {
+	name  byte
+	token string
+}
+
+func parseMetaFlags(args []string) []metaFlag {
+	result := make([]metaFlag, 0, len(args))
+	for _, arg := range args {
+		result = append(result, metaFlag{
+			name:  arg[0],
+			token: arg[1:],
+		})
+	}
+	return result
+}
+
+func parseTTL(token string) (int64, bool) {
+	ttl, err := strconv.ParseInt(token, 10, 64)
+	return ttl, err == nil
+}
+
+func parseCAS(token string) (uint64, bool) {
+	cas, err := strconv.ParseUint(token, 10, 64)
+	return cas, err == nil
+}
+
+func appendReturnFlags(resp []byte, key string, it *item, flags []metaFlag, now time.Time) []byte {
+	for _, f := range flags {
+		resp = append(resp, ' ', f.name)
+
+		switch f.name {
+		case 'c':
+			resp = strconv.AppendUint(resp, it.cas, 10)
+		case 't':
+			resp = strconv.AppendInt(resp, remainingTTL(now, it), 10)
+		case 'k':
+			resp = append(resp, key...)
+		case 's':
+			resp = strconv.AppendInt(resp, int64(len(it.value)), 10)
+		case 'f':
+			resp = strconv.AppendUint(resp, uint64(it.flags), 10)
+		case 'O':
+			resp = append(resp, f.token...)
+		case 'l':
+			resp = strconv.AppendInt(resp, now.Unix()-it.lastAccess.Unix(), 10)
+		case 'h':
+			if it.fetched {
+				resp = append(resp, '1')
+			} else {
+				resp = append(resp, '0')
+			}
+		default:
+		}
+	}
+	return resp
+}
+
+// ==============================
+// Meta Get
+// ==============================
+
+type metaGetRequest struct {
+	returnValue bool
+	quiet       bool
+
+	vivify    bool
+	vivifyTTL int64
+
+	hasUpdateTTL bool
+	updateTTL    int64
+
+	returnFlags []metaFlag
+}
+
+//revive:disable-next-line:cyclomatic
+func parseMetaGetRequest(flags []metaFlag) (metaGetRequest, bool) {
+	req := metaGetRequest{}
+	for _, f := range flags {
+		var ok bool
+		switch f.name {
+		case 'v':
+			req.returnValue, ok = true, true
+		case 'q':
+			req.quiet, ok = true, true
+		case 'N':
+			req.vivify = true
+			req.vivifyTTL, ok = parseTTL(f.token)
+		case 'T':
+			req.hasUpdateTTL = true
+			req.updateTTL, ok = parseTTL(f.token)
+		case 'c', 't', 'k', 's', 'f', 'O', 'l', 'h':
+			req.returnFlags = append(req.returnFlags, f)
+			ok = true
+		default:
+		}
+		if !ok {
+			return metaGetRequest{}, false
+		}
+	}
+	return req, true
+}
+
+func formatMetaGetResponse(key string, req metaGetRequest, result metaGetResult, now time.Time) []byte {
+	it := result.item
+
+	var resp []byte
+	if req.returnValue {
+		resp = append(resp, "VA "...)
+		resp = strconv.AppendInt(resp, int64(len(it.value)), 10)
+	} else {
+		resp = append(resp, "HD"...)
+	}
+
+	resp = appendReturnFlags(resp, key, it, req.returnFlags, now)
+
+	if result.token {
+		resp = append(resp, " Z"...)
+	}
+	if result.stale {
+		resp = append(resp, " X"...)
+	}
+	if result.win {
+		resp = append(resp, " W"...)
+	}
+	resp = append(resp, "\r\n"...)
+
+	if req.returnValue {
+		resp = append(resp, it.value...)
+		resp = append(resp, "\r\n"...)
+	}
+	return resp
+}
+
+func (s *session) handleMetaGet(args []string) {
+	if len(args) == 0 || !isValidKey(args[0]) {
+		s.writeClientError("bad command line format")
+		return
+	}
+	key := args[0]
+
+	req, ok := parseMetaGetRequest(parseMetaFlags(args[1:]))
+	if !ok {
+		s.writeClientError("invalid flag")
+		return
+	}
+
+	s.server.store.withLock(func(now time.Time) {
+		result := s.server.store.metaGetUnsafe(now, key, req)
+		if result.item == nil {
+			if !req.quiet {
+				s.writeString("EN\r\n")
+			}
+			return
+		}
+		_, _ = s.writer.Write(formatMetaGetResponse(key, req, result, now))
+	})
+}
+
+// ==============================
+// Meta Set
+// ==============================
+
+type metaSetMode byte
+
+const (
+	metaSetModeSet     metaSetMode = 'S'
+	metaSetModeAdd     metaSetMode = 'E'
+	metaSetModeAppend  metaSetMode = 'A'
+	metaSetModePrepend metaSetMode = 'P'
+	metaSetModeReplace metaSetMode = 'R'
+)
+
+func parseMetaSetMode(token string) (metaSetMode, bool) {
+	if len(token) != 1 {
+		return 0, false
+	}
+	switch mode := metaSetMode(token[0]); mode {
+	case metaSetModeSet, metaSetModeAdd, metaSetModeAppend, metaSetModePrepend, metaSetModeReplace:
+		return mode, true
+	default:
+		return 0, false
+	}
+}
+
+type metaSetRequest struct {
+	cas        uint64
+	ttl        int64
+	flags      uint32
+	mode       metaSetMode
+	invalidate bool
+	quiet      bool
+
+	returnFlags []metaFlag
+}
+
+//revive:disable-next-line:cyclomatic
+func parseMetaSetRequest(flags []metaFlag) (metaSetRequest, bool) {
+	req := metaSetRequest{
+		mode: metaSetModeSet,
+	}
+	for _, f := range flags {
+		var ok bool
+		switch f.name {
+		case 'C':
+			req.cas, ok = parseCAS(f.token)
+		case 'T':
+			req.ttl, ok = parseTTL(f.token)
+		case 'F':
+			clientFlags, err := strconv.ParseUint(f.token, 10, 32)
+			req.flags, ok = uint32(clientFlags), err == nil
+		case 'M':
+			req.mode, ok = parseMetaSetMode(f.token)
+		case 'I':
+			req.invalidate, ok = true, true
+		case 'q':
+			req.quiet, ok = true, true
+		case 'c', 'k', 'O':
+			req.returnFlags = append(req.returnFlags, f)
+			ok = true
+		default:
+		}
+		if !ok {
+			return metaSetRequest{}, false
+		}
+	}
+	return req, true
+}
+
+func (s *session) isTooLarge(key string, dataLen int) bool {
+	return itemHeaderSize+len(key)+1+dataLen+2 > s.server.conf.maxItemSize
+}
+
+// handleMetaSet swallows the data block when the key or the flags are invalid, same as memcached
+func (s *session) handleMetaSet(args []string) (closeConn bool) {
+	if len(args) < 2 {
+		s.writeClientError("bad command line format")
+		return true
+	}
+	key := args[0]
+
+	dataLen, err := strconv.Atoi(args[1])
+	if err != nil || dataLen < 0 {
+		s.writeClientError("bad data chunk")
+		return true
+	}
+
+	data, ok := s.readData(dataLen)
+	if !ok {
+		s.writeClientError("bad data chunk")
+		return true
+	}
+
+	if !isValidKey(key) {
+		s.writeClientError("bad command line format")
+		return false
+	}
+
+	req, ok := parseMetaSetRequest(parseMetaFlags(args[2:]))
+	if !ok {
+		s.writeClientError("invalid flag")
+		return false
+	}
+
+	if s.isTooLarge(key, dataLen) {
+		s.writeServerError("object too large for cache")
+		return false
+	}
+
+	s.server.store.withLock(func(now time.Time) {
+		it, code := s.server.store.metaSetUnsafe(now, key, data, req)
+		if code == "HD" && req.quiet {
+			return
+		}
+
+		resp := []byte(code)
+		if it != nil {
+			resp = appendReturnFlags(resp, key, it, req.returnFlags, now)
+		}
+		resp = append(resp, "\r\n"...)
+		_, _ = s.writer.Write(resp)
+	})
+	return false
+}
+
+// ==============================
+// Meta Delete
+// ==============================
+
+type metaDeleteRequest struct {
+	cas        uint64
+	hasTTL     bool
+	ttl        int64
+	invalidate bool
+	quiet      bool
+}
+
+//revive:disable-next-line:cyclomatic
+func parseMetaDeleteRequest(flags []metaFlag) (metaDeleteRequest, bool) {
+	req := metaDeleteRequest{}
+	for _, f := range flags {
+		var ok bool
+		switch f.name {
+		case 'C':
+			req.cas, ok = parseCAS(f.token)
+		case 'T':
+			req.hasTTL = true
+			req.ttl, ok = parseTTL(f.token)
+		case 'I':
+			req.invalidate, ok = true, true
+		case 'q':
+			req.quiet, ok = true, true
+		case 'k', 'O':
+			ok = true
+		default:
+		}
+		if !ok {
+			return metaDeleteRequest{}, false
+		}
+	}
+	return req, true
+}
+
+func (s *session) handleMetaDelete(args []string) {
+	if len(args) == 0 || !isValidKey(args[0]) {
+		s.writeClientError("bad command line format")
+		return
+	}
+	key := args[0]
+
+	req, ok := parseMetaDeleteRequest(parseMetaFlags(args[1:]))
+	if !ok {
+		s.writeClientError("invalid flag")
+		return
+	}
+
+	s.server.store.withLock(func(now time.Time) {
+		code := s.server.store.metaDeleteUnsafe(now, key, req)
+		if code == "HD" && req.quiet {
+			return
+		}
+		s.writeString(code, "\r\n")
+	})
+}
@@ -0,0 +1,91 @@
+package memcachetest
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// slabClassID is the slab class of every item, the server does NOT have slab classes
+const slabClassID = 1
+
+// metaDumpIncludesClass checks the argument of "lru_crawler metadump": all, hash or a list of slab classes
+func metaDumpIncludesClass(arg string) bool {
+	if arg == "all" || arg == "hash" {
+		return true
+	}
+	for _, class := range strings.Split(arg, ",") {
+		if class == strconv.Itoa(slabClassID) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendMetaDumpLine(buf []byte, key string, it *item) []byte {
+	buf = append(buf, "key="...)
+	buf = append(buf, url.PathEscape(key)...)
+
+	buf = append(buf, " exp="...)
+	if it.expireAt.IsZero() {
+		buf = append(buf, "-1"...)
+	} else {
+		buf = strconv.AppendInt(buf, it.expireAt.Unix(), 10)
+	}
+
+	buf = append(buf, " la="...)
+	buf = strconv.AppendInt(buf, it.lastAccess.Unix(), 10)
+	buf = append(buf, " cas="...)
+	buf = strconv.AppendUint(buf, it.cas, 10)
+
+	buf = append(buf, " fetch="...)
+	if it.fetched {
+		buf = append(buf, "yes"...)
+	} else {
+		buf = append(buf, "no"...)
+	}
+
+	buf = append(buf, " cls="...)
+	buf = strconv.AppendInt(buf, slabClassID, 10)
+	buf = append(buf, " size="...)
+	buf = strconv.AppendInt(buf, int64(itemHeaderSize+len(key)+len(it.value)), 10)
+	buf = append(buf, "\r\n"...)
+	return buf
+}
+
+// metaDumpUnsafe returns the lines of the valid items, sorted by key
+func (s *itemStore) metaDumpUnsafe(now time.Time) []byte {
+	keys := make([]string, 0, len(s.items))
+	for key := range s.items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf []byte
+	for _, key := range keys {
+		it := s.getUnsafe(now, key)
+		if it == nil {
+			continue
+		}
+		buf = appendMetaDumpLine(buf, key, it)
+	}
+	return buf
+}
+
+// handleLRUCrawler only supports the metadump sub command
+func (s *session) handleLRUCrawler(args []string) {
+	if len(args) != 2 || args[0] != "metadump" {
+		s.writeString("ERROR\r\n")
+		return
+	}
+
+	var lines []byte
+	if metaDumpIncludesClass(args[1]) {
+		s.server.store.withLock(func(now time.Time) {
+			lines = s.server.store.metaDumpUnsafe(now)
+		})
+	}
+	s.writeString(string(lines), "END\r\n")
+}
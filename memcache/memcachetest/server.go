@@ -0,0 +1,214 @@
+package memcachetest
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// Server is an in-memory memcached server for testing.
+// It speaks the subset of the text & meta protocol used by the memcache client:
+// mg / ms / md / mn / version / flush_all / stats slabs / lru_crawler metadump,
+// including lease flags, CAS and TTL.
+type Server struct {
+	lis   net.Listener
+	conf  serverConfig
+	store *itemStore
+
+	wg sync.WaitGroup
+
+	mut    sync.Mutex
+	closed bool
+	conns  map[net.Conn]bool
+}
+
+type serverConfig struct {
+	network string
+	address string
+
+	nowFunc     func() time.Time
+	version     string
+	maxItemSize int
+
+	credentials map[string]string
+}
+
+// Option ...
+type Option func(conf *serverConfig)
+
+// WithClock sets the clock used for TTL and last access time, default is time.Now
+func WithClock(nowFunc func() time.Time) Option {
+	return func(conf *serverConfig) {
+		conf.nowFunc = nowFunc
+	}
+}
+
+// WithVersion sets the string returned by the version command
+func WithVersion(version string) Option {
+	return func(conf *serverConfig) {
+		conf.version = version
+	}
+}
+
+// WithMaxItemSize sets the max size of an item (header + key + value), default is 1MB
+func WithMaxItemSize(size int) Option {
+	return func(conf *serverConfig) {
+		conf.maxItemSize = size
+	}
+}
+
+// WithListenAddress sets the listening address, default is a random port on 127.0.0.1
+func WithListenAddress(network string, address string) Option {
+	return func(conf *serverConfig) {
+		conf.network = network
+		conf.address = address
+	}
+}
+
+// WithPasswordAuth requires clients to authenticate using the ASCII "set memcached_auth" command.
+// Can be called multiple times for allowing multiple credentials.
+func WithPasswordAuth(username string, password string) Option {
+	return func(conf *serverConfig) {
+		if conf.credentials == nil {
+			conf.credentials = map[string]string{}
+		}
+		conf.credentials[username] = password
+	}
+}
+
+// NewServer creates a Server listening on a random local port
+func NewServer(options ...Option) (*Server, error) {
+	conf := serverConfig{
+		network: "tcp",
+		address: "127.0.0.1:0",
+
+		nowFunc:     time.Now,
+		version:     "1.6.37",
+		maxItemSize: 1024 * 1024,
+	}
+	for _, fn := range options {
+		fn(&conf)
+	}
+
+	lis, err := net.Listen(conf.network, conf.address)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		lis:   lis,
+		conf:  conf,
+		store: newItemStore(conf.nowFunc),
+		conns: map[net.Conn]bool{},
+	}
+
+	s.wg.Add(1)
+	go s.acceptLoop()
+
+	return s, nil
+}
+
+// Addr returns the listening address, can be passed directly to memcache.New
+func (s *Server) Addr() string {
+	addr := s.lis.Addr()
+	if addr.Network() == "unix" {
+		return "unix://" + addr.String()
+	}
+	return addr.String()
+}
+
+// FlushAll removes all items
+func (s *Server) FlushAll() {
+	s.store.flushAll(0)
+}
+
+// CloseConnections closes all the current client connections, but still accepts new ones
+func (s *Server) CloseConnections() {
+	s.mut.Lock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mut.Unlock()
+}
+
+// Close stops listening, closes all client connections and waits for the background goroutines
+func (s *Server) Close() error {
+	s.mut.Lock()
+	if s.closed {
+		s.mut.Unlock()
+		return nil
+	}
+	s.closed = true
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mut.Unlock()
+
+	err := s.lis.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.lis.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+
+		s.mut.Lock()
+		if s.closed {
+			s.mut.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.conns[conn] = true
+		s.mut.Unlock()
+
+		s.wg.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mut.Lock()
+		delete(s.conns, conn)
+		s.mut.Unlock()
+		_ = conn.Close()
+	}()
+
+	sess := &session{
+		server: s,
+		reader: bufio.NewReaderSize(conn, 64*1024),
+		writer: bufio.NewWriterSize(conn, 64*1024),
+
+		authenticated: len(s.conf.credentials) == 0,
+	}
+
+	for {
+		line, err := sess.readLine()
+		if err != nil {
+			return
+		}
+
+		if closeConn := sess.handleCommand(line); closeConn {
+			_ = sess.writer.Flush()
+			return
+		}
+
+		if sess.reader.Buffered() == 0 {
+			if err := sess.writer.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
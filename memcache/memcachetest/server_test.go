@@ -0,0 +1,449 @@
+package memcachetest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/QuangTung97/go-memcache/memcache"
+	"github.com/QuangTung97/go-memcache/memcache/netconn"
+	"github.com/QuangTung97/go-memcache/memcache/stats"
+)
+
+func newTestServer(t *testing.T, options ...Option) *Server {
+	s, err := NewServer(options...)
+	if err != nil {
+		panic(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func newTestClient(t *testing.T, addr string, options ...memcache.Option) *memcache.Pipeline {
+	c, err := memcache.New(addr, 1, options...)
+	if err != nil {
+		panic(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	p := c.Pipeline()
+	t.Cleanup(p.Finish)
+	return p
+}
+
+type fakeClock struct {
+	mut sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestServer_Get_Set_Delete(t *testing.T) {
+	s := newTestServer(t)
+	p := newTestClient(t, s.Addr())
+
+	resp, err := p.MGet("key01", memcache.MGetOptions{})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MGetResponse{Type: memcache.MGetResponseTypeEN}, resp)
+
+	setResp, err := p.MSet("key01", []byte("value01"), memcache.MSetOptions{})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MSetResponse{Type: memcache.MSetResponseTypeHD}, setResp)
+
+	resp, err = p.MGet("key01", memcache.MGetOptions{CAS: true})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MGetResponse{
+		Type: memcache.MGetResponseTypeVA,
+		Data: []byte("value01"),
+		CAS:  1,
+	}, resp)
+
+	delResp, err := p.MDel("key01", memcache.MDelOptions{})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MDelResponse{Type: memcache.MDelResponseTypeHD}, delResp)
+
+	delResp, err = p.MDel("key01", memcache.MDelOptions{})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MDelResponse{Type: memcache.MDelResponseTypeNF}, delResp)
+
+	resp, err = p.MGet("key01", memcache.MGetOptions{})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MGetResponse{Type: memcache.MGetResponseTypeEN}, resp)
+}
+
+func TestServer_CAS(t *testing.T) {
+	s := newTestServer(t)
+	p := newTestClient(t, s.Addr())
+
+	setResp, err := p.MSet("key01", []byte("value01"), memcache.MSetOptions{CAS: 10})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MSetResponse{Type: memcache.MSetResponseTypeNF}, setResp)
+
+	_, err = p.MSet("key01", []byte("value01"), memcache.MSetOptions{})()
+	assert.Equal(t, nil, err)
+
+	setResp, err = p.MSet("key01", []byte("value02"), memcache.MSetOptions{CAS: 10})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MSetResponse{Type: memcache.MSetResponseTypeEX}, setResp)
+
+	delResp, err := p.MDel("key01", memcache.MDelOptions{CAS: 10})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MDelResponse{Type: memcache.MDelResponseTypeEX}, delResp)
+
+	setResp, err = p.MSet("key01", []byte("value02"), memcache.MSetOptions{CAS: 1})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MSetResponse{Type: memcache.MSetResponseTypeHD}, setResp)
+
+	resp, err := p.MGet("key01", memcache.MGetOptions{CAS: true})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MGetResponse{
+		Type: memcache.MGetResponseTypeVA,
+		Data: []byte("value02"),
+		CAS:  2,
+	}, resp)
+}
+
+func TestServer_Lease(t *testing.T) {
+	s := newTestServer(t)
+	p := newTestClient(t, s.Addr())
+
+	// first get wins the lease
+	resp, err := p.MGet("key01", memcache.MGetOptions{N: 10, CAS: true})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MGetResponse{
+		Type:  memcache.MGetResponseTypeVA,
+		Flags: memcache.MGetFlagW,
+		CAS:   1,
+	}, resp)
+
+	// second get sees the lease had been taken
+	resp, err = p.MGet("key01", memcache.MGetOptions{N: 10, CAS: true})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MGetResponse{
+		Type:  memcache.MGetResponseTypeVA,
+		Flags: memcache.MGetFlagZ,
+		CAS:   1,
+	}, resp)
+
+	_, err = p.MSet("key01", []byte("value01"), memcache.MSetOptions{CAS: 1})()
+	assert.Equal(t, nil, err)
+
+	// invalidate => stale and win again
+	_, err = p.MDel("key01", memcache.MDelOptions{I: true, TTL: 30})()
+	assert.Equal(t, nil, err)
+
+	resp, err = p.MGet("key01", memcache.MGetOptions{N: 10, CAS: true})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MGetResponse{
+		Type:  memcache.MGetResponseTypeVA,
+		Data:  []byte("value01"),
+		Flags: memcache.MGetFlagW | memcache.MGetFlagX,
+		CAS:   3,
+	}, resp)
+
+	resp, err = p.MGet("key01", memcache.MGetOptions{N: 10, CAS: true})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MGetResponse{
+		Type:  memcache.MGetResponseTypeVA,
+		Data:  []byte("value01"),
+		Flags: memcache.MGetFlagX | memcache.MGetFlagZ,
+		CAS:   3,
+	}, resp)
+}
+
+func TestServer_TTL_With_Clock(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	s := newTestServer(t, WithClock(clock.Now))
+	p := newTestClient(t, s.Addr())
+
+	_, err := p.MSet("key01", []byte("value01"), memcache.MSetOptions{TTL: 10})()
+	assert.Equal(t, nil, err)
+
+	clock.Add(9 * time.Second)
+
+	resp, err := p.MGet("key01", memcache.MGetOptions{})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MGetResponseTypeVA, resp.Type)
+
+	clock.Add(time.Second)
+
+	resp, err = p.MGet("key01", memcache.MGetOptions{})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MGetResponse{Type: memcache.MGetResponseTypeEN}, resp)
+}
+
+func TestServer_Version_And_Flush_All(t *testing.T) {
+	s := newTestServer(t, WithVersion("1.6.99"))
+	p := newTestClient(t, s.Addr())
+
+	versionResp, err := p.Version()()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.VersionResponse{Version: "1.6.99"}, versionResp)
+
+	_, err = p.MSet("key01", []byte("value01"), memcache.MSetOptions{})()
+	assert.Equal(t, nil, err)
+
+	err = p.FlushAll()()
+	assert.Equal(t, nil, err)
+
+	resp, err := p.MGet("key01", memcache.MGetOptions{})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MGetResponseTypeEN, resp.Type)
+
+	_, err = p.MSet("key02", []byte("value02"), memcache.MSetOptions{})()
+	assert.Equal(t, nil, err)
+
+	s.FlushAll()
+
+	resp, err = p.MGet("key02", memcache.MGetOptions{})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memcache.MGetResponseTypeEN, resp.Type)
+}
+
+func TestServer_Max_Item_Size(t *testing.T) {
+	s := newTestServer(t, WithMaxItemSize(100))
+	p := newTestClient(t, s.Addr())
+
+	_, err := p.MSet("key01", make([]byte, 30), memcache.MSetOptions{})()
+	assert.Equal(t, nil, err)
+
+	_, err = p.MSet("key01", make([]byte, 40), memcache.MSetOptions{})()
+	assert.Equal(t, memcache.NewServerError(memcache.ObjectTooBigErrorMsg), err)
+}
+
+func TestServer_Close_Connections(t *testing.T) {
+	s := newTestServer(t)
+	p := newTestClient(t, s.Addr(), memcache.WithRetryDuration(10*time.Millisecond))
+
+	_, err := p.MSet("key01", []byte("value01"), memcache.MSetOptions{})()
+	assert.Equal(t, nil, err)
+
+	s.CloseConnections()
+
+	_, err = p.MGet("key01", memcache.MGetOptions{})()
+	assert.Error(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+
+	// items are kept
+	resp, err := p.MGet("key01", memcache.MGetOptions{})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "value01", string(resp.Data))
+}
+
+func TestServer_Password_Auth(t *testing.T) {
+	s := newTestServer(t, WithPasswordAuth("user01", "password01"))
+
+	t.Run("not provided", func(t *testing.T) {
+		p := newTestClient(t, s.Addr())
+
+		_, err := p.MGet("key01", memcache.MGetOptions{})()
+		assert.Equal(t, memcache.NewClientError("unauthenticated"), err)
+	})
+
+	t.Run("provided", func(t *testing.T) {
+		auth, err := netconn.NewPasswordAuth("user01", "password01")
+		assert.Equal(t, nil, err)
+
+		p := newTestClient(t, s.Addr(), memcache.WithDialFunc(auth.GetDialFunc(net.DialTimeout)))
+
+		_, err = p.MSet("key01", []byte("value01"), memcache.MSetOptions{})()
+		assert.Equal(t, nil, err)
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		auth, err := netconn.NewPasswordAuth("user01", "wrong")
+		assert.Equal(t, nil, err)
+
+		p := newTestClient(t, s.Addr(),
+			memcache.WithDialFunc(auth.GetDialFunc(net.DialTimeout)),
+			memcache.WithDialErrorLogger(func(err error) {}),
+		)
+
+		_, err = p.MGet("key01", memcache.MGetOptions{})()
+		assert.True(t, errors.Is(err, netconn.ErrInvalidUsernamePassword))
+	})
+}
+
+func TestServer_Unix_Socket(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "memcached.sock")
+
+	s := newTestServer(t, WithListenAddress("unix", sockPath))
+	assert.Equal(t, "unix://"+sockPath, s.Addr())
+
+	p := newTestClient(t, s.Addr())
+
+	_, err := p.MSet("key01", []byte("value01"), memcache.MSetOptions{})()
+	assert.Equal(t, nil, err)
+}
+
+func TestServer_Raw_Protocol(t *testing.T) {
+	s := newTestServer(t)
+
+	conn, err := net.Dial("tcp", s.Addr())
+	assert.Equal(t, nil, err)
+	defer func() { _ = conn.Close() }()
+
+	reader := bufio.NewReader(conn)
+	request := func(req string) string {
+		_, err := conn.Write([]byte(req))
+		assert.Equal(t, nil, err)
+
+		line, err := reader.ReadString('\n')
+		assert.Equal(t, nil, err)
+		return line
+	}
+
+	assert.Equal(t, "ERROR\r\n", request("unknown\r\n"))
+	assert.Equal(t, "MN\r\n", request("mn\r\n"))
+	assert.Equal(t, "CLIENT_ERROR invalid flag\r\n", request("mg key01 !\r\n"))
+
+	// quiet mode
+	assert.Equal(t, "MN\r\n", request("mg key01 v q\r\nmn\r\n"))
+	assert.Equal(t, "MN\r\n", request("ms key01 2 q\r\nAB\r\nmn\r\n"))
+
+	assert.Equal(t, "HD f0 s2 t-1 kkey01 Oabc\r\n", request("mg key01 f s t k Oabc\r\n"))
+	assert.Equal(t, "NS\r\n", request("ms key01 2 ME\r\nCD\r\n"))
+	assert.Equal(t, "HD\r\n", request("ms key01 2 MA\r\nCD\r\n"))
+	assert.Equal(t, "VA 4\r\n", request("mg key01 v\r\n"))
+
+	line, err := reader.ReadString('\n')
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "ABCD\r\n", line)
+
+	assert.Equal(t, "OK\r\n", request("flush_all\r\n"))
+	assert.Equal(t, "EN\r\n", request("mg key01 v\r\n"))
+}
+
+func TestServer_Invalid_Key_Swallows_Data(t *testing.T) {
+	s := newTestServer(t)
+
+	conn, err := net.Dial("tcp", s.Addr())
+	assert.Equal(t, nil, err)
+	defer func() { _ = conn.Close() }()
+
+	reader := bufio.NewReader(conn)
+	request := func(req string) string {
+		_, err := conn.Write([]byte(req))
+		assert.Equal(t, nil, err)
+
+		line, err := reader.ReadString('\n')
+		assert.Equal(t, nil, err)
+		return line
+	}
+
+	longKey := strings.Repeat("k", maxKeyLength+1)
+
+	assert.Equal(t, "CLIENT_ERROR bad command line format\r\n", request("set "+longKey+" 0 0 4\r\nmn\r\n\r\n"))
+	assert.Equal(t, "HD\r\n", request("ms key01 2\r\nAB\r\n"))
+
+	assert.Equal(t, "CLIENT_ERROR bad command line format\r\n", request("ms "+longKey+" 8\r\nmd key01\r\n"))
+	assert.Equal(t, "VA 2\r\n", request("mg key01 v\r\n"))
+
+	line, err := reader.ReadString('\n')
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "AB\r\n", line)
+
+	t.Run("invalid data length closes the connection", func(t *testing.T) {
+		_, err := conn.Write([]byte("set " + longKey + " 0 0 abc\r\n"))
+		assert.Equal(t, nil, err)
+
+		line, err := reader.ReadString('\n')
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "CLIENT_ERROR bad command line format\r\n", line)
+
+		_, err = reader.ReadString('\n')
+		assert.Equal(t, io.EOF, err)
+	})
+}
+
+func TestServer_MetaDump(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	s := newTestServer(t, WithClock(clock.Now))
+	p := newTestClient(t, s.Addr())
+
+	_, err := p.MSet("key02", []byte("value02"), memcache.MSetOptions{TTL: 100})()
+	assert.Equal(t, nil, err)
+	_, err = p.MSet("key:01", []byte("v1"), memcache.MSetOptions{})()
+	assert.Equal(t, nil, err)
+	_, err = p.MSet("key03", []byte("value03"), memcache.MSetOptions{TTL: 5})()
+	assert.Equal(t, nil, err)
+
+	clock.Add(5 * time.Second)
+
+	_, err = p.MGet("key02", memcache.MGetOptions{})()
+	assert.Equal(t, nil, err)
+
+	c := stats.New(s.Addr())
+	defer func() { _ = c.Close() }()
+
+	var keys []stats.MetaDumpKey
+	err = c.MetaDumpAll(func(key stats.MetaDumpKey) {
+		keys = append(keys, key)
+	})
+	assert.Equal(t, nil, err)
+
+	now := clock.Now().Unix()
+	assert.Equal(t, []stats.MetaDumpKey{
+		{Key: "key02", Exp: now + 95, LA: now, CAS: 1, Fetch: true, Class: 1, Size: 68},
+		{Key: "key:01", Exp: -1, LA: now - 5, CAS: 2, Class: 1, Size: 64},
+	}, keys)
+
+	keys = nil
+	options := stats.MetaDumpOptions{Classes: []uint32{2, 3}}
+	err = c.MetaDump(context.Background(), options, func(key stats.MetaDumpKey) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, nil, err)
+	assert.Nil(t, keys)
+}
+
+func TestServer_Stats_Slabs(t *testing.T) {
+	s := newTestServer(t, WithMaxItemSize(2048))
+	p := newTestClient(t, s.Addr())
+
+	c := stats.New(s.Addr())
+	defer func() { _ = c.Close() }()
+
+	result, err := c.GetSlabsStats()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, stats.SlabsStats{Slabs: map[uint32]stats.SingleSlabStats{}}, result)
+
+	_, err = p.MSet("key01", []byte("value01"), memcache.MSetOptions{})()
+	assert.Equal(t, nil, err)
+	_, err = p.MSet("key02", []byte("value02"), memcache.MSetOptions{})()
+	assert.Equal(t, nil, err)
+
+	result, err = c.GetSlabsStats()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, stats.SlabsStats{
+		ActiveSlabs:   1,
+		TotalMalloced: 2 * 68,
+		SlabIDs:       []uint32{1},
+		Slabs: map[uint32]stats.SingleSlabStats{
+			1: {ChunkSize: 2048, ChunksPerPage: 1, TotalPages: 2, TotalChunks: 2, UsedChunks: 2},
+		},
+	}, result)
+}
@@ -0,0 +1,205 @@
+package memcachetest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// itemHeaderSize is the size of the item header of memcached (including the CAS field),
+// used for computing the same max item size limitation
+const itemHeaderSize = 56
+
+const maxKeyLength = 250
+
+var errLineTooLong = errors.New("memcachetest: line too long")
+
+type session struct {
+	server *Server
+	reader *bufio.Reader
+	writer *bufio.Writer
+
+	authenticated bool
+}
+
+func (s *session) readLine() (string, error) {
+	line, err := s.reader.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", errLineTooLong
+	}
+	if err != nil {
+		return "", err
+	}
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	return string(line), nil
+}
+
+func (s *session) writeString(values ...string) {
+	for _, v := range values {
+		_, _ = s.writer.WriteString(v)
+	}
+}
+
+func (s *session) writeClientError(msg string) {
+	s.writeString("CLIENT_ERROR ", msg, "\r\n")
+}
+
+func (s *session) writeServerError(msg string) {
+	s.writeString("SERVER_ERROR ", msg, "\r\n")
+}
+
+// readData reads a data block of n bytes followed by CRLF
+func (s *session) readData(n int) ([]byte, bool) {
+	data := make([]byte, n+2)
+	if _, err := io.ReadFull(s.reader, data); err != nil {
+		return nil, false
+	}
+	if data[n] != '\r' || data[n+1] != '\n' {
+		return nil, false
+	}
+	return data[:n], true
+}
+
+func (s *session) handleCommand(line string) (closeConn bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		s.writeString("ERROR\r\n")
+		return false
+	}
+
+	if !s.authenticated {
+		return s.handleAuth(fields)
+	}
+
+	switch fields[0] {
+	case "mg":
+		s.handleMetaGet(fields[1:])
+	case "ms":
+		return s.handleMetaSet(fields[1:])
+	case "md":
+		s.handleMetaDelete(fields[1:])
+	case "set":
+		return s.handleSet(fields[1:])
+	case "mn":
+		s.writeString("MN\r\n")
+	case "version":
+		s.writeString("VERSION ", s.server.conf.version, "\r\n")
+	case "flush_all":
+		s.handleFlushAll(fields[1:])
+	case "lru_crawler":
+		s.handleLRUCrawler(fields[1:])
+	case "stats":
+		s.handleStats(fields[1:])
+	case "quit":
+		return true
+	default:
+		s.writeString("ERROR\r\n")
+	}
+	return false
+}
+
+func (s *session) handleAuth(fields []string) (closeConn bool) {
+	if fields[0] != "set" || len(fields) < 5 {
+		s.writeClientError("unauthenticated")
+		return true
+	}
+
+	n, err := strconv.Atoi(fields[4])
+	if err != nil || n < 0 {
+		s.writeClientError("bad command line format")
+		return true
+	}
+
+	data, ok := s.readData(n)
+	if !ok {
+		s.writeClientError("bad data chunk")
+		return true
+	}
+
+	userPass := strings.SplitN(string(data), " ", 2)
+	if len(userPass) == 2 {
+		password, existed := s.server.conf.credentials[userPass[0]]
+		if existed && password == userPass[1] {
+			s.authenticated = true
+			s.writeString("STORED\r\n")
+			return false
+		}
+	}
+
+	s.writeClientError("authentication failure")
+	return true
+}
+
+// handleSet handles the classic set command, it is also used by the ASCII authentication.
+// Same as memcached, the data block is swallowed when the key is invalid
+func (s *session) handleSet(args []string) (closeConn bool) {
+	if len(args) < 4 {
+		s.writeClientError("bad command line format")
+		return true
+	}
+	key := args[0]
+
+	clientFlags, flagsErr := strconv.ParseUint(args[1], 10, 32)
+	ttl, ttlErr := strconv.ParseInt(args[2], 10, 64)
+	dataLen, lenErr := strconv.Atoi(args[3])
+	if flagsErr != nil || ttlErr != nil || lenErr != nil || dataLen < 0 {
+		s.writeClientError("bad command line format")
+		return true
+	}
+
+	data, ok := s.readData(dataLen)
+	if !ok {
+		s.writeClientError("bad data chunk")
+		return true
+	}
+
+	if !isValidKey(key) {
+		s.writeClientError("bad command line format")
+		return false
+	}
+
+	if s.isTooLarge(key, dataLen) {
+		s.writeServerError("object too large for cache")
+		return false
+	}
+
+	s.server.store.withLock(func(now time.Time) {
+		_, _ = s.server.store.metaSetUnsafe(now, key, data, metaSetRequest{
+			ttl:   ttl,
+			flags: uint32(clientFlags),
+			mode:  metaSetModeSet,
+		})
+	})
+
+	if len(args) < 5 || args[4] != "noreply" {
+		s.writeString("STORED\r\n")
+	}
+	return false
+}
+
+func (s *session) handleFlushAll(args []string) {
+	var delay int64
+	noreply := false
+	for _, arg := range args {
+		if arg == "noreply" {
+			noreply = true
+			continue
+		}
+		d, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			s.writeClientError("bad command line format")
+			return
+		}
+		delay = d
+	}
+
+	s.server.store.flushAll(delay)
+	if !noreply {
+		s.writeString("OK\r\n")
+	}
+}
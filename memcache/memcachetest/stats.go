@@ -0,0 +1,58 @@
+package memcachetest
+
+import (
+	"strconv"
+	"time"
+)
+
+// slabsStatsUnsafe returns the stats of the only slab class, in which each item is a chunk of its own page
+func (s *itemStore) slabsStatsUnsafe(now time.Time) (numItems int, totalBytes int) {
+	for key := range s.items {
+		it := s.getUnsafe(now, key)
+		if it == nil {
+			continue
+		}
+		numItems++
+		totalBytes += itemHeaderSize + len(key) + len(it.value)
+	}
+	return numItems, totalBytes
+}
+
+func appendStat(buf []byte, name string, value int) []byte {
+	buf = append(buf, "STAT "...)
+	buf = append(buf, name...)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(value), 10)
+	buf = append(buf, "\r\n"...)
+	return buf
+}
+
+// handleStats only supports the "stats slabs" command
+func (s *session) handleStats(args []string) {
+	if len(args) != 1 || args[0] != "slabs" {
+		s.writeString("ERROR\r\n")
+		return
+	}
+
+	var numItems, totalBytes int
+	s.server.store.withLock(func(now time.Time) {
+		numItems, totalBytes = s.server.store.slabsStatsUnsafe(now)
+	})
+
+	prefix := strconv.Itoa(slabClassID) + ":"
+	activeSlabs := 0
+
+	var buf []byte
+	if numItems > 0 {
+		activeSlabs = 1
+		buf = appendStat(buf, prefix+"chunk_size", s.server.conf.maxItemSize)
+		buf = appendStat(buf, prefix+"chunks_per_page", 1)
+		buf = appendStat(buf, prefix+"total_pages", numItems)
+		buf = appendStat(buf, prefix+"total_chunks", numItems)
+		buf = appendStat(buf, prefix+"used_chunks", numItems)
+	}
+	buf = appendStat(buf, "active_slabs", activeSlabs)
+	buf = appendStat(buf, "total_malloced", totalBytes)
+
+	s.writeString(string(buf), "END\r\n")
+}
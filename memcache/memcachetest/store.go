@@ -0,0 +1,236 @@
+package memcachetest
+
+import (
+	"sync"
+	"time"
+)
+
+// maxRelativeTTL is the same as memcache.MaxRelativeTTL, which can NOT be imported here:
+// the tests of the memcache package use this package
+const maxRelativeTTL = 60 * 60 * 24 * 30
+
+type item struct {
+	value []byte
+	flags uint32
+	cas   uint64
+
+	expireAt time.Time // zero means never expire
+	setTime  time.Time
+
+	lastAccess time.Time
+	fetched    bool
+
+	stale     bool // invalidated by md with I flag
+	tokenSent bool // the win flag W had already been returned
+}
+
+type itemStore struct {
+	nowFunc func() time.Time
+
+	mut        sync.Mutex
+	items      map[string]*item
+	casCounter uint64
+
+	flushAt time.Time // items set before this time are invalid after this time
+}
+
+func newItemStore(nowFunc func() time.Time) *itemStore {
+	return &itemStore{
+		nowFunc: nowFunc,
+		items:   map[string]*item{},
+	}
+}
+
+func (s *itemStore) nextCAS() uint64 {
+	s.casCounter++
+	return s.casCounter
+}
+
+// computeExpireAt converts a TTL in memcached's format to an absolute time
+func computeExpireAt(now time.Time, ttl int64) time.Time {
+	if ttl == 0 {
+		return time.Time{}
+	}
+	if ttl < 0 {
+		return now
+	}
+	if ttl > maxRelativeTTL {
+		return time.Unix(ttl, 0)
+	}
+	return now.Add(time.Duration(ttl) * time.Second)
+}
+
+// remainingTTL returns -1 for items without expiration
+func remainingTTL(now time.Time, it *item) int64 {
+	if it.expireAt.IsZero() {
+		return -1
+	}
+	return it.expireAt.Unix() - now.Unix()
+}
+
+func (s *itemStore) isValid(now time.Time, it *item) bool {
+	if !it.expireAt.IsZero() && !now.Before(it.expireAt) {
+		return false
+	}
+	if !s.flushAt.IsZero() && !now.Before(s.flushAt) && !it.setTime.After(s.flushAt) {
+		return false
+	}
+	return true
+}
+
+// getUnsafe returns nil if the key is not found or expired
+func (s *itemStore) getUnsafe(now time.Time, key string) *item {
+	it, ok := s.items[key]
+	if !ok {
+		return nil
+	}
+	if !s.isValid(now, it) {
+		delete(s.items, key)
+		return nil
+	}
+	return it
+}
+
+func (s *itemStore) flushAll(delay int64) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if delay <= 0 {
+		s.items = map[string]*item{}
+		s.flushAt = time.Time{}
+		return
+	}
+	s.flushAt = computeExpireAt(s.nowFunc(), delay)
+}
+
+// withLock runs fn while holding the lock of the store
+func (s *itemStore) withLock(fn func(now time.Time)) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	fn(s.nowFunc())
+}
+
+// metaGetResult is the result of a mg command, item is nil if not found
+type metaGetResult struct {
+	item *item
+
+	win   bool
+	stale bool
+	token bool // the win token had already been sent to another client
+}
+
+func (s *itemStore) metaGetUnsafe(now time.Time, key string, req metaGetRequest) metaGetResult {
+	it := s.getUnsafe(now, key)
+	if it == nil {
+		if !req.vivify {
+			return metaGetResult{}
+		}
+
+		it = &item{
+			cas:        s.nextCAS(),
+			expireAt:   computeExpireAt(now, req.vivifyTTL),
+			setTime:    now,
+			lastAccess: now,
+			tokenSent:  true,
+		}
+		s.items[key] = it
+		return metaGetResult{item: it, win: true}
+	}
+
+	// same order of checking as memcached
+	result := metaGetResult{
+		item:  it,
+		token: it.tokenSent,
+		stale: it.stale,
+	}
+	if it.stale && !it.tokenSent {
+		result.win = true
+		it.tokenSent = true
+	}
+
+	if req.hasUpdateTTL {
+		it.expireAt = computeExpireAt(now, req.updateTTL)
+	}
+	it.lastAccess = now
+	it.fetched = true
+
+	return result
+}
+
+func checkMetaSetMode(mode metaSetMode, old *item) bool {
+	switch mode {
+	case metaSetModeAdd:
+		return old == nil
+	case metaSetModeReplace, metaSetModeAppend, metaSetModePrepend:
+		return old != nil
+	default:
+		return true
+	}
+}
+
+func (s *itemStore) metaSetUnsafe(now time.Time, key string, data []byte, req metaSetRequest) (*item, string) {
+	old := s.getUnsafe(now, key)
+	if !checkMetaSetMode(req.mode, old) {
+		return nil, "NS"
+	}
+
+	stale := false
+	if req.cas > 0 {
+		if old == nil {
+			return nil, "NF"
+		}
+		if old.cas != req.cas {
+			// with I flag, a CAS older than the item's CAS is stored as stale data
+			if !req.invalidate || req.cas > old.cas {
+				return nil, "EX"
+			}
+			stale = true
+		}
+	}
+
+	it := &item{
+		value:      data,
+		flags:      req.flags,
+		cas:        s.nextCAS(),
+		expireAt:   computeExpireAt(now, req.ttl),
+		setTime:    now,
+		lastAccess: now,
+		stale:      stale,
+	}
+
+	switch req.mode {
+	case metaSetModeAppend:
+		it.value = append(append([]byte{}, old.value...), data...)
+		it.flags, it.expireAt = old.flags, old.expireAt
+	case metaSetModePrepend:
+		it.value = append(append([]byte{}, data...), old.value...)
+		it.flags, it.expireAt = old.flags, old.expireAt
+	default:
+	}
+
+	s.items[key] = it
+	return it, "HD"
+}
+
+func (s *itemStore) metaDeleteUnsafe(now time.Time, key string, req metaDeleteRequest) string {
+	it := s.getUnsafe(now, key)
+	if it == nil {
+		return "NF"
+	}
+	if req.cas > 0 && req.cas != it.cas {
+		return "EX"
+	}
+
+	if !req.invalidate {
+		delete(s.items, key)
+		return "HD"
+	}
+
+	it.stale = true
+	it.tokenSent = false
+	it.cas = s.nextCAS()
+	if req.hasTTL {
+		it.expireAt = computeExpireAt(now, req.ttl)
+	}
+	return "HD"
+}
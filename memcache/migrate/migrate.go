@@ -0,0 +1,270 @@
+// Package migrate copies keys from one memcached server to another while both are serving traffic.
+package migrate
+
+import (
+	"context"
+	"slices"
+
+	"github.com/QuangTung97/go-memcache/memcache"
+	"github.com/QuangTung97/go-memcache/memcache/stats"
+)
+
+// Checkpoint is the resumable state of a migration, with the granularity of slab classes.
+// A slab class interrupted in the middle is migrated again from the beginning when resuming,
+// the keys already copied are then counted as Progress.Exists
+type Checkpoint struct {
+	// Done is the list of slab classes that have been completely migrated
+	Done []uint32 `json:"done"`
+}
+
+func (c Checkpoint) clone() Checkpoint {
+	return Checkpoint{Done: slices.Clone(c.Done)}
+}
+
+func (c Checkpoint) isDone(class uint32) bool {
+	return slices.Contains(c.Done, class)
+}
+
+// Progress is the state of a running migration
+type Progress struct {
+	Class uint32 // the slab class being migrated, zero after all classes are completed
+
+	Dumped    uint64 // number of keys returned by the metadump command
+	Copied    uint64 // number of items added to the destination
+	Exists    uint64 // number of items skipped because the destination already had the keys
+	Missed    uint64 // number of keys deleted, expired or evicted on the source before being copied
+	NotStored uint64 // number of items NOT stored by the destination, e.g. too large for the destination
+	Deleted   uint64 // number of keys deleted from the source, only with WithDeleteSource
+
+	Checkpoint Checkpoint
+}
+
+// Migrator copies the items of a source server to a destination server
+type Migrator struct {
+	conf   config
+	dumper *stats.Client
+	source *memcache.Client
+	dest   *memcache.Client
+}
+
+// NewMigrator creates a Migrator, **dumper** and **source** must connect to the same source server
+func NewMigrator(dumper *stats.Client, source *memcache.Client, dest *memcache.Client, options ...Option) *Migrator {
+	return &Migrator{
+		conf:   computeConfig(options...),
+		dumper: dumper,
+		source: source,
+		dest:   dest,
+	}
+}
+
+type sourceItem struct {
+	key         string
+	value       []byte
+	clientFlags uint32
+	cas         uint64
+	ttl         int64
+}
+
+type migration struct {
+	*Migrator
+
+	keys     []string
+	items    []sourceItem
+	progress Progress
+}
+
+// Run migrates the keys slab class by slab class, using the metadump command.
+// Keys are copied with the add mode, such that the items written to the destination by the clients win.
+// The values, the client flags and the remaining TTLs of the items are copied.
+//
+// Run returns the progress at the time it stops, the Checkpoint of which can be used to resume the migration.
+// The Checkpoint only records the completed slab classes, the class being migrated is restarted when resuming.
+// Items are NOT a consistent point-in-time view of the source, they can be changed while migrating
+func (m *Migrator) Run(ctx context.Context) (Progress, error) {
+	r := &migration{
+		Migrator: m,
+		keys:     make([]string, 0, m.conf.batchSize),
+		items:    make([]sourceItem, 0, m.conf.batchSize),
+		progress: Progress{Checkpoint: m.conf.checkpoint.clone()},
+	}
+
+	slabs, err := m.dumper.GetSlabsStats()
+	if err != nil {
+		return r.progress, err
+	}
+
+	for _, class := range slabs.SlabIDs {
+		if r.progress.Checkpoint.isDone(class) {
+			continue
+		}
+		if err := r.migrateClass(ctx, class); err != nil {
+			return r.progress, err
+		}
+	}
+
+	r.progress.Class = 0
+	return r.progress, nil
+}
+
+func (r *migration) migrateClass(ctx context.Context, class uint32) error {
+	r.progress.Class = class
+
+	var copyErr error
+	options := stats.MetaDumpOptions{Classes: []uint32{class}}
+	err := r.dumper.MetaDump(ctx, options, func(key stats.MetaDumpKey) bool {
+		r.progress.Dumped++
+		r.keys = append(r.keys, key.Key)
+		if len(r.keys) < r.conf.batchSize {
+			return true
+		}
+		copyErr = r.copyKeys()
+		return copyErr == nil
+	})
+	if copyErr != nil {
+		return copyErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := r.copyKeys(); err != nil {
+		return err
+	}
+
+	r.progress.Checkpoint.Done = append(r.progress.Checkpoint.Done, class)
+	r.notifyProgress()
+	return nil
+}
+
+func (r *migration) notifyProgress() {
+	progress := r.progress
+	progress.Checkpoint = progress.Checkpoint.clone()
+	r.conf.progressFunc(progress)
+}
+
+func (r *migration) copyKeys() error {
+	if len(r.keys) == 0 {
+		return nil
+	}
+	defer func() {
+		r.keys = r.keys[:0]
+		r.items = r.items[:0]
+	}()
+
+	if err := r.fetchItems(); err != nil {
+		return err
+	}
+	if err := r.addItems(); err != nil {
+		return err
+	}
+	if r.conf.deleteSource {
+		if err := r.deleteItems(); err != nil {
+			return err
+		}
+	}
+
+	r.notifyProgress()
+	return nil
+}
+
+func (r *migration) fetchItems() error {
+	p := r.source.Pipeline()
+	defer p.Finish()
+
+	fns := make([]func() (memcache.MGetMetaResponse, error), 0, len(r.keys))
+	for _, key := range r.keys {
+		fns = append(fns, p.MGetMeta(key, memcache.MGetOptions{CAS: true}))
+	}
+
+	for i, fn := range fns {
+		resp, err := fn()
+		if err != nil {
+			return err
+		}
+		if resp.Type != memcache.MGetResponseTypeVA {
+			r.progress.Missed++
+			continue
+		}
+		r.items = append(r.items, sourceItem{
+			key:         r.keys[i],
+			value:       resp.Data,
+			clientFlags: resp.ClientFlags,
+			cas:         resp.CAS,
+			ttl:         resp.TTL,
+		})
+	}
+	return nil
+}
+
+// computeTTL converts the remaining TTL of a source item to the TTL of the ms command
+func (r *migration) computeTTL(ttl int64) uint32 {
+	if ttl < 0 {
+		return 0
+	}
+	ttl = max(ttl, 1)
+	if ttl > memcache.MaxRelativeTTL {
+		ttl += r.conf.nowFunc().Unix()
+	}
+	return uint32(ttl)
+}
+
+// addItems keeps in r.items only the items added to or already existed on the destination
+func (r *migration) addItems() error {
+	p := r.dest.Pipeline()
+	defer p.Finish()
+
+	fns := make([]func() (memcache.MSetResponse, error), 0, len(r.items))
+	for _, it := range r.items {
+		fns = append(fns, p.MSet(it.key, it.value, memcache.MSetOptions{
+			TTL:         r.computeTTL(it.ttl),
+			Mode:        memcache.MSetModeAdd,
+			ClientFlags: it.clientFlags,
+		}))
+	}
+
+	copied := r.items[:0]
+	for i, fn := range fns {
+		resp, err := fn()
+		if memcache.IsObjectTooBig(err) {
+			r.progress.NotStored++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		switch resp.Type {
+		case memcache.MSetResponseTypeHD:
+			r.progress.Copied++
+		case memcache.MSetResponseTypeNS:
+			r.progress.Exists++
+		default:
+			r.progress.NotStored++
+			continue
+		}
+		copied = append(copied, r.items[i])
+	}
+	r.items = copied
+	return nil
+}
+
+func (r *migration) deleteItems() error {
+	p := r.source.Pipeline()
+	defer p.Finish()
+
+	fns := make([]func() (memcache.MDelResponse, error), 0, len(r.items))
+	for _, it := range r.items {
+		fns = append(fns, p.MDel(it.key, memcache.MDelOptions{CAS: it.cas}))
+	}
+
+	for _, fn := range fns {
+		resp, err := fn()
+		if err != nil {
+			return err
+		}
+		if resp.Type == memcache.MDelResponseTypeHD {
+			r.progress.Deleted++
+		}
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package migrate
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/QuangTung97/go-memcache/memcache"
+	"github.com/QuangTung97/go-memcache/memcache/memcachetest"
+	"github.com/QuangTung97/go-memcache/memcache/stats"
+)
+
+type fakeClock struct {
+	mut sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.now
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+type serverTest struct {
+	client *memcache.Client
+	dumper *stats.Client
+}
+
+func newServerTest(t *testing.T, clock *fakeClock, options ...memcachetest.Option) *serverTest {
+	options = append(options, memcachetest.WithClock(clock.Now))
+	s, err := memcachetest.NewServer(options...)
+	if err != nil {
+		panic(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	client, err := memcache.New(s.Addr(), 1)
+	if err != nil {
+		panic(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	dumper := stats.New(s.Addr())
+	t.Cleanup(func() { _ = dumper.Close() })
+
+	return &serverTest{
+		client: client,
+		dumper: dumper,
+	}
+}
+
+func (s *serverTest) set(key string, value string, ttl uint32) {
+	s.setWithFlags(key, value, ttl, 0)
+}
+
+func (s *serverTest) setWithFlags(key string, value string, ttl uint32, clientFlags uint32) {
+	p := s.client.Pipeline()
+	defer p.Finish()
+
+	_, err := p.MSet(key, []byte(value), memcache.MSetOptions{TTL: ttl, ClientFlags: clientFlags})()
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (s *serverTest) get(key string) memcache.MGetMetaResponse {
+	p := s.client.Pipeline()
+	defer p.Finish()
+
+	resp, err := p.MGetMeta(key, memcache.MGetOptions{})()
+	if err != nil {
+		panic(err)
+	}
+	return resp
+}
+
+func withClock(clock *fakeClock) Option {
+	return func(conf *config) {
+		conf.nowFunc = clock.Now
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		clock := newFakeClock()
+		source := newServerTest(t, clock)
+		dest := newServerTest(t, clock)
+
+		source.setWithFlags("key01", "value01", 0, 31)
+		source.set("key02", "value02", 100)
+		source.set("key03", "value03", uint32(clock.Now().Unix()+60*24*3600))
+
+		var progresses []Progress
+		m := NewMigrator(source.dumper, source.client, dest.client,
+			WithBatchSize(2), withClock(clock),
+			WithProgressHandler(func(progress Progress) {
+				progresses = append(progresses, progress)
+			}),
+		)
+
+		result, err := m.Run(context.Background())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, Progress{
+			Dumped:     3,
+			Copied:     3,
+			Checkpoint: Checkpoint{Done: []uint32{1}},
+		}, result)
+
+		assert.Equal(t, []Progress{
+			{Class: 1, Dumped: 2, Copied: 2},
+			{Class: 1, Dumped: 3, Copied: 3},
+			{Class: 1, Dumped: 3, Copied: 3, Checkpoint: Checkpoint{Done: []uint32{1}}},
+		}, progresses)
+
+		assert.Equal(t, memcache.MGetMetaResponse{
+			MGetResponse: memcache.MGetResponse{
+				Type: memcache.MGetResponseTypeVA,
+				Data: []byte("value01"),
+			},
+			TTL:         -1,
+			ClientFlags: 31,
+		}, dest.get("key01"))
+		assert.Equal(t, int64(100), dest.get("key02").TTL)
+		assert.Equal(t, uint32(0), dest.get("key02").ClientFlags)
+		assert.Equal(t, int64(60*24*3600), dest.get("key03").TTL)
+
+		// the source is unchanged
+		assert.Equal(t, []byte("value01"), source.get("key01").Data)
+	})
+
+	t.Run("newer writes on destination win", func(t *testing.T) {
+		clock := newFakeClock()
+		source := newServerTest(t, clock)
+		dest := newServerTest(t, clock)
+
+		source.set("key01", "value01", 0)
+		source.set("key02", "value02", 0)
+		dest.set("key02", "newer02", 0)
+
+		m := NewMigrator(source.dumper, source.client, dest.client, withClock(clock))
+		result, err := m.Run(context.Background())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, Progress{
+			Dumped:     2,
+			Copied:     1,
+			Exists:     1,
+			Checkpoint: Checkpoint{Done: []uint32{1}},
+		}, result)
+
+		assert.Equal(t, []byte("value01"), dest.get("key01").Data)
+		assert.Equal(t, []byte("newer02"), dest.get("key02").Data)
+	})
+
+	t.Run("resume from checkpoint", func(t *testing.T) {
+		clock := newFakeClock()
+		source := newServerTest(t, clock)
+		dest := newServerTest(t, clock)
+
+		source.set("key01", "value01", 0)
+
+		m := NewMigrator(source.dumper, source.client, dest.client,
+			WithCheckpoint(Checkpoint{Done: []uint32{1}}),
+		)
+		result, err := m.Run(context.Background())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, Progress{Checkpoint: Checkpoint{Done: []uint32{1}}}, result)
+
+		assert.Equal(t, memcache.MGetResponseTypeEN, dest.get("key01").Type)
+	})
+
+	t.Run("delete source", func(t *testing.T) {
+		clock := newFakeClock()
+		source := newServerTest(t, clock)
+		dest := newServerTest(t, clock)
+
+		source.set("key01", "value01", 0)
+		source.set("key02", "value02", 0)
+		dest.set("key02", "newer02", 0)
+
+		m := NewMigrator(source.dumper, source.client, dest.client, WithDeleteSource())
+		result, err := m.Run(context.Background())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, Progress{
+			Dumped:     2,
+			Copied:     1,
+			Exists:     1,
+			Deleted:    2,
+			Checkpoint: Checkpoint{Done: []uint32{1}},
+		}, result)
+
+		assert.Equal(t, memcache.MGetResponseTypeEN, source.get("key01").Type)
+		assert.Equal(t, memcache.MGetResponseTypeEN, source.get("key02").Type)
+		assert.Equal(t, []byte("value01"), dest.get("key01").Data)
+	})
+
+	t.Run("not stored", func(t *testing.T) {
+		clock := newFakeClock()
+		source := newServerTest(t, clock)
+		dest := newServerTest(t, clock, memcachetest.WithMaxItemSize(100))
+
+		source.set("key01", "value01", 0)
+		source.set("key02", string(make([]byte, 200)), 0)
+
+		m := NewMigrator(source.dumper, source.client, dest.client, WithDeleteSource())
+		result, err := m.Run(context.Background())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, Progress{
+			Dumped:     2,
+			Copied:     1,
+			NotStored:  1,
+			Deleted:    1,
+			Checkpoint: Checkpoint{Done: []uint32{1}},
+		}, result)
+
+		// the item NOT copied is kept on the source
+		assert.Equal(t, memcache.MGetResponseTypeVA, source.get("key02").Type)
+	})
+
+	t.Run("empty source", func(t *testing.T) {
+		clock := newFakeClock()
+		source := newServerTest(t, clock)
+		dest := newServerTest(t, clock)
+
+		m := NewMigrator(source.dumper, source.client, dest.client)
+		result, err := m.Run(context.Background())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, Progress{}, result)
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		clock := newFakeClock()
+		source := newServerTest(t, clock)
+		dest := newServerTest(t, clock)
+
+		source.set("key01", "value01", 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		m := NewMigrator(source.dumper, source.client, dest.client)
+		result, err := m.Run(ctx)
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, Progress{Class: 1}, result)
+		assert.Equal(t, memcache.MGetResponseTypeEN, dest.get("key01").Type)
+	})
+}
+
+func TestMigration_ComputeTTL(t *testing.T) {
+	clock := newFakeClock()
+	r := &migration{Migrator: NewMigrator(nil, nil, nil, withClock(clock))}
+
+	assert.Equal(t, uint32(0), r.computeTTL(-1))
+	assert.Equal(t, uint32(1), r.computeTTL(0))
+	assert.Equal(t, uint32(100), r.computeTTL(100))
+	assert.Equal(t, uint32(clock.Now().Unix()+60*24*3600), r.computeTTL(60*24*3600))
+}
@@ -0,0 +1,60 @@
+package migrate
+
+import (
+	"time"
+)
+
+// Option is an option of the Migrator
+type Option func(conf *config)
+
+type config struct {
+	batchSize    int
+	checkpoint   Checkpoint
+	deleteSource bool
+	progressFunc func(progress Progress)
+	nowFunc      func() time.Time
+}
+
+func computeConfig(options ...Option) config {
+	conf := config{
+		batchSize:    100,
+		progressFunc: func(progress Progress) {},
+		nowFunc:      time.Now,
+	}
+	for _, fn := range options {
+		fn(&conf)
+	}
+	return conf
+}
+
+// WithBatchSize specifies the number of commands in each pipeline, default is 100
+func WithBatchSize(size int) Option {
+	return func(conf *config) {
+		if size > 0 {
+			conf.batchSize = size
+		}
+	}
+}
+
+// WithCheckpoint resumes a migration, the slab classes completed in **checkpoint** are skipped
+func WithCheckpoint(checkpoint Checkpoint) Option {
+	return func(conf *config) {
+		conf.checkpoint = checkpoint.clone()
+	}
+}
+
+// WithDeleteSource deletes the migrated keys from the source server.
+// A key is only deleted if its CAS is unchanged since it was copied, such that newer writes on the source are kept
+func WithDeleteSource() Option {
+	return func(conf *config) {
+		conf.deleteSource = true
+	}
+}
+
+// WithProgressHandler specifies the function called after each batch and after each completed slab class.
+// The Checkpoint of the progress can be persisted to resume the migration later
+func WithProgressHandler(fn func(progress Progress)) Option {
+	return func(conf *config) {
+		conf.progressFunc = fn
+	}
+}
@@ -1,6 +1,7 @@
 package netconn
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -11,17 +12,64 @@ import (
 // ErrInvalidUsernamePassword ...
 var ErrInvalidUsernamePassword = errors.New("netconn: invalid username password")
 
+// AuthRejectedError is returned when memcached rejects the credentials.
+// It matches ErrInvalidUsernamePassword using errors.Is.
+// Network errors while authenticating are returned as is, NOT wrapped by this type.
+type AuthRejectedError struct {
+	Username string
+	Response string // the response line of memcached, without CRLF
+}
+
+func (e *AuthRejectedError) Error() string {
+	return fmt.Sprintf("netconn: authentication rejected for user %q: %s", e.Username, e.Response)
+}
+
+// Is ...
+func (e *AuthRejectedError) Is(target error) bool {
+	return target == ErrInvalidUsernamePassword
+}
+
+// Credentials ...
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// CredentialProvider is consulted on every dial, including reconnects, for supporting secret rotation.
+// When multiple credentials are returned (e.g. both the old & the new one during rotation),
+// they are tried in order until one is accepted.
+type CredentialProvider interface {
+	GetCredentials() ([]Credentials, error)
+}
+
+// CredentialProviderFunc ...
+type CredentialProviderFunc func() ([]Credentials, error)
+
+// GetCredentials ...
+func (f CredentialProviderFunc) GetCredentials() ([]Credentials, error) {
+	return f()
+}
+
 // PasswordAuth is simple ASCII password authentication
 type PasswordAuth struct {
-	username string
-	password string
+	provider CredentialProvider
 }
 
 // NewPasswordAuth ...
 func NewPasswordAuth(username string, password string) (*PasswordAuth, error) {
+	creds := []Credentials{{Username: username, Password: password}}
+	return NewPasswordAuthWithProvider(CredentialProviderFunc(func() ([]Credentials, error) {
+		return creds, nil
+	}))
+}
+
+// NewPasswordAuthWithProvider creates a PasswordAuth that gets the credentials from **provider** on every dial
+func NewPasswordAuthWithProvider(provider CredentialProvider) (*PasswordAuth, error) {
+	if provider == nil {
+		return nil, errors.New("netconn: credential provider must not be nil")
+	}
 	return &PasswordAuth{
-		username: username,
-		password: password,
+		provider: provider,
 	}, nil
 }
 
@@ -38,36 +86,103 @@ func tryReadBytes(reader io.Reader, numBytes int) ([]byte, error) {
 	return data, nil
 }
 
-const storedString = "STORED\r\n"
+const maxAuthResponseLen = 1024
 
-// GetDialFunc ...
-func (a *PasswordAuth) GetDialFunc(dialFunc DialFunc) DialFunc {
-	return func(network, address string, timeout time.Duration) (net.Conn, error) {
-		conn, err := dialFunc(network, address, timeout)
+var errAuthResponseTooLong = errors.New("netconn: authentication response too long")
+
+// readResponseLine reads byte by byte until CRLF, for NOT consuming any data after the response line
+func readResponseLine(reader io.Reader) (string, error) {
+	var line []byte
+	for len(line) < maxAuthResponseLen {
+		b, err := tryReadBytes(reader, 1)
 		if err != nil {
-			return nil, err
+			return "", err
+		}
+		line = append(line, b[0])
+		if bytes.HasSuffix(line, []byte("\r\n")) {
+			return string(line[:len(line)-2]), nil
 		}
+	}
+	return "", errAuthResponseTooLong
+}
 
-		n := len(a.username) + len(a.password) + 1
-		msg := fmt.Sprintf("set memcached_auth 0 0 %d\r\n%s %s\r\n", n, a.username, a.password)
+const storedString = "STORED"
 
-		_, err = conn.Write([]byte(msg))
-		if err != nil {
-			_ = conn.Close()
-			return nil, err
+func authenticate(conn net.Conn, creds Credentials) error {
+	n := len(creds.Username) + len(creds.Password) + 1
+	msg := fmt.Sprintf("set memcached_auth 0 0 %d\r\n%s %s\r\n", n, creds.Username, creds.Password)
+
+	if _, err := conn.Write([]byte(msg)); err != nil {
+		return err
+	}
+
+	line, err := readResponseLine(conn)
+	if err != nil {
+		return err
+	}
+
+	if line != storedString {
+		return &AuthRejectedError{
+			Username: creds.Username,
+			Response: line,
 		}
+	}
+	return nil
+}
 
-		data, err := tryReadBytes(conn, len(storedString))
-		if err != nil {
+func (a *PasswordAuth) dialAndAuthenticate(
+	dialFunc DialFunc, network, address string, timeout time.Duration, creds Credentials,
+) (net.Conn, error) {
+	start := time.Now()
+
+	conn, err := dialFunc(network, address, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if timeout > 0 {
+		if err := conn.SetDeadline(start.Add(timeout)); err != nil {
 			_ = conn.Close()
 			return nil, err
 		}
+	}
 
-		if string(data) != storedString {
-			_ = conn.Close()
-			return nil, ErrInvalidUsernamePassword
+	if err := authenticate(conn, creds); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// GetDialFunc returns a dial function that authenticates right after connected.
+// Each credential is tried with a new connection, because memcached closes the connection after a rejected login.
+// The authentication must finish within the connect timeout, counted from the start of dialing.
+func (a *PasswordAuth) GetDialFunc(dialFunc DialFunc) DialFunc {
+	return func(network, address string, timeout time.Duration) (net.Conn, error) {
+		credsList, err := a.provider.GetCredentials()
+		if err != nil {
+			return nil, fmt.Errorf("netconn: get credentials: %w", err)
+		}
+		if len(credsList) == 0 {
+			return nil, errors.New("netconn: credential provider returned no credentials")
 		}
 
-		return conn, nil
+		var lastErr error
+		for _, creds := range credsList {
+			conn, err := a.dialAndAuthenticate(dialFunc, network, address, timeout, creds)
+			if err == nil {
+				return conn, nil
+			}
+			if !errors.Is(err, ErrInvalidUsernamePassword) {
+				return nil, err
+			}
+			lastErr = err
+		}
+		return nil, lastErr
 	}
 }
@@ -0,0 +1,263 @@
+package netconn
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type authServerTest struct {
+	lis net.Listener
+
+	// user => password
+	accepted map[string]string
+
+	// response after a successful login, to check that the client does not consume it
+	extraResponse string
+
+	// close the connection without responding
+	closeWithoutResponse bool
+
+	mut      sync.Mutex
+	attempts []string
+}
+
+func newAuthServerTest(t *testing.T, accepted map[string]string) *authServerTest {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, nil, err)
+
+	s := &authServerTest{
+		lis:      lis,
+		accepted: accepted,
+	}
+
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		_ = lis.Close()
+		wg.Wait()
+	})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.handleConn(conn)
+			}()
+		}
+	}()
+
+	return s
+}
+
+func (s *authServerTest) handleConn(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	reader := bufio.NewReader(conn)
+	if _, err := reader.ReadString('\n'); err != nil {
+		return
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return
+	}
+	userPass := strings.SplitN(strings.TrimSuffix(line, "\r\n"), " ", 2)
+
+	s.mut.Lock()
+	s.attempts = append(s.attempts, userPass[0])
+	s.mut.Unlock()
+
+	if s.closeWithoutResponse {
+		return
+	}
+
+	password, ok := s.accepted[userPass[0]]
+	if !ok || len(userPass) != 2 || password != userPass[1] {
+		_, _ = conn.Write([]byte("CLIENT_ERROR authentication failure\r\n"))
+		return
+	}
+
+	_, _ = conn.Write([]byte("STORED\r\n" + s.extraResponse))
+	_, _ = io.Copy(io.Discard, reader)
+}
+
+func (s *authServerTest) getAttempts() []string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return append([]string(nil), s.attempts...)
+}
+
+func (s *authServerTest) addr() string {
+	return s.lis.Addr().String()
+}
+
+func staticProvider(creds ...Credentials) CredentialProvider {
+	return CredentialProviderFunc(func() ([]Credentials, error) {
+		return creds, nil
+	})
+}
+
+func TestPasswordAuth(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		s := newAuthServerTest(t, map[string]string{"user01": "pass01"})
+		s.extraResponse = "VERSION 1.6.0\r\n"
+
+		auth, err := NewPasswordAuth("user01", "pass01")
+		assert.Equal(t, nil, err)
+
+		conn, err := auth.GetDialFunc(net.DialTimeout)("tcp", s.addr(), time.Second)
+		assert.Equal(t, nil, err)
+		defer func() { _ = conn.Close() }()
+
+		// response after the login is not consumed
+		data, err := tryReadBytes(conn, len("VERSION 1.6.0\r\n"))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "VERSION 1.6.0\r\n", string(data))
+
+		assert.Equal(t, []string{"user01"}, s.getAttempts())
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		s := newAuthServerTest(t, map[string]string{"user01": "pass01"})
+
+		auth, err := NewPasswordAuth("user01", "wrong")
+		assert.Equal(t, nil, err)
+
+		conn, err := auth.GetDialFunc(net.DialTimeout)("tcp", s.addr(), time.Second)
+		assert.Nil(t, conn)
+		assert.True(t, errors.Is(err, ErrInvalidUsernamePassword))
+		assert.Equal(t, &AuthRejectedError{
+			Username: "user01",
+			Response: "CLIENT_ERROR authentication failure",
+		}, err)
+		assert.Equal(t,
+			`netconn: authentication rejected for user "user01": CLIENT_ERROR authentication failure`,
+			err.Error(),
+		)
+	})
+
+	t.Run("rotation with multiple credentials", func(t *testing.T) {
+		s := newAuthServerTest(t, map[string]string{"user02": "pass02"})
+
+		auth, err := NewPasswordAuthWithProvider(staticProvider(
+			Credentials{Username: "user01", Password: "pass01"},
+			Credentials{Username: "user02", Password: "pass02"},
+		))
+		assert.Equal(t, nil, err)
+
+		conn, err := auth.GetDialFunc(net.DialTimeout)("tcp", s.addr(), time.Second)
+		assert.Equal(t, nil, err)
+		_ = conn.Close()
+
+		assert.Equal(t, []string{"user01", "user02"}, s.getAttempts())
+	})
+
+	t.Run("provider consulted on every dial", func(t *testing.T) {
+		s := newAuthServerTest(t, map[string]string{"user01": "pass01", "user02": "pass02"})
+
+		var mut sync.Mutex
+		current := Credentials{Username: "user01", Password: "pass01"}
+
+		auth, err := NewPasswordAuthWithProvider(CredentialProviderFunc(func() ([]Credentials, error) {
+			mut.Lock()
+			defer mut.Unlock()
+			return []Credentials{current}, nil
+		}))
+		assert.Equal(t, nil, err)
+
+		dialFunc := auth.GetDialFunc(net.DialTimeout)
+
+		conn, err := dialFunc("tcp", s.addr(), time.Second)
+		assert.Equal(t, nil, err)
+		_ = conn.Close()
+
+		mut.Lock()
+		current = Credentials{Username: "user02", Password: "pass02"}
+		mut.Unlock()
+
+		conn, err = dialFunc("tcp", s.addr(), time.Second)
+		assert.Equal(t, nil, err)
+		_ = conn.Close()
+
+		assert.Equal(t, []string{"user01", "user02"}, s.getAttempts())
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		auth, err := NewPasswordAuthWithProvider(CredentialProviderFunc(func() ([]Credentials, error) {
+			return nil, errors.New("secret not found")
+		}))
+		assert.Equal(t, nil, err)
+
+		_, err = auth.GetDialFunc(net.DialTimeout)("tcp", "127.0.0.1:0", time.Second)
+		assert.Equal(t, "netconn: get credentials: secret not found", err.Error())
+		assert.False(t, errors.Is(err, ErrInvalidUsernamePassword))
+	})
+
+	t.Run("empty credentials", func(t *testing.T) {
+		auth, err := NewPasswordAuthWithProvider(staticProvider())
+		assert.Equal(t, nil, err)
+
+		_, err = auth.GetDialFunc(net.DialTimeout)("tcp", "127.0.0.1:0", time.Second)
+		assert.Equal(t, errors.New("netconn: credential provider returned no credentials"), err)
+	})
+
+	t.Run("nil provider", func(t *testing.T) {
+		auth, err := NewPasswordAuthWithProvider(nil)
+		assert.Nil(t, auth)
+		assert.Equal(t, errors.New("netconn: credential provider must not be nil"), err)
+	})
+
+	t.Run("network error is not rejection", func(t *testing.T) {
+		s := newAuthServerTest(t, map[string]string{"user01": "pass01"})
+		s.closeWithoutResponse = true
+
+		auth, err := NewPasswordAuthWithProvider(staticProvider(
+			Credentials{Username: "user01", Password: "pass01"},
+			Credentials{Username: "user02", Password: "pass02"},
+		))
+		assert.Equal(t, nil, err)
+
+		_, err = auth.GetDialFunc(net.DialTimeout)("tcp", s.addr(), time.Second)
+		assert.Equal(t, io.EOF, err)
+		assert.False(t, errors.Is(err, ErrInvalidUsernamePassword))
+
+		// not retry with other credentials
+		assert.Equal(t, []string{"user01"}, s.getAttempts())
+	})
+
+	t.Run("dial error", func(t *testing.T) {
+		auth, err := NewPasswordAuth("user01", "pass01")
+		assert.Equal(t, nil, err)
+
+		dialErr := errors.New("dial error")
+		_, err = auth.GetDialFunc(func(network, address string, timeout time.Duration) (net.Conn, error) {
+			return nil, dialErr
+		})("tcp", "127.0.0.1:0", time.Second)
+		assert.Equal(t, dialErr, err)
+	})
+}
+
+func TestReadResponseLine(t *testing.T) {
+	line, err := readResponseLine(strings.NewReader("STORED\r\nEXTRA"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "STORED", line)
+
+	_, err = readResponseLine(strings.NewReader("STORED"))
+	assert.Equal(t, io.EOF, err)
+
+	_, err = readResponseLine(strings.NewReader(strings.Repeat("A", 2000)))
+	assert.Equal(t, errAuthResponseTooLong, err)
+}
@@ -0,0 +1,87 @@
+package netconn
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BackoffPolicy computes the duration to wait before the next dial attempt.
+// The attempt param starts from 0 for the first retry after a failed dial,
+// and is reset to 0 after the connection is established successfully.
+// Implementations must be safe for concurrent use.
+type BackoffPolicy interface {
+	Backoff(attempt int) time.Duration
+}
+
+type constantBackoff struct {
+	duration time.Duration
+}
+
+// NewConstantBackoff always waits for the same duration between dial attempts
+func NewConstantBackoff(d time.Duration) BackoffPolicy {
+	return &constantBackoff{duration: d}
+}
+
+func (b *constantBackoff) Backoff(int) time.Duration {
+	return b.duration
+}
+
+// seedCounter to avoid policies created at the same nanosecond having the same seed
+var seedCounter atomic.Int64
+
+type exponentialBackoff struct {
+	base        time.Duration
+	maxDuration time.Duration
+
+	mut       sync.Mutex
+	randInt63 func(n int64) int64
+}
+
+// NewExponentialBackoff doubles the wait duration after each failed attempt, starting from **base**,
+// up to **maxDuration**, with full jitter: the actual duration is a random value in [0, computed duration).
+// Full jitter avoids all clients reconnecting at the same time after a server restart.
+func NewExponentialBackoff(base time.Duration, maxDuration time.Duration) BackoffPolicy {
+	seed := time.Now().UnixNano() + seedCounter.Add(1)
+	return newExponentialBackoffWithRand(base, maxDuration, rand.New(rand.NewSource(seed)).Int63n)
+}
+
+func newExponentialBackoffWithRand(
+	base time.Duration, maxDuration time.Duration, randInt63 func(n int64) int64,
+) *exponentialBackoff {
+	return &exponentialBackoff{
+		base:        base,
+		maxDuration: maxDuration,
+		randInt63:   randInt63,
+	}
+}
+
+func (b *exponentialBackoff) computeUpperBound(attempt int) time.Duration {
+	d := b.base
+	for i := 0; i < attempt && d < b.maxDuration; i++ {
+		d *= 2
+	}
+	if d > b.maxDuration {
+		return b.maxDuration
+	}
+	return d
+}
+
+func (b *exponentialBackoff) Backoff(attempt int) time.Duration {
+	upper := b.computeUpperBound(attempt)
+	if upper <= 0 {
+		return 0
+	}
+
+	b.mut.Lock()
+	d := b.randInt63(int64(upper))
+	b.mut.Unlock()
+
+	return time.Duration(d)
+}
+
+// DefaultBackoffPolicy is exponential with base = 50ms and max = 10s, so the first retry is near-immediate
+func DefaultBackoffPolicy() BackoffPolicy {
+	return NewExponentialBackoff(50*time.Millisecond, 10*time.Second)
+}
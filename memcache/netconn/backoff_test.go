@@ -0,0 +1,71 @@
+package netconn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstantBackoff(t *testing.T) {
+	b := NewConstantBackoff(20 * time.Millisecond)
+	assert.Equal(t, 20*time.Millisecond, b.Backoff(0))
+	assert.Equal(t, 20*time.Millisecond, b.Backoff(1))
+	assert.Equal(t, 20*time.Millisecond, b.Backoff(100))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	t.Run("upper bound", func(t *testing.T) {
+		var calls []int64
+		b := newExponentialBackoffWithRand(50*time.Millisecond, time.Second, func(n int64) int64 {
+			calls = append(calls, n)
+			return n - 1
+		})
+
+		assert.Equal(t, 50*time.Millisecond-1, b.Backoff(0))
+		assert.Equal(t, 100*time.Millisecond-1, b.Backoff(1))
+		assert.Equal(t, 200*time.Millisecond-1, b.Backoff(2))
+		assert.Equal(t, 800*time.Millisecond-1, b.Backoff(4))
+		assert.Equal(t, time.Second-1, b.Backoff(5))
+		assert.Equal(t, time.Second-1, b.Backoff(1000))
+
+		assert.Equal(t, []int64{
+			int64(50 * time.Millisecond),
+			int64(100 * time.Millisecond),
+			int64(200 * time.Millisecond),
+			int64(800 * time.Millisecond),
+			int64(time.Second),
+			int64(time.Second),
+		}, calls)
+	})
+
+	t.Run("zero base", func(t *testing.T) {
+		b := NewExponentialBackoff(0, time.Second)
+		assert.Equal(t, time.Duration(0), b.Backoff(3))
+	})
+
+	t.Run("random in range", func(t *testing.T) {
+		b := DefaultBackoffPolicy()
+		for i := 0; i < 1000; i++ {
+			d := b.Backoff(i % 20)
+			assert.GreaterOrEqual(t, d, time.Duration(0))
+			assert.Less(t, d, 10*time.Second)
+		}
+		for i := 0; i < 100; i++ {
+			assert.Less(t, b.Backoff(0), 50*time.Millisecond)
+		}
+	})
+
+	t.Run("different seeds", func(t *testing.T) {
+		b1 := NewExponentialBackoff(time.Second, time.Hour)
+		b2 := NewExponentialBackoff(time.Second, time.Hour)
+
+		same := true
+		for i := 0; i < 10; i++ {
+			if b1.Backoff(10) != b2.Backoff(10) {
+				same = false
+			}
+		}
+		assert.False(t, same)
+	})
+}
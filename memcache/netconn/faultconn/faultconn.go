@@ -0,0 +1,254 @@
+// Package faultconn injects network faults into connections for chaos testing.
+// The faults are randomized by a seeded random source, so a failing run can be reproduced with the same seed.
+//
+// Usage with the memcache client:
+//
+//	dialFunc := faultconn.NewDialFunc(net.DialTimeout,
+//		faultconn.WithSeed(seed),
+//		faultconn.WithFragmentedReads(16),
+//		faultconn.WithLatency(0, 5*time.Millisecond),
+//	)
+//	client, err := memcache.New(addr, 1, memcache.WithDialFunc(dialFunc))
+package faultconn
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/QuangTung97/go-memcache/memcache/netconn"
+)
+
+// ErrInjectedWriteFailure is returned by Write after the limit set by WithFailWritesAfter is reached
+var ErrInjectedWriteFailure = errors.New("faultconn: injected write failure")
+
+type config struct {
+	seed int64
+
+	minLatency time.Duration
+	maxLatency time.Duration
+
+	maxReadFragment int
+
+	failWritesAfter int
+	dropAfterRead   int
+
+	corruptRate float64
+
+	sleepFunc func(d time.Duration)
+}
+
+func computeConfig(options ...Option) config {
+	conf := config{
+		failWritesAfter: -1,
+		dropAfterRead:   -1,
+		sleepFunc:       time.Sleep,
+	}
+	for _, fn := range options {
+		fn(&conf)
+	}
+	return conf
+}
+
+// Option ...
+type Option func(conf *config)
+
+// WithSeed sets the seed of the random source, default is 0
+func WithSeed(seed int64) Option {
+	return func(conf *config) {
+		conf.seed = seed
+	}
+}
+
+// WithLatency delays every Read & Write by a random duration in [min, max]
+func WithLatency(minLatency time.Duration, maxLatency time.Duration) Option {
+	return func(conf *config) {
+		conf.minLatency = minLatency
+		conf.maxLatency = maxLatency
+	}
+}
+
+// WithFragmentedReads makes every Read return a random number of bytes in [1, maxSize],
+// simulating responses split at arbitrary byte boundaries
+func WithFragmentedReads(maxSize int) Option {
+	return func(conf *config) {
+		conf.maxReadFragment = maxSize
+	}
+}
+
+// WithFailWritesAfter makes Write return ErrInjectedWriteFailure after **n** bytes had been written.
+// The bytes before the limit are still sent, the same as a partial write of a broken connection.
+func WithFailWritesAfter(n int) Option {
+	return func(conf *config) {
+		conf.failWritesAfter = n
+	}
+}
+
+// WithDropAfterReadBytes closes the underlying connection after **n** bytes had been read,
+// then Read returns io.EOF, the same as the server closing the connection in the middle of a response
+func WithDropAfterReadBytes(n int) Option {
+	return func(conf *config) {
+		conf.dropAfterRead = n
+	}
+}
+
+// WithCorruptRate changes each read byte to a different value with the probability **rate** (from 0 to 1)
+func WithCorruptRate(rate float64) Option {
+	return func(conf *config) {
+		conf.corruptRate = rate
+	}
+}
+
+// Wrap returns a connection that injects the configured faults into **nc**.
+// The counters for WithFailWritesAfter and WithDropAfterReadBytes are per connection.
+func Wrap(nc net.Conn, options ...Option) net.Conn {
+	conf := computeConfig(options...)
+	return newFaultConn(nc, conf, conf.seed)
+}
+
+// NewDialFunc wraps every connection created by **dialFunc** with the configured faults.
+// Each connection gets its own seed derived from the seed of WithSeed and the order of dialing,
+// so reconnections are also reproducible.
+func NewDialFunc(dialFunc netconn.DialFunc, options ...Option) netconn.DialFunc {
+	conf := computeConfig(options...)
+
+	var mut sync.Mutex
+	seedRand := rand.New(rand.NewSource(conf.seed))
+
+	return func(network, address string, timeout time.Duration) (net.Conn, error) {
+		nc, err := dialFunc(network, address, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		mut.Lock()
+		seed := seedRand.Int63()
+		mut.Unlock()
+
+		return newFaultConn(nc, conf, seed), nil
+	}
+}
+
+type faultConn struct {
+	net.Conn
+
+	conf config
+
+	readMut   sync.Mutex
+	readRand  *rand.Rand
+	readBytes int
+	dropped   bool
+
+	writeMut     sync.Mutex
+	writeRand    *rand.Rand
+	writtenBytes int
+}
+
+var _ net.Conn = &faultConn{}
+
+func newFaultConn(nc net.Conn, conf config, seed int64) *faultConn {
+	// reads & writes are usually on different goroutines => separated random sources
+	seedRand := rand.New(rand.NewSource(seed))
+	return &faultConn{
+		Conn: nc,
+		conf: conf,
+
+		readRand:  rand.New(rand.NewSource(seedRand.Int63())),
+		writeRand: rand.New(rand.NewSource(seedRand.Int63())),
+	}
+}
+
+func (c *faultConn) delay(r *rand.Rand) {
+	if c.conf.maxLatency <= 0 {
+		return
+	}
+
+	d := c.conf.minLatency
+	if diff := c.conf.maxLatency - c.conf.minLatency; diff > 0 {
+		d += time.Duration(r.Int63n(int64(diff) + 1))
+	}
+	c.conf.sleepFunc(d)
+}
+
+// Read ...
+func (c *faultConn) Read(p []byte) (int, error) {
+	c.readMut.Lock()
+	defer c.readMut.Unlock()
+
+	if c.dropped {
+		return 0, io.EOF
+	}
+
+	c.delay(c.readRand)
+
+	if c.conf.dropAfterRead >= 0 {
+		remaining := c.conf.dropAfterRead - c.readBytes
+		if remaining <= 0 {
+			c.dropped = true
+			_ = c.Conn.Close()
+			return 0, io.EOF
+		}
+		if len(p) > remaining {
+			p = p[:remaining]
+		}
+	}
+
+	if c.conf.maxReadFragment > 0 && len(p) > 1 {
+		maxSize := c.conf.maxReadFragment
+		if maxSize > len(p) {
+			maxSize = len(p)
+		}
+		p = p[:1+c.readRand.Intn(maxSize)]
+	}
+
+	n, err := c.Conn.Read(p)
+	c.readBytes += n
+	c.corrupt(p[:n])
+	return n, err
+}
+
+func (c *faultConn) corrupt(data []byte) {
+	if c.conf.corruptRate <= 0 {
+		return
+	}
+	for i := range data {
+		if c.readRand.Float64() < c.conf.corruptRate {
+			data[i] ^= byte(1 + c.readRand.Intn(255))
+		}
+	}
+}
+
+// Write ...
+func (c *faultConn) Write(p []byte) (int, error) {
+	c.writeMut.Lock()
+	defer c.writeMut.Unlock()
+
+	c.delay(c.writeRand)
+
+	if c.conf.failWritesAfter < 0 {
+		n, err := c.Conn.Write(p)
+		c.writtenBytes += n
+		return n, err
+	}
+
+	remaining := c.conf.failWritesAfter - c.writtenBytes
+	if len(p) <= remaining {
+		n, err := c.Conn.Write(p)
+		c.writtenBytes += n
+		return n, err
+	}
+
+	n := 0
+	if remaining > 0 {
+		var err error
+		n, err = c.Conn.Write(p[:remaining])
+		c.writtenBytes += n
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, ErrInjectedWriteFailure
+}
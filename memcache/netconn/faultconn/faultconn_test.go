@@ -0,0 +1,254 @@
+package faultconn
+
+import (
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/QuangTung97/go-memcache/memcache"
+	"github.com/QuangTung97/go-memcache/memcache/memcachetest"
+)
+
+// newConnPair returns both sides of a TCP connection on localhost
+func newConnPair(t *testing.T) (client net.Conn, server net.Conn) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, nil, err)
+	defer func() { _ = lis.Close() }()
+
+	acceptCh := make(chan net.Conn, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			close(acceptCh)
+			return
+		}
+		acceptCh <- conn
+	}()
+
+	client, err = net.Dial("tcp", lis.Addr().String())
+	assert.Equal(t, nil, err)
+
+	server = <-acceptCh
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = server.Close()
+	})
+	return client, server
+}
+
+func readAllWithSizes(t *testing.T, conn net.Conn, total int) ([]byte, []int) {
+	var data []byte
+	var sizes []int
+
+	buf := make([]byte, 1024)
+	for len(data) < total {
+		n, err := conn.Read(buf)
+		assert.Equal(t, nil, err)
+		data = append(data, buf[:n]...)
+		sizes = append(sizes, n)
+	}
+	return data, sizes
+}
+
+func TestFaultConn_Fragmented_Reads(t *testing.T) {
+	runRead := func(seed int64) ([]byte, []int) {
+		client, server := newConnPair(t)
+		conn := Wrap(client, WithSeed(seed), WithFragmentedReads(7))
+
+		_, err := server.Write([]byte(strings.Repeat("0123456789", 50)))
+		assert.Equal(t, nil, err)
+
+		return readAllWithSizes(t, conn, 500)
+	}
+
+	data, sizes := runRead(11)
+	assert.Equal(t, strings.Repeat("0123456789", 50), string(data))
+	assert.Greater(t, len(sizes), 500/7)
+	for _, size := range sizes {
+		assert.LessOrEqual(t, size, 7)
+		assert.GreaterOrEqual(t, size, 1)
+	}
+
+	t.Run("same seed same fragments", func(t *testing.T) {
+		_, newSizes := runRead(11)
+		assert.Equal(t, sizes, newSizes)
+	})
+
+	t.Run("different seed", func(t *testing.T) {
+		_, newSizes := runRead(12)
+		assert.NotEqual(t, sizes, newSizes)
+	})
+}
+
+func TestFaultConn_Fail_Writes_After(t *testing.T) {
+	client, server := newConnPair(t)
+	conn := Wrap(client, WithFailWritesAfter(6))
+
+	n, err := conn.Write([]byte("mn\r\n"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 4, n)
+
+	n, err = conn.Write([]byte("mn\r\n"))
+	assert.Equal(t, ErrInjectedWriteFailure, err)
+	assert.Equal(t, 2, n)
+
+	n, err = conn.Write([]byte("mn\r\n"))
+	assert.Equal(t, ErrInjectedWriteFailure, err)
+	assert.Equal(t, 0, n)
+
+	buf := make([]byte, 6)
+	_, err = io.ReadFull(server, buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "mn\r\nmn", string(buf))
+}
+
+func TestFaultConn_Drop_After_Read_Bytes(t *testing.T) {
+	client, server := newConnPair(t)
+	conn := Wrap(client, WithDropAfterReadBytes(8))
+
+	_, err := server.Write([]byte("VA 5\r\nhello\r\n"))
+	assert.Equal(t, nil, err)
+
+	buf := make([]byte, 8)
+	_, err = io.ReadFull(conn, buf)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "VA 5\r\nhe", string(buf))
+
+	n, err := conn.Read(buf)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+
+	n, err = conn.Read(buf)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+
+	// the server side also sees the connection closed
+	n, err = server.Read(buf)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestFaultConn_Corrupt_Bytes(t *testing.T) {
+	t.Run("always corrupt", func(t *testing.T) {
+		client, server := newConnPair(t)
+		conn := Wrap(client, WithCorruptRate(1))
+
+		input := strings.Repeat("abcdef", 20)
+		_, err := server.Write([]byte(input))
+		assert.Equal(t, nil, err)
+
+		data, _ := readAllWithSizes(t, conn, len(input))
+		assert.Equal(t, len(input), len(data))
+		for i := range data {
+			assert.NotEqual(t, input[i], data[i])
+		}
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		runRead := func() []byte {
+			client, server := newConnPair(t)
+			conn := Wrap(client, WithSeed(5), WithCorruptRate(0.1), WithFragmentedReads(10))
+
+			_, err := server.Write([]byte(strings.Repeat("a", 200)))
+			assert.Equal(t, nil, err)
+
+			data, _ := readAllWithSizes(t, conn, 200)
+			return data
+		}
+
+		data := runRead()
+		assert.NotEqual(t, strings.Repeat("a", 200), string(data))
+		assert.Equal(t, data, runRead())
+	})
+}
+
+func TestFaultConn_Latency(t *testing.T) {
+	client, server := newConnPair(t)
+
+	var mut sync.Mutex
+	var sleeps []time.Duration
+
+	conn := Wrap(client,
+		WithLatency(10*time.Millisecond, 20*time.Millisecond),
+		func(conf *config) {
+			conf.sleepFunc = func(d time.Duration) {
+				mut.Lock()
+				sleeps = append(sleeps, d)
+				mut.Unlock()
+			}
+		},
+	)
+
+	_, err := conn.Write([]byte("mn\r\n"))
+	assert.Equal(t, nil, err)
+
+	_, err = server.Write([]byte("MN\r\n"))
+	assert.Equal(t, nil, err)
+
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	assert.Equal(t, nil, err)
+
+	assert.GreaterOrEqual(t, len(sleeps), 2)
+	for _, d := range sleeps {
+		assert.GreaterOrEqual(t, d, 10*time.Millisecond)
+		assert.LessOrEqual(t, d, 20*time.Millisecond)
+	}
+}
+
+func TestNewDialFunc_With_Memcache_Client(t *testing.T) {
+	server, err := memcachetest.NewServer()
+	assert.Equal(t, nil, err)
+	t.Cleanup(func() { _ = server.Close() })
+
+	t.Run("fragmented reads and latency", func(t *testing.T) {
+		dialFunc := NewDialFunc(net.DialTimeout,
+			WithSeed(1),
+			WithFragmentedReads(3),
+			WithLatency(0, time.Millisecond),
+		)
+
+		c, err := memcache.New(server.Addr(), 2, memcache.WithDialFunc(dialFunc))
+		assert.Equal(t, nil, err)
+		defer func() { _ = c.Close() }()
+
+		p := c.Pipeline()
+		defer p.Finish()
+
+		value := []byte(strings.Repeat("value", 100))
+		_, err = p.MSet("key01", value, memcache.MSetOptions{})()
+		assert.Equal(t, nil, err)
+
+		resp, err := p.MGet("key01", memcache.MGetOptions{})()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, memcache.MGetResponse{
+			Type: memcache.MGetResponseTypeVA,
+			Data: value,
+		}, resp)
+	})
+
+	t.Run("drop connection mid response", func(t *testing.T) {
+		dialFunc := NewDialFunc(net.DialTimeout, WithDropAfterReadBytes(10))
+
+		c, err := memcache.New(server.Addr(), 1,
+			memcache.WithDialFunc(dialFunc),
+			memcache.WithDialErrorLogger(func(err error) {}),
+		)
+		assert.Equal(t, nil, err)
+		defer func() { _ = c.Close() }()
+
+		p := c.Pipeline()
+		defer p.Finish()
+
+		_, err = p.MSet("key02", []byte(strings.Repeat("x", 100)), memcache.MSetOptions{})()
+		assert.Equal(t, nil, err)
+
+		_, err = p.MGet("key02", memcache.MGetOptions{})()
+		assert.NotEqual(t, nil, err)
+	})
+}
@@ -2,8 +2,11 @@ package netconn
 
 import (
 	"bufio"
+	"crypto/tls"
+	"errors"
 	"io"
 	"net"
+	"strings"
 	"time"
 )
 
@@ -75,6 +78,11 @@ type config struct {
 	readTimeout    time.Duration
 
 	bufferSize int
+
+	tlsConfig *tls.Config
+	auth      *PasswordAuth
+
+	customDialFunc bool
 }
 
 func computeConfig(options ...Option) config {
@@ -97,21 +105,68 @@ func computeConfig(options ...Option) config {
 	return conf
 }
 
-// DialNewConn ...
+// UnixAddressPrefix is the prefix of unix domain socket addresses, e.g. unix:///var/run/memcached.sock
+const UnixAddressPrefix = "unix://"
+
+// ParseAddress returns the network & the address for the dial function.
+// Addresses with prefix unix:// are unix domain sockets, others are TCP addresses
+func ParseAddress(addr string) (network string, address string) {
+	if strings.HasPrefix(addr, UnixAddressPrefix) {
+		return "unix", strings.TrimPrefix(addr, UnixAddressPrefix)
+	}
+	return "tcp", addr
+}
+
+// ErrTLSWithCustomDialFunc is returned when both WithTLSConfig & WithDialFunc are used.
+// The TLS handshake can NOT be done underneath a custom dial function (e.g. the authentication would be sent
+// before the handshake), wrap the base dial function with NewTLSDialFunc instead
+var ErrTLSWithCustomDialFunc = errors.New("netconn: WithTLSConfig can not be used with WithDialFunc")
+
+// getDialFunc returns the dial function doing the TLS handshake before the authentication
+func (c config) getDialFunc() (DialFunc, error) {
+	dialFunc := c.dialFunc
+	if c.tlsConfig != nil {
+		if c.customDialFunc {
+			return nil, ErrTLSWithCustomDialFunc
+		}
+		dialFunc = NewTLSDialFunc(dialFunc, c.tlsConfig)
+	}
+	if c.auth != nil {
+		dialFunc = c.auth.GetDialFunc(dialFunc)
+	}
+	return dialFunc, nil
+}
+
+// ValidateOptions checks the options without dialing, e.g. returns ErrTLSWithCustomDialFunc
+func ValidateOptions(options ...Option) error {
+	_, err := computeConfig(options...).getDialFunc()
+	return err
+}
+
+// DialNewConn dials to a TCP address (host:port) or a unix domain socket (unix:///path/to/socket)
 func DialNewConn(addr string, options ...Option) (NetConn, error) {
 	conf := computeConfig(options...)
 
-	nc, err := conf.dialFunc("tcp", addr, conf.connectTimeout)
+	dialFunc, err := conf.getDialFunc()
 	if err != nil {
 		return NetConn{}, err
 	}
 
-	tcpNetConn, ok := nc.(*net.TCPConn)
+	network, address := ParseAddress(addr)
+	nc, err := dialFunc(network, address, conf.connectTimeout)
+	if err != nil {
+		return NetConn{}, err
+	}
+
+	// keep alive is only for TCP connections
+	tcpNetConn, ok := getTCPConn(nc)
 	if ok {
 		if err := tcpNetConn.SetKeepAlive(true); err != nil {
+			_ = nc.Close()
 			return NetConn{}, err
 		}
 		if err := tcpNetConn.SetKeepAlivePeriod(conf.tcpKeepAliveDuration); err != nil {
+			_ = nc.Close()
 			return NetConn{}, err
 		}
 	}
@@ -200,6 +255,14 @@ func WithTCPKeepAliveDuration(d time.Duration) Option {
 func WithDialFunc(dialFunc DialFunc) Option {
 	return func(conf *config) {
 		conf.dialFunc = dialFunc
+		conf.customDialFunc = true
+	}
+}
+
+// WithConnectTimeout sets the timeout for dialing, including the TLS handshake, default is 10 seconds
+func WithConnectTimeout(d time.Duration) Option {
+	return func(conf *config) {
+		conf.connectTimeout = d
 	}
 }
 
@@ -216,3 +279,18 @@ func WithWriteTimeout(d time.Duration) Option {
 		conf.writeTimeout = d
 	}
 }
+
+// WithTLSConfig enables TLS, the handshake is performed within the connect timeout.
+// Can NOT be used with WithDialFunc (see ErrTLSWithCustomDialFunc), use WithPasswordAuth for authentication
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(conf *config) {
+		conf.tlsConfig = tlsConfig
+	}
+}
+
+// WithPasswordAuth authenticates right after connected, after the TLS handshake if WithTLSConfig is used
+func WithPasswordAuth(auth *PasswordAuth) Option {
+	return func(conf *config) {
+		conf.auth = auth
+	}
+}
@@ -0,0 +1,75 @@
+package netconn
+
+import (
+	"bufio"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAddress(t *testing.T) {
+	network, address := ParseAddress("localhost:11211")
+	assert.Equal(t, "tcp", network)
+	assert.Equal(t, "localhost:11211", address)
+
+	network, address = ParseAddress("unix:///var/run/memcached.sock")
+	assert.Equal(t, "unix", network)
+	assert.Equal(t, "/var/run/memcached.sock", address)
+}
+
+// startEchoServer accepts a single connection and writes back every received line
+func startEchoServer(t *testing.T, lis net.Listener) {
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		_ = lis.Close()
+		<-done
+	})
+
+	go func() {
+		defer close(done)
+
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			if _, err := conn.Write(append(scanner.Bytes(), '\n')); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func TestDialNewConn_Unix_Socket(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "memcached.sock")
+
+	lis, err := net.Listen("unix", sockPath)
+	assert.Equal(t, nil, err)
+	startEchoServer(t, lis)
+
+	var dialNetwork string
+	dialFunc := func(network, address string, timeout time.Duration) (net.Conn, error) {
+		dialNetwork = network
+		return net.DialTimeout(network, address, timeout)
+	}
+
+	nc, err := DialNewConn("unix://"+sockPath, WithDialFunc(dialFunc))
+	assert.Equal(t, nil, err)
+	defer func() { _ = nc.Closer.Close() }()
+
+	assert.Equal(t, "unix", dialNetwork)
+
+	_, err = nc.Writer.Write([]byte("version\n"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, nc.Writer.Flush())
+
+	data, err := tryReadBytes(nc.Reader, len("version\n"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "version\n", string(data))
+}
@@ -0,0 +1,71 @@
+package netconn
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+)
+
+// NewTLSDialFunc wraps **dialFunc** to perform the TLS handshake right after connected.
+// The handshake must finish within the connect timeout, counted from the start of dialing.
+// If config.ServerName is empty, the host part of the address is used for verifying the server certificate.
+// The handshake is always performed, **dialFunc** must NOT return connections already wrapped by TLS.
+//
+// Used for TLS with a custom dial function, instead of WithTLSConfig.
+// For using with PasswordAuth, the authentication must happen after the handshake:
+//
+//	auth.GetDialFunc(netconn.NewTLSDialFunc(net.DialTimeout, tlsConfig))
+func NewTLSDialFunc(dialFunc DialFunc, config *tls.Config) DialFunc {
+	return func(network, address string, timeout time.Duration) (net.Conn, error) {
+		start := time.Now()
+
+		conn, err := dialFunc(network, address, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConn := tls.Client(conn, tlsConfigWithServerName(config, network, address))
+
+		if timeout > 0 {
+			if err := tlsConn.SetDeadline(start.Add(timeout)); err != nil {
+				_ = conn.Close()
+				return nil, err
+			}
+		}
+
+		if err := tlsConn.Handshake(); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+
+		if err := tlsConn.SetDeadline(time.Time{}); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
+
+func tlsConfigWithServerName(config *tls.Config, network string, address string) *tls.Config {
+	if config.ServerName != "" || network != "tcp" {
+		return config
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return config
+	}
+
+	config = config.Clone()
+	config.ServerName = host
+	return config
+}
+
+// getTCPConn returns the underlying TCP connection, also for TLS connections
+func getTCPConn(nc net.Conn) (*net.TCPConn, bool) {
+	if tlsConn, ok := nc.(*tls.Conn); ok {
+		nc = tlsConn.NetConn()
+	}
+	tcpConn, ok := nc.(*net.TCPConn)
+	return tcpConn, ok
+}
@@ -0,0 +1,293 @@
+package netconn
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCertificate generates a self-signed certificate for localhost & 127.0.0.1
+func newTestCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Equal(t, nil, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+
+		DNSNames:    []string{"localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Equal(t, nil, err)
+
+	cert, err := x509.ParseCertificate(der)
+	assert.Equal(t, nil, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}, pool
+}
+
+func newTLSListener(t *testing.T) (net.Listener, *tls.Config) {
+	cert, pool := newTestCertificate(t)
+
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+	assert.Equal(t, nil, err)
+
+	return lis, &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
+func localhostAddr(lis net.Listener) string {
+	port := lis.Addr().(*net.TCPAddr).Port
+	return "localhost:" + strconv.Itoa(port)
+}
+
+func TestDialNewConn_TLS(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		lis, clientConf := newTLSListener(t)
+		startEchoServer(t, lis)
+
+		nc, err := DialNewConn(localhostAddr(lis), WithTLSConfig(clientConf))
+		assert.Equal(t, nil, err)
+		defer func() { _ = nc.Closer.Close() }()
+
+		_, err = nc.Writer.Write([]byte("version\n"))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, nil, nc.Writer.Flush())
+
+		data, err := tryReadBytes(nc.Reader, len("version\n"))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "version\n", string(data))
+
+		// server name is not changed
+		assert.Equal(t, "", clientConf.ServerName)
+	})
+
+	t.Run("server name mismatch", func(t *testing.T) {
+		lis, clientConf := newTLSListener(t)
+		startEchoServer(t, lis)
+
+		clientConf.ServerName = "memcached.example.com"
+
+		_, err := DialNewConn(localhostAddr(lis), WithTLSConfig(clientConf))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "memcached.example.com")
+	})
+
+	t.Run("handshake timeout", func(t *testing.T) {
+		// plain TCP listener that never responds to the handshake
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.Equal(t, nil, err)
+		defer func() { _ = lis.Close() }()
+
+		go func() {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = io.Copy(io.Discard, conn)
+		}()
+
+		_, clientConf := newTLSListener(t)
+
+		start := time.Now()
+		_, err = DialNewConn(localhostAddr(lis),
+			WithTLSConfig(clientConf),
+			WithConnectTimeout(50*time.Millisecond),
+		)
+		assert.Error(t, err)
+		assert.True(t, isTimeoutError(err))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestNewTLSDialFunc_Wrapped_Twice(t *testing.T) {
+	lis, clientConf := newTLSListener(t)
+	startEchoServer(t, lis)
+
+	// the second handshake is sent inside the first TLS connection, the server can NOT understand it
+	dialFunc := NewTLSDialFunc(NewTLSDialFunc(net.DialTimeout, clientConf), clientConf)
+
+	_, err := DialNewConn(localhostAddr(lis),
+		WithDialFunc(dialFunc),
+		WithConnectTimeout(200*time.Millisecond),
+	)
+	assert.Error(t, err)
+}
+
+func isTimeoutError(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
+// startAuthServer handles the ASCII authentication, then writes back every received line
+func startAuthServer(t *testing.T, lis net.Listener, userPass string) {
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		_ = lis.Close()
+		<-done
+	})
+
+	go func() {
+		defer close(done)
+
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		reader := bufio.NewReader(conn)
+		if _, err := reader.ReadString('\n'); err != nil { // set memcached_auth ...
+			return
+		}
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if strings.TrimSuffix(line, "\r\n") != userPass {
+			_, _ = conn.Write([]byte("CLIENT_ERROR authentication failure\r\n"))
+			return
+		}
+		_, _ = conn.Write([]byte("STORED\r\n"))
+
+		_, _ = io.Copy(conn, reader)
+	}()
+}
+
+func assertEchoConn(t *testing.T, nc NetConn) {
+	_, err := nc.Writer.Write([]byte("mn\r\n"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, nc.Writer.Flush())
+
+	data, err := tryReadBytes(nc.Reader, len("mn\r\n"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "mn\r\n", string(data))
+}
+
+// startRecordServer records the plaintext data received by the first accepted connection
+func startRecordServer(t *testing.T) (net.Listener, func() string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, nil, err)
+
+	var received bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_ = conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		_, _ = io.Copy(&received, conn)
+	}()
+
+	return lis, func() string {
+		_ = lis.Close()
+		<-done
+		return received.String()
+	}
+}
+
+func TestDialNewConn_TLS_With_Password_Auth(t *testing.T) {
+	t.Run("with password auth option", func(t *testing.T) {
+		// the auth server only receives the credentials after the handshake, over TLS
+		lis, clientConf := newTLSListener(t)
+		startAuthServer(t, lis, "user01 password01")
+
+		auth, err := NewPasswordAuth("user01", "password01")
+		assert.Equal(t, nil, err)
+
+		nc, err := DialNewConn(localhostAddr(lis),
+			WithTLSConfig(clientConf),
+			WithPasswordAuth(auth),
+		)
+		assert.Equal(t, nil, err)
+		defer func() { _ = nc.Closer.Close() }()
+
+		assertEchoConn(t, nc)
+	})
+
+	t.Run("with dial func wrapping tls dial func", func(t *testing.T) {
+		lis, clientConf := newTLSListener(t)
+		startAuthServer(t, lis, "user01 password01")
+
+		auth, err := NewPasswordAuth("user01", "password01")
+		assert.Equal(t, nil, err)
+
+		nc, err := DialNewConn(localhostAddr(lis),
+			WithDialFunc(auth.GetDialFunc(NewTLSDialFunc(net.DialTimeout, clientConf))),
+		)
+		assert.Equal(t, nil, err)
+		defer func() { _ = nc.Closer.Close() }()
+
+		assertEchoConn(t, nc)
+	})
+
+	t.Run("tls config with custom dial func", func(t *testing.T) {
+		lis, getReceived := startRecordServer(t)
+		_, clientConf := newTLSListener(t)
+
+		auth, err := NewPasswordAuth("user01", "password01")
+		assert.Equal(t, nil, err)
+
+		options := []Option{
+			WithTLSConfig(clientConf),
+			WithDialFunc(auth.GetDialFunc(net.DialTimeout)),
+		}
+		assert.Equal(t, ErrTLSWithCustomDialFunc, ValidateOptions(options...))
+
+		_, err = DialNewConn(localhostAddr(lis), options...)
+		assert.Equal(t, ErrTLSWithCustomDialFunc, err)
+
+		// the credentials are never sent in plaintext
+		assert.Equal(t, "", getReceived())
+	})
+}
+
+func TestGetTCPConn(t *testing.T) {
+	lis, clientConf := newTLSListener(t)
+	startEchoServer(t, lis)
+
+	conn, err := NewTLSDialFunc(net.DialTimeout, clientConf)("tcp", localhostAddr(lis), time.Second)
+	assert.Equal(t, nil, err)
+	defer func() { _ = conn.Close() }()
+
+	tcpConn, ok := getTCPConn(conn)
+	assert.Equal(t, true, ok)
+	assert.NotNil(t, tcpConn)
+}
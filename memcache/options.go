@@ -1,6 +1,8 @@
 package memcache
 
 import (
+	"context"
+	"crypto/tls"
 	"log"
 	"net"
 	"time"
@@ -9,7 +11,7 @@ import (
 )
 
 type memcacheOptions struct {
-	retryDuration time.Duration
+	backoffPolicy BackoffPolicy
 	bufferSize    int
 	writeLimit    int
 
@@ -17,11 +19,29 @@ type memcacheOptions struct {
 
 	healthCheckDuration time.Duration
 
+	selectionStrategy ConnSelectionStrategy
+
 	dialErrorLogger func(err error)
 
+	lazyDial bool
+
+	waitForReadyCtx context.Context
+	minReadyConns   int
+
+	connIndex         int
+	connStateCallback ConnStateCallback
+	stateNotifyCh     chan<- struct{}
+
+	traceConf       *traceConfig
+	trafficRecorder *TrafficRecorder
+
 	connOptions []netconn.Option
 }
 
+func (o *memcacheOptions) isLazyDial() bool {
+	return o.lazyDial || o.waitForReadyCtx != nil
+}
+
 func (o *memcacheOptions) addConnOption(options ...netconn.Option) {
 	o.connOptions = append(o.connOptions, options...)
 }
@@ -31,7 +51,7 @@ type Option func(opts *memcacheOptions)
 
 func computeOptions(options ...Option) *memcacheOptions {
 	opts := &memcacheOptions{
-		retryDuration: 10 * time.Second,
+		backoffPolicy: netconn.DefaultBackoffPolicy(),
 		bufferSize:    16 * 1024,
 		writeLimit:    500,
 
@@ -49,10 +69,19 @@ func computeOptions(options ...Option) *memcacheOptions {
 	return opts
 }
 
-// WithRetryDuration duration between TCP connection retry
+// BackoffPolicy computes the duration between TCP connection retries
+type BackoffPolicy = netconn.BackoffPolicy
+
+// WithRetryDuration uses a fixed duration between TCP connection retries, instead of the default exponential backoff
 func WithRetryDuration(d time.Duration) Option {
+	return WithBackoffPolicy(netconn.NewConstantBackoff(d))
+}
+
+// WithBackoffPolicy specifies the policy for computing the duration between TCP connection retries,
+// default is exponential backoff with full jitter, from 50ms up to 10 seconds
+func WithBackoffPolicy(policy BackoffPolicy) Option {
 	return func(opts *memcacheOptions) {
-		opts.retryDuration = d
+		opts.backoffPolicy = policy
 	}
 }
 
@@ -78,6 +107,21 @@ func WithTCPKeepAliveDuration(d time.Duration) Option {
 	}
 }
 
+// WithTLSConfig enables TLS for connecting to memcached (started with the -Z option).
+// Can NOT be used with WithDialFunc, New returns netconn.ErrTLSWithCustomDialFunc
+func WithTLSConfig(config *tls.Config) Option {
+	return func(opts *memcacheOptions) {
+		opts.addConnOption(netconn.WithTLSConfig(config))
+	}
+}
+
+// WithPasswordAuth authenticates each connection, after the TLS handshake if WithTLSConfig is used
+func WithPasswordAuth(auth *netconn.PasswordAuth) Option {
+	return func(opts *memcacheOptions) {
+		opts.addConnOption(netconn.WithPasswordAuth(auth))
+	}
+}
+
 // WithDialFunc ...
 func WithDialFunc(dialFunc func(network, address string, timeout time.Duration) (net.Conn, error)) Option {
 	return func(opts *memcacheOptions) {
@@ -114,3 +158,56 @@ func WithHealthCheckDuration(duration time.Duration) Option {
 		opts.healthCheckDuration = duration
 	}
 }
+
+// WithConnStateCallback sets the callback that will be called on every state transition of each connection
+func WithConnStateCallback(fn ConnStateCallback) Option {
+	return func(opts *memcacheOptions) {
+		opts.connStateCallback = fn
+	}
+}
+
+// WithConnSelectionStrategy specifies how each Pipeline chooses its connection, default is ConnSelectionRoundRobin
+func WithConnSelectionStrategy(strategy ConnSelectionStrategy) Option {
+	return func(opts *memcacheOptions) {
+		opts.selectionStrategy = strategy
+	}
+}
+
+// WithLazyDial defers dialing of each connection until it is used for the first time,
+// so New will NOT be blocked by the connect timeouts
+func WithLazyDial() Option {
+	return func(opts *memcacheOptions) {
+		opts.lazyDial = true
+	}
+}
+
+// WithWaitForReady makes New dial all connections concurrently and block until
+// at least **minConns** connections are established.
+// If ctx is done before that, New closes the client and returns an error of type *ErrNotReady.
+// When **minConns** <= 0 or > numConns, New waits for all connections.
+func WithWaitForReady(ctx context.Context, minConns int) Option {
+	return func(opts *memcacheOptions) {
+		opts.waitForReadyCtx = ctx
+		opts.minReadyConns = minConns
+	}
+}
+
+// withoutWaitForReady disables the startup only option WithWaitForReady
+func withoutWaitForReady() Option {
+	return func(opts *memcacheOptions) {
+		opts.waitForReadyCtx = nil
+		opts.minReadyConns = 0
+	}
+}
+
+func withStateNotify(ch chan<- struct{}) Option {
+	return func(opts *memcacheOptions) {
+		opts.stateNotifyCh = ch
+	}
+}
+
+func withConnIndex(index int) Option {
+	return func(opts *memcacheOptions) {
+		opts.connIndex = index
+	}
+}
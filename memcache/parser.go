@@ -53,6 +53,17 @@ type MGetResponse struct {
 	CAS   uint64
 }
 
+// MGetMetaResponse is the response of Pipeline.MGetMeta.
+// It is separated from MGetResponse for keeping the pipeline commands of MGet small
+type MGetMetaResponse struct {
+	MGetResponse
+
+	// TTL is the remaining TTL in seconds, -1 means no expiration
+	TTL int64
+
+	ClientFlags uint32
+}
+
 // MSetResponseType ...
 type MSetResponseType int
 
@@ -156,6 +167,16 @@ func findNumber(data []byte, index int) (uint64, int) {
 	return num, i // next index right after number
 }
 
+// findSignedNumber is similar to findNumber, but the number can be prefixed by '-', e.g. t-1
+func findSignedNumber(data []byte, index int) (int64, int) {
+	if index < len(data) && data[index] == '-' {
+		num, nextIndex := findNumber(data, index+1)
+		return -int64(num), nextIndex
+	}
+	num, nextIndex := findNumber(data, index)
+	return int64(num), nextIndex
+}
+
 func (p *parser) returnIfCRLF(index int, resp MGetResponse) (MGetResponse, error) {
 	nextIndex := p.findCRLF(index)
 	if nextIndex < 0 {
@@ -165,7 +186,8 @@ func (p *parser) returnIfCRLF(index int, resp MGetResponse) (MGetResponse, error
 	return resp, nil
 }
 
-func (p *parser) parseMGetFlags(index int, resp *MGetResponse) (int, error) {
+// parseMGetFlags also parses the flags t & f to **meta** if it is not nil
+func (p *parser) parseMGetFlags(index int, resp *MGetResponse, meta *MGetMetaResponse) (int, error) {
 	flags := MGetFlags(0)
 	for i := index; i < len(p.data)-1; i++ {
 		if p.data[i] == 'W' {
@@ -186,6 +208,12 @@ func (p *parser) parseMGetFlags(index int, resp *MGetResponse) (int, error) {
 			i = nextIndex - 1
 			continue
 		}
+		if meta != nil {
+			if nextIndex, ok := p.parseMGetMetaFlag(i, meta); ok {
+				i = nextIndex - 1
+				continue
+			}
+		}
 
 		if p.isCRLF(i) {
 			resp.Flags = flags
@@ -195,16 +223,32 @@ func (p *parser) parseMGetFlags(index int, resp *MGetResponse) (int, error) {
 	return 0, ErrInvalidMGet
 }
 
+// parseMGetMetaFlag returns the index right after the flag t or f at **i**, false if it is another flag
+func (p *parser) parseMGetMetaFlag(i int, meta *MGetMetaResponse) (int, bool) {
+	switch p.data[i] {
+	case 't':
+		ttl, nextIndex := findSignedNumber(p.data, i+1)
+		meta.TTL = ttl
+		return nextIndex, true
+	case 'f':
+		clientFlags, nextIndex := findNumber(p.data, i+1)
+		meta.ClientFlags = uint32(clientFlags)
+		return nextIndex, true
+	default:
+		return 0, false
+	}
+}
+
 func (p *parser) skipData(nextIndex int) {
 	p.data = p.data[nextIndex:]
 }
 
-func (p *parser) readMGetHD() (MGetResponse, error) {
+func (p *parser) readMGetHD(meta *MGetMetaResponse) (MGetResponse, error) {
 	resp := MGetResponse{
 		Type: MGetResponseTypeHD,
 	}
 
-	nextIndex, err := p.parseMGetFlags(2, &resp)
+	nextIndex, err := p.parseMGetFlags(2, &resp, meta)
 	if err != nil {
 		return MGetResponse{}, err
 	}
@@ -212,14 +256,14 @@ func (p *parser) readMGetHD() (MGetResponse, error) {
 	return resp, nil
 }
 
-func (p *parser) readMGetVA() (MGetResponse, error) {
+func (p *parser) readMGetVA(meta *MGetMetaResponse) (MGetResponse, error) {
 	_, index := findNumber(p.data, 3)
 
 	resp := MGetResponse{
 		Type: MGetResponseTypeVA,
 	}
 
-	crlfIndex, err := p.parseMGetFlags(index, &resp)
+	crlfIndex, err := p.parseMGetFlags(index, &resp, meta)
 	if err != nil {
 		return MGetResponse{}, err
 	}
@@ -280,6 +324,20 @@ func (p *parser) isErrorPrefix() errorType {
 }
 
 func (p *parser) readMGet() (MGetResponse, error) {
+	return p.readMGetWithMeta(nil)
+}
+
+func (p *parser) readMGetMeta() (MGetMetaResponse, error) {
+	var meta MGetMetaResponse
+	resp, err := p.readMGetWithMeta(&meta)
+	if err != nil {
+		return MGetMetaResponse{}, err
+	}
+	meta.MGetResponse = resp
+	return meta, nil
+}
+
+func (p *parser) readMGetWithMeta(meta *MGetMetaResponse) (MGetResponse, error) {
 	if len(p.data) < 4 {
 		return MGetResponse{}, ErrInvalidMGet
 	}
@@ -291,11 +349,11 @@ func (p *parser) readMGet() (MGetResponse, error) {
 	}
 
 	if p.prefixEqual('H', 'D') {
-		return p.readMGetHD()
+		return p.readMGetHD(meta)
 	}
 
 	if p.prefixEqual('V', 'A') {
-		return p.readMGetVA()
+		return p.readMGetVA(meta)
 	}
 
 	if errType := p.isErrorPrefix(); errType != errorTypeNone {
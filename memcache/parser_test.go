@@ -176,6 +176,84 @@ func TestParser_Read_MGet(t *testing.T) {
 	}
 }
 
+func TestParser_Read_MGet_Meta(t *testing.T) {
+	table := []struct {
+		name     string
+		data     string
+		binaries []string
+		err      error
+		resp     MGetMetaResponse
+	}{
+		{
+			name:     "VA-with-ttl",
+			data:     "VA 3 c123 t250 f0\r\n",
+			binaries: []string{"XXX"},
+			resp: MGetMetaResponse{
+				MGetResponse: MGetResponse{
+					Type: MGetResponseTypeVA,
+					Data: []byte("XXX"),
+					CAS:  123,
+				},
+				TTL: 250,
+			},
+		},
+		{
+			name:     "VA-with-no-expiration-ttl",
+			data:     "VA 3 t-1 f4294967295 c123\r\n",
+			binaries: []string{"XXX"},
+			resp: MGetMetaResponse{
+				MGetResponse: MGetResponse{
+					Type: MGetResponseTypeVA,
+					Data: []byte("XXX"),
+					CAS:  123,
+				},
+				TTL:         -1,
+				ClientFlags: 4294967295,
+			},
+		},
+		{
+			name: "HD-with-client-flags",
+			data: "HD t30 f12 W\r\n",
+			resp: MGetMetaResponse{
+				MGetResponse: MGetResponse{
+					Type:  MGetResponseTypeHD,
+					Flags: MGetFlagW,
+				},
+				TTL:         30,
+				ClientFlags: 12,
+			},
+		},
+		{
+			name: "EN",
+			data: "EN\r\n",
+			resp: MGetMetaResponse{
+				MGetResponse: MGetResponse{
+					Type: MGetResponseTypeEN,
+				},
+			},
+		},
+		{
+			name: "server-error",
+			data: "SERVER_ERROR some message\r\n",
+			err:  NewServerError("some message"),
+		},
+		{
+			name: "missing-crlf",
+			data: "HD t30 f12",
+			err:  ErrInvalidMGet,
+		},
+	}
+
+	for _, e := range table {
+		t.Run(e.name, func(t *testing.T) {
+			p := newParserStr(e.data, e.binaries...)
+			resp, err := p.readMGetMeta()
+			assert.Equal(t, e.err, err)
+			assert.Equal(t, e.resp, resp)
+		})
+	}
+}
+
 func TestParser_Read_MSet(t *testing.T) {
 	table := []struct {
 		name string
@@ -114,6 +114,10 @@ func parseCommandsForSingleCommandData(
 			cmd.getResp = resp
 			cmd.err = err
 
+		case commandTypeMGetMeta:
+			resp, err := ps.readMGetMeta()
+			cmd.resp, cmd.err = unsafe.Pointer(&resp), err
+
 		case commandTypeMSet:
 			resp, err := ps.readMSet()
 			cmd.resp, cmd.err = unsafe.Pointer(&resp), err
@@ -189,7 +193,24 @@ func (p *Pipeline) getCurrentSession() *pipelineSession {
 
 func (s *pipelineSession) pushCommands(cmd *commandListData) {
 	pipe := s.pipeline
-	pipe.conn.pushCommand(cmd)
+	for {
+		if pipe.client != nil && pipe.conn.retired.Load() {
+			pipe.conn = pipe.client.getNextConn()
+		}
+
+		closed := pipe.conn.tryPushCommand(cmd)
+		if !closed {
+			return
+		}
+
+		// the connection is removed by Client.SetNumConns right before pushing, select another one
+		if pipe.client != nil && pipe.conn.retired.Load() {
+			continue
+		}
+
+		cmd.setCompleted(ErrConnClosed)
+		return
+	}
 }
 
 func (s *pipelineSession) pushCommandsIfNotPublished() {
@@ -301,6 +322,57 @@ func ReleaseMGetResult(r MGetResult) {
 	putPipelineCmdToPool(r.ref.cmd)
 }
 
+// MTouch updates the TTL of the key using the *mg* command, without fetching the value.
+// MGetResponse.Type is MGetResponseTypeHD if the key is found, MGetResponseTypeEN otherwise
+func (p *Pipeline) MTouch(key string, ttl uint32) func() (MGetResponse, error) {
+	if err := validateKeyFormat(key); err != nil {
+		return func() (MGetResponse, error) {
+			return MGetResponse{}, err
+		}
+	}
+
+	cmdRef := p.addCommand(commandTypeMGet)
+	cmdRef.sess.builder.addMTouch(key, ttl)
+
+	return func() (MGetResponse, error) {
+		err := cmdRef.pushAndWaitIfNotRead()
+		if err != nil {
+			return MGetResponse{}, err
+		}
+
+		cmd := cmdRef.getCmd()
+		return cmd.getResp, cmd.err
+	}
+}
+
+// MGetMeta is similar to MGet, but also returns the remaining TTL & the client flags of the item,
+// using the options t & f of the *mg* command
+func (p *Pipeline) MGetMeta(key string, opts MGetOptions) func() (MGetMetaResponse, error) {
+	if err := validateKeyFormat(key); err != nil {
+		return func() (MGetMetaResponse, error) {
+			return MGetMetaResponse{}, err
+		}
+	}
+
+	cmdRef := p.addCommand(commandTypeMGetMeta)
+	cmdRef.sess.builder.addMGetMeta(key, opts)
+
+	return func() (MGetMetaResponse, error) {
+		err := cmdRef.pushAndWaitIfNotRead()
+		if err != nil {
+			return MGetMetaResponse{}, err
+		}
+
+		cmd := cmdRef.getCmd()
+
+		resp := (*MGetMetaResponse)(cmd.resp)
+		if resp == nil {
+			return MGetMetaResponse{}, cmd.err
+		}
+		return *resp, cmd.err
+	}
+}
+
 // MSet ...
 func (p *Pipeline) MSet(key string, value []byte, opts MSetOptions) func() (MSetResponse, error) {
 	if err := validateKeyFormat(key); err != nil {
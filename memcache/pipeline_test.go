@@ -215,6 +215,58 @@ func TestPipeline_MSet_Then_Mget_Special_Characters(t *testing.T) {
 	}, resp)
 }
 
+func TestPipeline_MGetMeta(t *testing.T) {
+	p := newPipelineTest(t)
+
+	_, err := p.MSet("key01", []byte("data 01"), MSetOptions{TTL: 100, ClientFlags: 23})()
+	assert.Equal(t, nil, err)
+
+	resp, err := p.MGetMeta("key01", MGetOptions{})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, MGetMetaResponse{
+		MGetResponse: MGetResponse{
+			Type: MGetResponseTypeVA,
+			Data: []byte("data 01"),
+		},
+		TTL:         100,
+		ClientFlags: 23,
+	}, resp)
+
+	resp, err = p.MGetMeta("key02", MGetOptions{})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, MGetMetaResponse{
+		MGetResponse: MGetResponse{
+			Type: MGetResponseTypeEN,
+		},
+	}, resp)
+
+	_, err = p.MGetMeta("key 03", MGetOptions{})()
+	assert.Equal(t, ErrInvalidKeyFormat, err)
+}
+
+func TestPipeline_MTouch(t *testing.T) {
+	p := newPipelineTest(t)
+
+	_, err := p.MSet("key01", []byte("data 01"), MSetOptions{TTL: 100})()
+	assert.Equal(t, nil, err)
+
+	resp, err := p.MTouch("key01", 300)()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, MGetResponse{Type: MGetResponseTypeHD}, resp)
+
+	metaResp, err := p.MGetMeta("key01", MGetOptions{})()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(300), metaResp.TTL)
+	assert.Equal(t, []byte("data 01"), metaResp.Data)
+
+	resp, err = p.MTouch("key02", 300)()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, MGetResponse{Type: MGetResponseTypeEN}, resp)
+
+	_, err = p.MTouch("key 03", 300)()
+	assert.Equal(t, ErrInvalidKeyFormat, err)
+}
+
 func TestPipeline_MSet_With_Key_Contains_Special_Characters(t *testing.T) {
 	p := newPipelineTest(t)
 
@@ -823,7 +875,7 @@ func Benchmark_Pipeline_Single_Thread(b *testing.B) {
 	}
 	defer func() { _ = c.Close() }()
 
-	connFlushAll(c.conns[0])
+	connFlushAll(c.getConns()[0])
 
 	for n := 0; n < b.N; n++ {
 		func() {
@@ -846,7 +898,7 @@ func Benchmark_Pipeline_Single_Thread_Batching_Set(b *testing.B) {
 	}
 	defer func() { _ = c.Close() }()
 
-	connFlushAll(c.conns[0])
+	connFlushAll(c.getConns()[0])
 
 	for n := 0; n < b.N; n++ {
 		func() {
@@ -0,0 +1,310 @@
+package memcache
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/QuangTung97/go-memcache/memcache/netconn"
+)
+
+type replayConfig struct {
+	speed           float64
+	dialOptions     []netconn.Option
+	mismatchHandler func(mismatch ReplayMismatch)
+}
+
+// ReplayOption ...
+type ReplayOption func(conf *replayConfig)
+
+// WithReplaySpeed keeps the recorded timing of the request batches, scaled by **speed**:
+// 1 is the original speed, 2 is twice as fast. The default 0 sends the batches as fast as possible
+func WithReplaySpeed(speed float64) ReplayOption {
+	return func(conf *replayConfig) {
+		conf.speed = speed
+	}
+}
+
+// WithReplayDialOptions sets the options for dialing to the memcached server, e.g. netconn.WithDialFunc
+func WithReplayDialOptions(options ...netconn.Option) ReplayOption {
+	return func(conf *replayConfig) {
+		conf.dialOptions = options
+	}
+}
+
+// WithReplayMismatchHandler is called when a response is different from the recorded one.
+// It can be called concurrently by the goroutines replaying different connections
+func WithReplayMismatchHandler(fn func(mismatch ReplayMismatch)) ReplayOption {
+	return func(conf *replayConfig) {
+		conf.mismatchHandler = fn
+	}
+}
+
+// ReplayMismatch is a response that is different from the recorded one
+type ReplayMismatch struct {
+	BatchID   uint64
+	ConnIndex int
+	Request   []byte
+	Expected  []byte
+	Actual    []byte
+}
+
+// ReplayStats ...
+type ReplayStats struct {
+	NumBatches    int
+	NumCommands   int
+	NumMismatches int
+
+	// Latencies of the request batches, in the order of the recorded requests
+	Latencies []time.Duration
+
+	Duration time.Duration
+}
+
+type replayBatch struct {
+	request  TrafficRecord
+	expected *TrafficRecord // nil if not having a recorded response
+
+	completedOffset time.Duration // offset of the response or error record, -1 if not recorded
+	done            chan struct{} // closed after replayed
+}
+
+func readReplayBatches(r io.Reader) ([]replayBatch, error) {
+	reader, err := NewTrafficReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var batches []replayBatch
+	batchIndex := map[uint64]int{}
+
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return batches, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch record.Type {
+		case TrafficRecordRequest:
+			batchIndex[record.BatchID] = len(batches)
+			batches = append(batches, replayBatch{
+				request:         record,
+				completedOffset: -1,
+				done:            make(chan struct{}),
+			})
+
+		default:
+			index, ok := batchIndex[record.BatchID]
+			if !ok {
+				return nil, ErrInvalidTrafficFile
+			}
+			batches[index].completedOffset = record.Offset
+			if record.Type == TrafficRecordResponse {
+				expected := record
+				batches[index].expected = &expected
+			}
+		}
+	}
+}
+
+// ReplayTraffic sends the request batches of a traffic file (recorded by TrafficRecorder) to the server at **addr**.
+// Each recorded connection is replayed by a separate TCP connection, in the recorded order.
+// A batch is sent only after the responses of all batches completed before it in the recording are received,
+// including the batches of other connections.
+//
+// Responses are compared byte by byte with the recorded ones, responses containing CAS values
+// or TTLs usually are different when replaying against another server.
+func ReplayTraffic(addr string, r io.Reader, options ...ReplayOption) (ReplayStats, error) {
+	conf := &replayConfig{}
+	for _, fn := range options {
+		fn(conf)
+	}
+
+	batches, err := readReplayBatches(r)
+	if err != nil {
+		return ReplayStats{}, err
+	}
+
+	replayer := &trafficReplayer{
+		addr:    addr,
+		conf:    conf,
+		batches: batches,
+		conns:   map[int][]int{},
+	}
+	for i, batch := range batches {
+		connIndex := batch.request.ConnIndex
+		replayer.conns[connIndex] = append(replayer.conns[connIndex], i)
+	}
+
+	stats := ReplayStats{
+		NumBatches: len(batches),
+		Latencies:  make([]time.Duration, len(batches)),
+	}
+	for _, batch := range batches {
+		stats.NumCommands += batch.request.CmdCount
+	}
+
+	replayer.start = time.Now()
+	replayer.latencies = stats.Latencies
+
+	var wg sync.WaitGroup
+	var mut sync.Mutex
+	var firstErr error
+
+	for _, indices := range replayer.conns {
+		wg.Add(1)
+		go func(indices []int) {
+			defer wg.Done()
+
+			numMismatches, err := replayer.replayConn(indices)
+
+			mut.Lock()
+			stats.NumMismatches += numMismatches
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			mut.Unlock()
+		}(indices)
+	}
+	wg.Wait()
+
+	stats.Duration = time.Since(replayer.start)
+	return stats, firstErr
+}
+
+type trafficReplayer struct {
+	addr    string
+	conf    *replayConfig
+	batches []replayBatch
+	conns   map[int][]int // conn index => indices of batches
+
+	start     time.Time
+	latencies []time.Duration
+}
+
+// waitForCompletedBefore waits for the batches of other connections that had completed
+// before **batch** was sent in the recording
+func (r *trafficReplayer) waitForCompletedBefore(batch replayBatch) {
+	for connIndex, indices := range r.conns {
+		if connIndex == batch.request.ConnIndex {
+			continue
+		}
+
+		// completed offsets are increasing in each connection, batches without responses are at the end
+		n := sort.Search(len(indices), func(i int) bool {
+			offset := r.batches[indices[i]].completedOffset
+			return offset < 0 || offset >= batch.request.Offset
+		})
+		if n > 0 {
+			<-r.batches[indices[n-1]].done
+		}
+	}
+}
+
+func (r *trafficReplayer) replayConn(indices []int) (int, error) {
+	closedCount := 0
+	defer func() {
+		// for not blocking other connections when returning early
+		for _, index := range indices[closedCount:] {
+			close(r.batches[index].done)
+		}
+	}()
+
+	nc, err := netconn.DialNewConn(r.addr, r.conf.dialOptions...)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = nc.Closer.Close() }()
+
+	conf := r.conf
+
+	conn := &replayConn{
+		nc:     nc,
+		reader: newResponseReader(),
+		buf:    make([]byte, 16*1024),
+	}
+
+	numMismatches := 0
+	for _, index := range indices {
+		batch := r.batches[index]
+
+		r.waitForCompletedBefore(batch)
+
+		if conf.speed > 0 {
+			sendTime := r.start.Add(time.Duration(float64(batch.request.Offset) / conf.speed))
+			time.Sleep(time.Until(sendTime))
+		}
+
+		sendStart := time.Now()
+		resp, err := conn.roundTrip(batch.request.Data, batch.request.CmdCount)
+		if err != nil {
+			return numMismatches, err
+		}
+		r.latencies[index] = time.Since(sendStart)
+
+		close(batch.done)
+		closedCount++
+
+		if batch.expected == nil || bytes.Equal(batch.expected.Data, resp) {
+			continue
+		}
+
+		numMismatches++
+		if conf.mismatchHandler != nil {
+			conf.mismatchHandler(ReplayMismatch{
+				BatchID:   batch.request.BatchID,
+				ConnIndex: batch.request.ConnIndex,
+				Request:   batch.request.Data,
+				Expected:  batch.expected.Data,
+				Actual:    resp,
+			})
+		}
+	}
+	return numMismatches, nil
+}
+
+// replayConn sends raw request batches and frames the responses using responseReader
+type replayConn struct {
+	nc     netconn.NetConn
+	reader *responseReader
+	buf    []byte
+}
+
+func (c *replayConn) roundTrip(request []byte, cmdCount int) ([]byte, error) {
+	if _, err := c.nc.Writer.Write(request); err != nil {
+		return nil, err
+	}
+	if err := c.nc.Writer.Flush(); err != nil {
+		return nil, err
+	}
+
+	cmd := &commandListData{cmdCount: cmdCount}
+	c.reader.setCurrentCommand(cmd)
+	defer c.reader.setCurrentCommand(nil)
+
+	for i := 0; i < cmdCount; i++ {
+		if err := c.readNextResponse(); err != nil {
+			return nil, err
+		}
+	}
+	return rawResponseBytes(cmd.responseData, cmd.responseBinaries), nil
+}
+
+func (c *replayConn) readNextResponse() error {
+	for {
+		if c.reader.readNextData() {
+			return c.reader.hasError()
+		}
+
+		n, err := c.nc.Reader.Read(c.buf)
+		if err != nil {
+			return err
+		}
+		c.reader.recv(c.buf[:n])
+	}
+}
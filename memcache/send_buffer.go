@@ -2,6 +2,7 @@ package memcache
 
 import (
 	"sync"
+	"sync/atomic"
 )
 
 // sendBuffer is a singly-linked list of commandListData.
@@ -12,6 +13,8 @@ type sendBuffer struct {
 	closed     bool
 	mut        sync.Mutex
 	cond       *sync.Cond
+
+	pushedCount atomic.Uint64 // number of commands pushed, including the siblings
 }
 
 func initSendBuffer(b *sendBuffer) {
@@ -34,6 +37,12 @@ func (b *sendBuffer) push(cmd *commandListData) (closed bool) {
 
 	needSignal := b.firstCmd == nil
 
+	var count uint64
+	for c := cmd; c != nil; c = c.sibling {
+		count += uint64(c.cmdCount)
+	}
+	b.pushedCount.Add(count)
+
 	cmd.link = nil
 	*b.nextCmdPtr = cmd
 	b.nextCmdPtr = &cmd.link
@@ -150,3 +150,22 @@ func TestSendBuffer(t *testing.T) {
 		assert.Equal(t, true, popClosed)
 	})
 }
+
+func TestSendBuffer_Pushed_Count(t *testing.T) {
+	b := newSendBuffer()
+
+	cmd := newCommandFromString("mg key01")
+	cmd.sibling = newCommandFromString("mg key02")
+	cmd.sibling.cmdCount = 3
+
+	b.push(cmd)
+	b.push(newCommandFromString("mg key03"))
+	assert.Equal(t, uint64(5), b.pushedCount.Load())
+
+	b.popAll(true)
+	assert.Equal(t, uint64(5), b.pushedCount.Load())
+
+	b.close()
+	b.push(newCommandFromString("mg key04"))
+	assert.Equal(t, uint64(5), b.pushedCount.Load())
+}
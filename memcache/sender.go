@@ -26,6 +26,8 @@ type sender struct {
 	closed      bool
 	// ---- end connMut protection ----
 
+	hooks senderHooks
+
 	finishCh chan struct{}
 	sendBuf  sendBuffer
 	selector inputSelector
@@ -198,7 +200,19 @@ func (b *recvBuffer) closeBuffer() {
 }
 
 func newSender(nc netconn.NetConn, bufSizeLog int, writeLimit int) *sender {
-	s := &sender{}
+	return newSenderWithHooks(nc, bufSizeLog, writeLimit, senderHooks{})
+}
+
+// senderHooks observes the request & response batches, nil fields mean disabled
+type senderHooks struct {
+	tracer   *protocolTracer
+	recorder *connTrafficRecorder
+}
+
+func newSenderWithHooks(nc netconn.NetConn, bufSizeLog int, writeLimit int, hooks senderHooks) *sender {
+	s := &sender{
+		hooks: hooks,
+	}
 	s.conn = newSenderConn(s, nc)
 	s.ncErrorCond = sync.NewCond(&s.connMut)
 
@@ -227,6 +241,9 @@ func (s *sender) writeAndFlush() {
 	}
 
 	for _, cmd := range s.tmpBuf {
+		if cmd.traced {
+			s.hooks.tracer.traceRequest(cmd)
+		}
 		if err := cmd.writeToWriter(s.conn.writer); err != nil {
 			_ = s.conn.setLastErrorAndCloseUnsafe(err)
 			return
@@ -247,6 +264,8 @@ func (s *sender) sendToWriter() (closed bool) {
 
 	for _, cmd := range s.tmpBuf {
 		cmd.conn = s.conn
+		cmd.traced = s.hooks.tracer.sample()
+		cmd.recordID = s.hooks.recorder.recordRequest(cmd)
 	}
 
 	s.recv.push(s.tmpBuf)
@@ -287,14 +306,22 @@ func (s *sender) readSentCommands(cmdList []*commandListData) int {
 
 func (s *sender) waitForError() (closed bool) {
 	s.connMut.Lock()
-	result := s.closed
 	for !s.closed && s.conn.getLastErrorInternal() == nil {
 		s.ncErrorCond.Wait()
 	}
+	result := s.closed
 	s.connMut.Unlock()
 	return result
 }
 
+// lastError returns the error that broke the current connection, nil if the connection is still healthy
+func (s *sender) lastError() error {
+	s.connMut.Lock()
+	err := s.conn.getLastErrorInternal()
+	s.connMut.Unlock()
+	return err
+}
+
 func (s *sender) resetNetConn(nc netconn.NetConn) {
 	s.connMut.Lock()
 	if s.closed {
@@ -0,0 +1,122 @@
+package snapshot
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/QuangTung97/go-memcache/memcache"
+	"github.com/QuangTung97/go-memcache/memcache/stats"
+)
+
+// ExportResult is the summary of an export
+type ExportResult struct {
+	Dumped  uint64 // number of keys returned by the metadump command
+	Written uint64 // number of entries written to the snapshot file
+	Missed  uint64 // number of keys deleted, expired or evicted before their values were fetched
+}
+
+type exporter struct {
+	conf      config
+	client    *memcache.Client
+	writer    *Writer
+	createdAt time.Time
+
+	keys   []string
+	result ExportResult
+}
+
+// Export iterates the keys of a server via **dumper** (with the metadump command), fetches their values,
+// client flags and remaining TTLs in batches using **client** and writes them to **w** in the snapshot format.
+// Both clients must connect to the same server.
+//
+// Items are NOT a consistent point-in-time view of the server, they can be changed while exporting
+func Export(
+	ctx context.Context, dumper *stats.Client, client *memcache.Client, w io.Writer, options ...Option,
+) (ExportResult, error) {
+	conf := computeConfig(options...)
+	createdAt := time.Unix(conf.nowFunc().Unix(), 0)
+
+	e := &exporter{
+		conf:      conf,
+		client:    client,
+		writer:    NewWriter(w, createdAt),
+		createdAt: createdAt,
+		keys:      make([]string, 0, conf.batchSize),
+	}
+
+	var fetchErr error
+	err := dumper.MetaDump(ctx, stats.MetaDumpOptions{}, func(key stats.MetaDumpKey) bool {
+		e.result.Dumped++
+		e.keys = append(e.keys, key.Key)
+		if len(e.keys) < conf.batchSize {
+			return true
+		}
+		fetchErr = e.fetchKeys()
+		return fetchErr == nil
+	})
+	if fetchErr != nil {
+		return e.result, fetchErr
+	}
+	if err != nil {
+		return e.result, err
+	}
+
+	if err := e.fetchKeys(); err != nil {
+		return e.result, err
+	}
+	return e.result, e.writer.Close()
+}
+
+// readAfter returns the seconds since the snapshot created that the items are read at **now**
+func (e *exporter) readAfter(now time.Time) uint32 {
+	return uint32(max(now.Sub(e.createdAt)/time.Second, 0))
+}
+
+// entryTTL converts the remaining TTL of mg command, the items about to expire keep a TTL of one second
+func entryTTL(ttl int64) uint32 {
+	if ttl < 0 {
+		return 0
+	}
+	return uint32(max(ttl, 1))
+}
+
+func (e *exporter) fetchKeys() error {
+	if len(e.keys) == 0 {
+		return nil
+	}
+
+	p := e.client.Pipeline()
+	defer p.Finish()
+
+	fns := make([]func() (memcache.MGetMetaResponse, error), 0, len(e.keys))
+	for _, key := range e.keys {
+		fns = append(fns, p.MGetMeta(key, memcache.MGetOptions{}))
+	}
+
+	for i, fn := range fns {
+		resp, err := fn()
+		if err != nil {
+			return err
+		}
+		if resp.Type != memcache.MGetResponseTypeVA {
+			e.result.Missed++
+			continue
+		}
+
+		err = e.writer.Write(Entry{
+			Key:         e.keys[i],
+			Value:       resp.Data,
+			ClientFlags: resp.ClientFlags,
+			ReadAfter:   e.readAfter(e.conf.nowFunc()),
+			TTL:         entryTTL(resp.TTL),
+		})
+		if err != nil {
+			return err
+		}
+		e.result.Written++
+	}
+
+	e.keys = e.keys[:0]
+	return nil
+}
@@ -0,0 +1,152 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/QuangTung97/go-memcache/memcache"
+	"github.com/QuangTung97/go-memcache/memcache/memcachetest"
+	"github.com/QuangTung97/go-memcache/memcache/stats"
+)
+
+type fakeClock struct {
+	mut sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+type serverTest struct {
+	server *memcachetest.Server
+	client *memcache.Client
+	dumper *stats.Client
+}
+
+func newServerTest(t *testing.T, clock *fakeClock) *serverTest {
+	s, err := memcachetest.NewServer(memcachetest.WithClock(clock.Now))
+	if err != nil {
+		panic(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	client, err := memcache.New(s.Addr(), 1)
+	if err != nil {
+		panic(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	dumper := stats.New(s.Addr())
+	t.Cleanup(func() { _ = dumper.Close() })
+
+	return &serverTest{
+		server: s,
+		client: client,
+		dumper: dumper,
+	}
+}
+
+func (s *serverTest) set(key string, value string, ttl uint32) {
+	s.setWithFlags(key, value, ttl, 0)
+}
+
+func (s *serverTest) setWithFlags(key string, value string, ttl uint32, clientFlags uint32) {
+	p := s.client.Pipeline()
+	defer p.Finish()
+
+	_, err := p.MSet(key, []byte(value), memcache.MSetOptions{TTL: ttl, ClientFlags: clientFlags})()
+	if err != nil {
+		panic(err)
+	}
+}
+
+func withClock(clock *fakeClock) Option {
+	return func(conf *config) {
+		conf.nowFunc = clock.Now
+	}
+}
+
+func TestExport(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		clock := newFakeClock()
+		s := newServerTest(t, clock)
+
+		s.set("key01", "value01", 0)
+		s.setWithFlags("key02", "value02", 100, 12)
+		s.set("key03", "value03", 3600)
+
+		var buf bytes.Buffer
+		result, err := Export(context.Background(), s.dumper, s.client, &buf, WithBatchSize(2), withClock(clock))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, ExportResult{Dumped: 3, Written: 3}, result)
+
+		r, err := NewReader(&buf)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, clock.Now().Unix(), r.CreatedAt().Unix())
+
+		entries, err := readAllEntries(r)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []Entry{
+			{Key: "key01", Value: []byte("value01")},
+			{Key: "key02", Value: []byte("value02"), ClientFlags: 12, TTL: 100},
+			{Key: "key03", Value: []byte("value03"), TTL: 3600},
+		}, entries)
+	})
+
+	t.Run("ttl is relative to read time", func(t *testing.T) {
+		clock := newFakeClock()
+
+		e := &exporter{createdAt: clock.Now()}
+		assert.Equal(t, uint32(0), e.readAfter(clock.Now()))
+		assert.Equal(t, uint32(20), e.readAfter(clock.Now().Add(20*time.Second+500*time.Millisecond)))
+
+		assert.Equal(t, uint32(0), entryTTL(-1))
+		assert.Equal(t, uint32(100), entryTTL(100))
+		assert.Equal(t, uint32(1), entryTTL(0))
+	})
+
+	t.Run("empty server", func(t *testing.T) {
+		s := newServerTest(t, newFakeClock())
+
+		var buf bytes.Buffer
+		result, err := Export(context.Background(), s.dumper, s.client, &buf)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, ExportResult{}, result)
+
+		r, err := NewReader(&buf)
+		assert.Equal(t, nil, err)
+		entries, err := readAllEntries(r)
+		assert.Equal(t, nil, err)
+		assert.Nil(t, entries)
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		s := newServerTest(t, newFakeClock())
+		s.set("key01", "value01", 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var buf bytes.Buffer
+		_, err := Export(ctx, s.dumper, s.client, &buf)
+		assert.Equal(t, context.Canceled, err)
+	})
+}
@@ -0,0 +1,53 @@
+package snapshot
+
+import (
+	"time"
+)
+
+// Option is an option of Export & Restore
+type Option func(conf *config)
+
+type config struct {
+	batchSize     int
+	rateLimit     int
+	ignoreElapsed bool
+	nowFunc       func() time.Time
+}
+
+func computeConfig(options ...Option) config {
+	conf := config{
+		batchSize: 100,
+		nowFunc:   time.Now,
+	}
+	for _, fn := range options {
+		fn(&conf)
+	}
+	return conf
+}
+
+// WithBatchSize specifies the number of commands in each pipeline, default is 100
+func WithBatchSize(size int) Option {
+	return func(conf *config) {
+		if size > 0 {
+			conf.batchSize = size
+		}
+	}
+}
+
+// WithRateLimit limits the number of items restored per second, zero means unlimited. Only for Restore
+func WithRateLimit(itemsPerSecond int) Option {
+	return func(conf *config) {
+		conf.rateLimit = itemsPerSecond
+	}
+}
+
+// WithIgnoreElapsed restores the TTLs as they were when each item was read by the export,
+// such that the duration of the export is NOT counted either.
+// By default, the time elapsed since the items were read is subtracted from the TTLs,
+// and the expired items are skipped.
+// Only for Restore
+func WithIgnoreElapsed() Option {
+	return func(conf *config) {
+		conf.ignoreElapsed = true
+	}
+}
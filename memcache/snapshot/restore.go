@@ -0,0 +1,166 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/QuangTung97/go-memcache/memcache"
+)
+
+// RestoreResult is the summary of a restore
+type RestoreResult struct {
+	Read      uint64 // number of entries read from the snapshot file
+	Stored    uint64
+	Expired   uint64 // number of entries skipped because they had expired
+	NotStored uint64 // number of entries NOT stored by the server, e.g. too large for the server
+}
+
+// rateLimiter paces the batches so that on average at most **rate** items are issued per second
+type rateLimiter struct {
+	rate   int
+	start  time.Time
+	issued uint64
+}
+
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	if l.rate <= 0 {
+		return nil
+	}
+	if l.issued == 0 {
+		l.start = time.Now()
+	}
+
+	target := l.start.Add(time.Duration(l.issued) * time.Second / time.Duration(l.rate))
+	l.issued += uint64(n)
+
+	d := time.Until(target)
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type restorer struct {
+	conf      config
+	client    *memcache.Client
+	createdAt time.Time
+	limiter   rateLimiter
+
+	entries []Entry
+	result  RestoreResult
+}
+
+// Restore reads the snapshot file from **r** and sets the items to the server of **client** in batches.
+// The TTLs are relative to the time each item was read by the export, see WithIgnoreElapsed.
+// Existing items of the server with the same keys are overridden
+func Restore(ctx context.Context, client *memcache.Client, r io.Reader, options ...Option) (RestoreResult, error) {
+	conf := computeConfig(options...)
+
+	reader, err := NewReader(r)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+
+	s := &restorer{
+		conf:      conf,
+		client:    client,
+		createdAt: reader.CreatedAt(),
+		limiter:   rateLimiter{rate: conf.rateLimit},
+		entries:   make([]Entry, 0, conf.batchSize),
+	}
+
+	for {
+		entry, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return s.result, err
+		}
+
+		s.result.Read++
+		s.entries = append(s.entries, entry)
+		if len(s.entries) < conf.batchSize {
+			continue
+		}
+		if err := s.storeEntries(ctx); err != nil {
+			return s.result, err
+		}
+	}
+
+	return s.result, s.storeEntries(ctx)
+}
+
+// computeTTL returns false if the entry has expired at **now**
+func (s *restorer) computeTTL(now time.Time, e Entry) (uint32, bool) {
+	if e.TTL == 0 {
+		return 0, true
+	}
+
+	ttl := int64(e.TTL)
+	if !s.conf.ignoreElapsed {
+		ttl = int64(e.ExpireAfter()) - int64(now.Sub(s.createdAt)/time.Second)
+	}
+	if ttl <= 0 {
+		return 0, false
+	}
+
+	if ttl > memcache.MaxRelativeTTL {
+		ttl += now.Unix()
+	}
+	return uint32(ttl), true
+}
+
+func (s *restorer) storeEntries(ctx context.Context) error {
+	if len(s.entries) == 0 {
+		return nil
+	}
+	defer func() { s.entries = s.entries[:0] }()
+
+	if err := s.limiter.wait(ctx, len(s.entries)); err != nil {
+		return err
+	}
+
+	p := s.client.Pipeline()
+	defer p.Finish()
+
+	now := s.conf.nowFunc()
+
+	fns := make([]func() (memcache.MSetResponse, error), 0, len(s.entries))
+	for _, e := range s.entries {
+		ttl, ok := s.computeTTL(now, e)
+		if !ok {
+			s.result.Expired++
+			continue
+		}
+		fns = append(fns, p.MSet(e.Key, e.Value, memcache.MSetOptions{TTL: ttl, ClientFlags: e.ClientFlags}))
+	}
+
+	for _, fn := range fns {
+		resp, err := fn()
+		if memcache.IsObjectTooBig(err) {
+			s.result.NotStored++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if resp.Type == memcache.MSetResponseTypeHD {
+			s.result.Stored++
+		} else {
+			s.result.NotStored++
+		}
+	}
+	return nil
+}
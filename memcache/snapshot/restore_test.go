@@ -0,0 +1,180 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/QuangTung97/go-memcache/memcache"
+)
+
+func (s *serverTest) get(key string) memcache.MGetMetaResponse {
+	p := s.client.Pipeline()
+	defer p.Finish()
+
+	resp, err := p.MGetMeta(key, memcache.MGetOptions{})()
+	if err != nil {
+		panic(err)
+	}
+	return resp
+}
+
+func newSnapshotTest(createdAt time.Time, entries ...Entry) *bytes.Buffer {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, createdAt)
+	for _, e := range entries {
+		if err := w.Write(e); err != nil {
+			panic(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+	return &buf
+}
+
+func TestRestore(t *testing.T) {
+	t.Run("export then restore", func(t *testing.T) {
+		clock := newFakeClock()
+		source := newServerTest(t, clock)
+		dest := newServerTest(t, clock)
+
+		source.setWithFlags("key01", "value01", 0, 7)
+		source.set("key02", "value02", 100)
+		source.set("key03", "value03", 20)
+
+		var buf bytes.Buffer
+		_, err := Export(context.Background(), source.dumper, source.client, &buf, withClock(clock))
+		assert.Equal(t, nil, err)
+
+		clock.Add(30 * time.Second)
+
+		result, err := Restore(context.Background(), dest.client, &buf, WithBatchSize(2), withClock(clock))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, RestoreResult{Read: 3, Stored: 2, Expired: 1}, result)
+
+		assert.Equal(t, memcache.MGetMetaResponse{
+			MGetResponse: memcache.MGetResponse{
+				Type: memcache.MGetResponseTypeVA,
+				Data: []byte("value01"),
+			},
+			TTL:         -1,
+			ClientFlags: 7,
+		}, dest.get("key01"))
+
+		assert.Equal(t, memcache.MGetMetaResponse{
+			MGetResponse: memcache.MGetResponse{
+				Type: memcache.MGetResponseTypeVA,
+				Data: []byte("value02"),
+			},
+			TTL: 70,
+		}, dest.get("key02"))
+
+		assert.Equal(t, memcache.MGetResponseTypeEN, dest.get("key03").Type)
+	})
+
+	t.Run("ttl relative to read time", func(t *testing.T) {
+		clock := newFakeClock()
+		dest := newServerTest(t, clock)
+
+		buf := newSnapshotTest(clock.Now(), Entry{Key: "key01", Value: []byte("value01"), ReadAfter: 10, TTL: 30})
+		clock.Add(30 * time.Second)
+
+		result, err := Restore(context.Background(), dest.client, buf, withClock(clock))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, RestoreResult{Read: 1, Stored: 1}, result)
+		assert.Equal(t, int64(10), dest.get("key01").TTL)
+	})
+
+	t.Run("ignore elapsed", func(t *testing.T) {
+		clock := newFakeClock()
+		dest := newServerTest(t, clock)
+
+		buf := newSnapshotTest(clock.Now(), Entry{Key: "key01", Value: []byte("value01"), ReadAfter: 10, TTL: 20})
+		clock.Add(30 * time.Second)
+
+		result, err := Restore(context.Background(), dest.client, buf, WithIgnoreElapsed(), withClock(clock))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, RestoreResult{Read: 1, Stored: 1}, result)
+		assert.Equal(t, int64(20), dest.get("key01").TTL)
+	})
+
+	t.Run("ttl longer than 30 days", func(t *testing.T) {
+		clock := newFakeClock()
+		dest := newServerTest(t, clock)
+
+		const ttl = 60 * 24 * 3600
+		buf := newSnapshotTest(clock.Now(), Entry{Key: "key01", Value: []byte("value01"), TTL: ttl + 10})
+		clock.Add(10 * time.Second)
+
+		result, err := Restore(context.Background(), dest.client, buf, withClock(clock))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, RestoreResult{Read: 1, Stored: 1}, result)
+		assert.Equal(t, int64(ttl), dest.get("key01").TTL)
+	})
+
+	t.Run("not stored", func(t *testing.T) {
+		clock := newFakeClock()
+		dest := newServerTest(t, clock)
+
+		buf := newSnapshotTest(clock.Now(),
+			Entry{Key: "key01", Value: bytes.Repeat([]byte("A"), 2*1024*1024)},
+			Entry{Key: "key02", Value: []byte("value02")},
+		)
+
+		result, err := Restore(context.Background(), dest.client, buf, withClock(clock))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, RestoreResult{Read: 2, Stored: 1, NotStored: 1}, result)
+		assert.Equal(t, memcache.MGetResponseTypeEN, dest.get("key01").Type)
+		assert.Equal(t, []byte("value02"), dest.get("key02").Data)
+	})
+
+	t.Run("invalid snapshot", func(t *testing.T) {
+		dest := newServerTest(t, newFakeClock())
+
+		_, err := Restore(context.Background(), dest.client, strings.NewReader("invalid\n"))
+		assert.Equal(t, ErrInvalidSnapshot, err)
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		clock := newFakeClock()
+		dest := newServerTest(t, clock)
+
+		var entries []Entry
+		for i := 0; i < 30; i++ {
+			entries = append(entries, Entry{Key: "key" + string(rune('A'+i)), Value: []byte("value")})
+		}
+		buf := newSnapshotTest(clock.Now(), entries...)
+
+		start := time.Now()
+		result, err := Restore(context.Background(), dest.client, buf,
+			WithBatchSize(10), WithRateLimit(200), withClock(clock),
+		)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, RestoreResult{Read: 30, Stored: 30}, result)
+
+		// the third batch waits until 20 items / 200 per second
+		assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("rate limit with context cancelled", func(t *testing.T) {
+		clock := newFakeClock()
+		dest := newServerTest(t, clock)
+
+		buf := newSnapshotTest(clock.Now(),
+			Entry{Key: "key01", Value: []byte("value01")},
+			Entry{Key: "key02", Value: []byte("value02")},
+		)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		result, err := Restore(ctx, dest.client, buf, WithBatchSize(1), WithRateLimit(1), withClock(clock))
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, RestoreResult{Read: 2, Stored: 1}, result)
+	})
+}
@@ -0,0 +1,264 @@
+// Package snapshot exports the items of a memcached server to a snapshot file,
+// and restores a snapshot file into another server for warming up its cache.
+package snapshot
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	snapshotMagic   = "memcache-snapshot"
+	snapshotVersion = 2
+)
+
+// maxValueSize is the max item size of memcached (the -I option)
+const maxValueSize = 1 << 30
+
+// ErrInvalidSnapshot is returned when reading a snapshot file with bad format, including a truncated file
+var ErrInvalidSnapshot = errors.New("snapshot: invalid snapshot file")
+
+// ErrUnsupportedVersion is returned when reading a snapshot file of another version
+var ErrUnsupportedVersion = errors.New("snapshot: unsupported snapshot version")
+
+// Entry is a single item of a snapshot file.
+//
+// A snapshot file starts with the header line:
+//
+//	memcache-snapshot v2 <created at>\n
+//
+// followed by a sequence of entries, each entry is a header line and the value:
+//
+//	<key> <client flags> <read after> <ttl> <value length>\n<value>\n
+//
+// and ends with the line:
+//
+//	end <number of entries>\n
+//
+// in which:
+//   - created at: unix timestamp in seconds when the export started
+//   - read after: seconds since the created at timestamp that the item was read
+//   - ttl: remaining TTL in seconds when the item was read, zero means no expiration
+//
+// All numbers are in decimal. A file without the end line is considered truncated.
+type Entry struct {
+	Key   string
+	Value []byte
+
+	ClientFlags uint32
+
+	ReadAfter uint32
+	TTL       uint32
+}
+
+// ExpireAfter returns the seconds since the created at timestamp that the item expires, zero means no expiration
+func (e Entry) ExpireAfter() uint32 {
+	if e.TTL == 0 {
+		return 0
+	}
+	return e.ReadAfter + e.TTL
+}
+
+// Writer writes entries to a snapshot file
+type Writer struct {
+	writer     *bufio.Writer
+	numEntries uint64
+	err        error
+}
+
+// NewWriter writes the header of the snapshot file with **createdAt**
+func NewWriter(w io.Writer, createdAt time.Time) *Writer {
+	sw := &Writer{
+		writer: bufio.NewWriter(w),
+	}
+
+	buf := append([]byte(snapshotMagic), " v"...)
+	buf = strconv.AppendInt(buf, snapshotVersion, 10)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, createdAt.Unix(), 10)
+	buf = append(buf, '\n')
+
+	_, sw.err = sw.writer.Write(buf)
+	return sw
+}
+
+func appendEntryHeader(buf []byte, e Entry) []byte {
+	buf = append(buf, e.Key...)
+	for _, n := range []uint32{e.ClientFlags, e.ReadAfter, e.TTL} {
+		buf = append(buf, ' ')
+		buf = strconv.AppendUint(buf, uint64(n), 10)
+	}
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(len(e.Value)), 10)
+	buf = append(buf, '\n')
+	return buf
+}
+
+// Write writes an entry, the key must NOT contain spaces or new lines, as memcached keys
+func (w *Writer) Write(e Entry) error {
+	if w.err != nil {
+		return w.err
+	}
+
+	if _, w.err = w.writer.Write(appendEntryHeader(nil, e)); w.err != nil {
+		return w.err
+	}
+	if _, w.err = w.writer.Write(e.Value); w.err != nil {
+		return w.err
+	}
+	if w.err = w.writer.WriteByte('\n'); w.err != nil {
+		return w.err
+	}
+
+	w.numEntries++
+	return nil
+}
+
+// Close writes the end line and flushes the buffered data, it does NOT close the underlying writer
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+
+	buf := append([]byte("end "), strconv.FormatUint(w.numEntries, 10)...)
+	buf = append(buf, '\n')
+	if _, w.err = w.writer.Write(buf); w.err != nil {
+		return w.err
+	}
+	w.err = w.writer.Flush()
+	return w.err
+}
+
+// Reader reads entries of a snapshot file
+type Reader struct {
+	reader    *bufio.Reader
+	createdAt time.Time
+
+	numEntries uint64
+	finished   bool
+}
+
+func parseSnapshotHeader(line string) (time.Time, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 || fields[0] != snapshotMagic || !strings.HasPrefix(fields[1], "v") {
+		return time.Time{}, ErrInvalidSnapshot
+	}
+
+	version, err := strconv.ParseUint(fields[1][1:], 10, 32)
+	if err != nil {
+		return time.Time{}, ErrInvalidSnapshot
+	}
+	if version != snapshotVersion {
+		return time.Time{}, ErrUnsupportedVersion
+	}
+
+	createdAt, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return time.Time{}, ErrInvalidSnapshot
+	}
+	return time.Unix(createdAt, 0), nil
+}
+
+// NewReader checks the header of the snapshot file
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := bufio.NewReader(r)
+
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrInvalidSnapshot
+		}
+		return nil, err
+	}
+
+	createdAt, err := parseSnapshotHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reader{
+		reader:    reader,
+		createdAt: createdAt,
+	}, nil
+}
+
+// CreatedAt returns the time the export started, with seconds precision
+func (r *Reader) CreatedAt() time.Time {
+	return r.createdAt
+}
+
+func (r *Reader) checkEnd(fields []string) error {
+	numEntries, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil || numEntries != r.numEntries {
+		return ErrInvalidSnapshot
+	}
+	r.finished = true
+	return io.EOF
+}
+
+func parseEntryHeader(fields []string) (Entry, int, error) {
+	var nums [3]uint32
+	for i := range nums {
+		n, err := strconv.ParseUint(fields[i+1], 10, 32)
+		if err != nil {
+			return Entry{}, 0, ErrInvalidSnapshot
+		}
+		nums[i] = uint32(n)
+	}
+
+	valueLen, err := strconv.ParseUint(fields[4], 10, 32)
+	if err != nil || valueLen > maxValueSize {
+		return Entry{}, 0, ErrInvalidSnapshot
+	}
+
+	return Entry{
+		Key:         fields[0],
+		ClientFlags: nums[0],
+		ReadAfter:   nums[1],
+		TTL:         nums[2],
+	}, int(valueLen), nil
+}
+
+// Next returns the next entry, io.EOF after the end line
+func (r *Reader) Next() (Entry, error) {
+	if r.finished {
+		return Entry{}, io.EOF
+	}
+
+	line, err := r.reader.ReadString('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return Entry{}, ErrInvalidSnapshot
+		}
+		return Entry{}, err
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 2 && fields[0] == "end" {
+		return Entry{}, r.checkEnd(fields)
+	}
+	if len(fields) != 5 {
+		return Entry{}, ErrInvalidSnapshot
+	}
+
+	entry, valueLen, err := parseEntryHeader(fields)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	data := make([]byte, valueLen+1)
+	if _, err := io.ReadFull(r.reader, data); err != nil {
+		return Entry{}, ErrInvalidSnapshot
+	}
+	if data[valueLen] != '\n' {
+		return Entry{}, ErrInvalidSnapshot
+	}
+
+	entry.Value = data[:valueLen]
+	r.numEntries++
+	return entry, nil
+}
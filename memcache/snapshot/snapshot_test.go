@@ -0,0 +1,119 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readAllEntries(r *Reader) ([]Entry, error) {
+	var entries []Entry
+	for {
+		e, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, e)
+	}
+}
+
+func TestWriter_Reader(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, time.Unix(1700000000, 500))
+
+		assert.Equal(t, nil, w.Write(Entry{Key: "key01", Value: []byte("value01"), ReadAfter: 5, TTL: 30}))
+		assert.Equal(t, nil, w.Write(Entry{Key: "key02", Value: []byte("line1\nline2\r\n"), ClientFlags: 41}))
+		assert.Equal(t, nil, w.Write(Entry{Key: "key03", Value: []byte{}}))
+		assert.Equal(t, nil, w.Close())
+
+		assert.Equal(t, "memcache-snapshot v2 1700000000\n"+
+			"key01 0 5 30 7\nvalue01\n"+
+			"key02 41 0 0 13\nline1\nline2\r\n\n"+
+			"key03 0 0 0 0\n\n"+
+			"end 3\n", buf.String())
+
+		r, err := NewReader(&buf)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, time.Unix(1700000000, 0), r.CreatedAt())
+
+		entries, err := readAllEntries(r)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []Entry{
+			{Key: "key01", Value: []byte("value01"), ReadAfter: 5, TTL: 30},
+			{Key: "key02", Value: []byte("line1\nline2\r\n"), ClientFlags: 41},
+			{Key: "key03", Value: []byte{}},
+		}, entries)
+
+		_, err = r.Next()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("expire after", func(t *testing.T) {
+		assert.Equal(t, uint32(0), Entry{ReadAfter: 5}.ExpireAfter())
+		assert.Equal(t, uint32(35), Entry{ReadAfter: 5, TTL: 30}.ExpireAfter())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, time.Unix(1700000000, 0))
+		assert.Equal(t, nil, w.Close())
+
+		r, err := NewReader(&buf)
+		assert.Equal(t, nil, err)
+
+		entries, err := readAllEntries(r)
+		assert.Equal(t, nil, err)
+		assert.Nil(t, entries)
+	})
+}
+
+func TestReader_Errors(t *testing.T) {
+	const header = "memcache-snapshot v2 1700000000\n"
+
+	t.Run("invalid header", func(t *testing.T) {
+		for _, input := range []string{"", "memcache-snapshot", "memcache-traffic v1\n", "memcache-snapshot v2 abc\n"} {
+			_, err := NewReader(strings.NewReader(input))
+			assert.Equal(t, ErrInvalidSnapshot, err, input)
+		}
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		for _, input := range []string{"memcache-snapshot v1 1700000000\n", "memcache-snapshot v3 1700000000\n"} {
+			_, err := NewReader(strings.NewReader(input))
+			assert.Equal(t, ErrUnsupportedVersion, err, input)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "truncated without end", input: header + "key01 0 0 0 2\nAB\n"},
+		{name: "truncated value", input: header + "key01 0 0 0 20\nAB\n"},
+		{name: "value not end with new line", input: header + "key01 0 0 0 2\nABC\nend 1\n"},
+		{name: "invalid client flags", input: header + "key01 4294967296 0 0 2\nAB\nend 1\n"},
+		{name: "invalid read after", input: header + "key01 0 x 0 2\nAB\nend 1\n"},
+		{name: "invalid ttl", input: header + "key01 0 0 -1 2\nAB\nend 1\n"},
+		{name: "too large value", input: header + "key01 0 0 0 2000000000\nAB\nend 1\n"},
+		{name: "invalid entry header", input: header + "key01 0 2\nAB\nend 1\n"},
+		{name: "mismatched count", input: header + "key01 0 0 0 2\nAB\nend 2\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewReader(strings.NewReader(tc.input))
+			assert.Equal(t, nil, err)
+
+			_, err = readAllEntries(r)
+			assert.Equal(t, ErrInvalidSnapshot, err)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package stats
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AdminClient sends administrative commands to a memcached server.
+// Same as Client, it is safe for concurrent use and reconnects lazily when the connection is broken
+type AdminClient struct {
+	client *Client
+}
+
+// NewAdminClient creates an admin client, accepting the same options as New
+func NewAdminClient(addr string, options ...Option) *AdminClient {
+	return &AdminClient{
+		client: New(addr, options...),
+	}
+}
+
+// ReplyError is returned when the server does NOT reply the expected success reply,
+// e.g. "ERROR", "CLIENT_ERROR bad command line format" or "BUSY currently processing reassign request"
+type ReplyError struct {
+	Command string // the command line without CRLF
+	Kind    string // the first word of the reply, e.g. ERROR, CLIENT_ERROR, SERVER_ERROR, BUSY, BADCLASS
+	Message string // the remaining of the reply, can be empty
+}
+
+func (e *ReplyError) Error() string {
+	reply := e.Kind
+	if e.Message != "" {
+		reply += " " + e.Message
+	}
+	return "stats: command '" + e.Command + "' failed with reply: " + reply
+}
+
+func newReplyError(cmd string, line string) *ReplyError {
+	kind, msg, _ := strings.Cut(line, " ")
+	return &ReplyError{
+		Command: strings.TrimSuffix(cmd, "\r\n"),
+		Kind:    strings.TrimSuffix(kind, ":"),
+		Message: msg,
+	}
+}
+
+func (c *Client) readReplyLineUnsafe() (string, error) {
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	c.keepConn = true
+	return c.scanner.Text(), nil
+}
+
+func (c *AdminClient) runCommand(cmd string, successReply string) error {
+	return c.client.do(func() error {
+		if err := c.client.writeCommand(cmd); err != nil {
+			return err
+		}
+
+		line, err := c.client.readReplyLineUnsafe()
+		if err != nil {
+			return err
+		}
+
+		if line != successReply {
+			return newReplyError(cmd, line)
+		}
+		return nil
+	})
+}
+
+// Verbosity sets the logging level of the server
+func (c *AdminClient) Verbosity(level int) error {
+	return c.runCommand("verbosity "+strconv.Itoa(level)+"\r\n", "OK")
+}
+
+// AnySlabClass is used as the source of SlabsReassign for picking a page from any slab class
+const AnySlabClass = -1
+
+// SlabsReassign moves a memory page from the slab class **source** to **dest**.
+// The server can reply BUSY, BADCLASS, NOSPARE, NOTFULL or UNSAFE, returned as ReplyError
+func (c *AdminClient) SlabsReassign(source int, dest int) error {
+	cmd := "slabs reassign " + strconv.Itoa(source) + " " + strconv.Itoa(dest) + "\r\n"
+	return c.runCommand(cmd, "OK")
+}
+
+// SlabsAutomoveMode ...
+type SlabsAutomoveMode int
+
+const (
+	// SlabsAutomoveDisabled disables the automatic slab page mover
+	SlabsAutomoveDisabled SlabsAutomoveMode = 0
+
+	// SlabsAutomoveEnabled moves pages based on the eviction stats, the default of the server
+	SlabsAutomoveEnabled SlabsAutomoveMode = 1
+
+	// SlabsAutomoveAggressive moves pages on every eviction, NOT recommended
+	SlabsAutomoveAggressive SlabsAutomoveMode = 2
+)
+
+// SlabsAutomove sets the mode of the background slab page mover
+func (c *AdminClient) SlabsAutomove(mode SlabsAutomoveMode) error {
+	return c.runCommand("slabs automove "+strconv.Itoa(int(mode))+"\r\n", "OK")
+}
+
+// LRUTuneParams are the parameters of the segmented LRU
+type LRUTuneParams struct {
+	HotPercent  int // percent of memory of a slab class for the HOT LRU
+	WarmPercent int // percent of memory of a slab class for the WARM LRU
+
+	HotMaxFactor     float64 // items in HOT older than this factor times the age of COLD are moved to WARM
+	WarmMaxAgeFactor float64 // items in WARM older than this factor times the age of COLD are moved to COLD
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// LRUTune tunes the segmented LRU
+func (c *AdminClient) LRUTune(params LRUTuneParams) error {
+	cmd := "lru tune " + strconv.Itoa(params.HotPercent) + " " + strconv.Itoa(params.WarmPercent) + " " +
+		formatFloat(params.HotMaxFactor) + " " + formatFloat(params.WarmMaxAgeFactor) + "\r\n"
+	return c.runCommand(cmd, "OK")
+}
+
+// LRUMode ...
+type LRUMode string
+
+const (
+	// LRUModeFlat uses a single LRU for each slab class
+	LRUModeFlat LRUMode = "flat"
+
+	// LRUModeSegmented uses HOT, WARM, COLD and TEMP LRUs
+	LRUModeSegmented LRUMode = "segmented"
+)
+
+// LRUMode switches the LRU mode of the server
+func (c *AdminClient) LRUMode(mode LRUMode) error {
+	return c.runCommand("lru mode "+string(mode)+"\r\n", "OK")
+}
+
+// CacheMemLimit changes the memory limit of the server, in megabytes
+func (c *AdminClient) CacheMemLimit(megabytes uint64) error {
+	return c.runCommand("cache_memlimit "+strconv.FormatUint(megabytes, 10)+"\r\n", "OK")
+}
+
+// FlushAll invalidates all items after **delay** (rounded down to seconds), zero means immediately
+func (c *AdminClient) FlushAll(delay time.Duration) error {
+	secs := int64(delay / time.Second)
+	if secs <= 0 {
+		return c.runCommand("flush_all\r\n", "OK")
+	}
+	return c.runCommand("flush_all "+strconv.FormatInt(secs, 10)+"\r\n", "OK")
+}
+
+// ResetStats resets the counters of the general stats
+func (c *AdminClient) ResetStats() error {
+	return c.runCommand("stats reset\r\n", "RESET")
+}
+
+func (c *AdminClient) runShutdown(cmd string) error {
+	return c.client.do(func() error {
+		if err := c.client.writeCommand(cmd); err != nil {
+			return err
+		}
+
+		line, err := c.client.readReplyLineUnsafe()
+		if err == io.EOF {
+			// the server closed the connection without a reply
+			return c.client.disconnectUnsafe()
+		}
+		if err != nil {
+			return err
+		}
+
+		if line != "OK" {
+			return newReplyError(cmd, line)
+		}
+		return c.client.disconnectUnsafe()
+	})
+}
+
+// Shutdown stops the server, it must be started with the -A option (enable shutdown command)
+func (c *AdminClient) Shutdown() error {
+	return c.runShutdown("shutdown\r\n")
+}
+
+// ShutdownGraceful stops the server gracefully (sending SIGUSR1), available since memcached 1.6
+func (c *AdminClient) ShutdownGraceful() error {
+	return c.runShutdown("shutdown graceful\r\n")
+}
+
+// Close ...
+func (c *AdminClient) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,178 @@
+package stats
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newAdminClientTest(t *testing.T, inputs ...string) (*AdminClient, *dialTest) {
+	d := &dialTest{inputs: inputs}
+	c := NewAdminClient("localhost:11211", WithDialFunc(d.dial))
+	t.Cleanup(func() { _ = c.Close() })
+	return c, d
+}
+
+func TestAdminClient_Commands(t *testing.T) {
+	tests := []struct {
+		name  string
+		call  func(c *AdminClient) error
+		cmd   string
+		reply string
+	}{
+		{
+			name:  "verbosity",
+			call:  func(c *AdminClient) error { return c.Verbosity(1) },
+			cmd:   "verbosity 1\r\n",
+			reply: "OK",
+		},
+		{
+			name:  "slabs reassign",
+			call:  func(c *AdminClient) error { return c.SlabsReassign(AnySlabClass, 5) },
+			cmd:   "slabs reassign -1 5\r\n",
+			reply: "OK",
+		},
+		{
+			name:  "slabs automove",
+			call:  func(c *AdminClient) error { return c.SlabsAutomove(SlabsAutomoveDisabled) },
+			cmd:   "slabs automove 0\r\n",
+			reply: "OK",
+		},
+		{
+			name: "lru tune",
+			call: func(c *AdminClient) error {
+				return c.LRUTune(LRUTuneParams{
+					HotPercent:       20,
+					WarmPercent:      40,
+					HotMaxFactor:     0.2,
+					WarmMaxAgeFactor: 2,
+				})
+			},
+			cmd:   "lru tune 20 40 0.2 2\r\n",
+			reply: "OK",
+		},
+		{
+			name:  "lru mode",
+			call:  func(c *AdminClient) error { return c.LRUMode(LRUModeFlat) },
+			cmd:   "lru mode flat\r\n",
+			reply: "OK",
+		},
+		{
+			name:  "cache memlimit",
+			call:  func(c *AdminClient) error { return c.CacheMemLimit(1024) },
+			cmd:   "cache_memlimit 1024\r\n",
+			reply: "OK",
+		},
+		{
+			name:  "flush all",
+			call:  func(c *AdminClient) error { return c.FlushAll(0) },
+			cmd:   "flush_all\r\n",
+			reply: "OK",
+		},
+		{
+			name:  "flush all with delay",
+			call:  func(c *AdminClient) error { return c.FlushAll(90 * time.Second) },
+			cmd:   "flush_all 90\r\n",
+			reply: "OK",
+		},
+		{
+			name:  "stats reset",
+			call:  func(c *AdminClient) error { return c.ResetStats() },
+			cmd:   "stats reset\r\n",
+			reply: "RESET",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, d := newAdminClientTest(t, tc.reply+"\r\n")
+
+			err := tc.call(c)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.cmd, string(d.conns[0].writeBytes))
+		})
+	}
+}
+
+func TestAdminClient_Error_Replies(t *testing.T) {
+	t.Run("error", func(t *testing.T) {
+		c, _ := newAdminClientTest(t, "ERROR\r\n")
+
+		err := c.LRUMode("random")
+		assert.Equal(t, &ReplyError{
+			Command: "lru mode random",
+			Kind:    "ERROR",
+		}, err)
+		assert.Equal(t, "stats: command 'lru mode random' failed with reply: ERROR", err.Error())
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		c, _ := newAdminClientTest(t, "CLIENT_ERROR bad command line format\r\n")
+
+		err := c.Verbosity(-1)
+		assert.Equal(t, &ReplyError{
+			Command: "verbosity -1",
+			Kind:    "CLIENT_ERROR",
+			Message: "bad command line format",
+		}, err)
+	})
+
+	t.Run("slabs reassign busy then reuse connection", func(t *testing.T) {
+		c, d := newAdminClientTest(t, "BUSY currently processing reassign request\r\nOK\r\n")
+
+		err := c.SlabsReassign(3, 5)
+		assert.Equal(t, &ReplyError{
+			Command: "slabs reassign 3 5",
+			Kind:    "BUSY",
+			Message: "currently processing reassign request",
+		}, err)
+
+		err = c.SlabsReassign(3, 5)
+		assert.Equal(t, nil, err)
+
+		assert.Equal(t, 1, len(d.conns))
+	})
+
+	t.Run("connection closed", func(t *testing.T) {
+		c, d := newAdminClientTest(t, "", "OK\r\n")
+
+		err := c.Verbosity(1)
+		assert.Equal(t, io.EOF, err)
+
+		err = c.Verbosity(1)
+		assert.Equal(t, nil, err)
+
+		assert.Equal(t, 2, len(d.conns))
+	})
+}
+
+func TestAdminClient_Shutdown(t *testing.T) {
+	t.Run("connection closed by server", func(t *testing.T) {
+		c, d := newAdminClientTest(t, "")
+
+		err := c.Shutdown()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "shutdown\r\n", string(d.conns[0].writeBytes))
+	})
+
+	t.Run("graceful", func(t *testing.T) {
+		c, d := newAdminClientTest(t, "OK\r\n")
+
+		err := c.ShutdownGraceful()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "shutdown graceful\r\n", string(d.conns[0].writeBytes))
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		c, _ := newAdminClientTest(t, "ERROR: shutdown not enabled\r\n")
+
+		err := c.Shutdown()
+		assert.Equal(t, &ReplyError{
+			Command: "shutdown",
+			Kind:    "ERROR",
+			Message: "shutdown not enabled",
+		}, err)
+	})
+}
@@ -0,0 +1,238 @@
+package stats
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/QuangTung97/go-memcache/memcache/netconn"
+)
+
+type dialTest struct {
+	mut      sync.Mutex
+	dialErrs []error
+	inputs   []string
+	conns    []*connTest
+}
+
+func (d *dialTest) dial(_, _ string, _ time.Duration) (net.Conn, error) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	if len(d.dialErrs) > 0 {
+		err := d.dialErrs[0]
+		d.dialErrs = d.dialErrs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	input := ""
+	if len(d.inputs) > 0 {
+		input = d.inputs[0]
+		d.inputs = d.inputs[1:]
+	}
+
+	nc := &connTest{reader: strings.NewReader(input)}
+	d.conns = append(d.conns, nc)
+	return nc, nil
+}
+
+func (d *dialTest) numConns() int {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	return len(d.conns)
+}
+
+func TestClient_Reconnect(t *testing.T) {
+	t.Run("connection broken", func(t *testing.T) {
+		d := &dialTest{
+			inputs: []string{
+				buildResponse("STAT pid 11"),
+				buildResponse("STAT pid 12"),
+			},
+		}
+		c := New("localhost:11211", WithDialFunc(d.dial))
+		defer func() { _ = c.Close() }()
+
+		general, err := c.GetGeneralStats()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, uint64(11), general.PID)
+
+		_, err = c.GetGeneralStats()
+		assert.Equal(t, io.EOF, err)
+		assert.Equal(t, 1, d.numConns())
+
+		general, err = c.GetGeneralStats()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, uint64(12), general.PID)
+		assert.Equal(t, 2, d.numConns())
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		d := &dialTest{
+			inputs: []string{
+				buildResponse("STAT pid aa", "STAT uptime 100"),
+				buildResponse("STAT pid 12"),
+			},
+		}
+		c := New("localhost:11211", WithDialFunc(d.dial))
+		defer func() { _ = c.Close() }()
+
+		_, err := c.GetGeneralStats()
+		assert.Error(t, err)
+
+		general, err := c.GetGeneralStats()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, uint64(12), general.PID)
+		assert.Equal(t, 2, d.numConns())
+	})
+
+	t.Run("dial error with backoff", func(t *testing.T) {
+		dialErr := errors.New("dial error")
+		d := &dialTest{
+			dialErrs: []error{dialErr},
+			inputs: []string{
+				buildResponse("STAT pid 11"),
+			},
+		}
+
+		var logErrors []error
+		c := New("localhost:11211",
+			WithDialFunc(d.dial),
+			WithBackoffPolicy(netconn.NewConstantBackoff(time.Hour)),
+			WithErrorLogger(func(err error) {
+				logErrors = append(logErrors, err)
+			}),
+		)
+		defer func() { _ = c.Close() }()
+
+		_, err := c.GetGeneralStats()
+		assert.Equal(t, dialErr, err)
+
+		_, err = c.GetSlabsStats()
+		assert.Equal(t, dialErr, err)
+
+		assert.Equal(t, []error{dialErr}, logErrors)
+		assert.Equal(t, 0, d.numConns())
+	})
+
+	t.Run("dial error then redial", func(t *testing.T) {
+		dialErr := errors.New("dial error")
+		d := &dialTest{
+			dialErrs: []error{dialErr, dialErr},
+			inputs: []string{
+				buildResponse("STAT pid 11"),
+			},
+		}
+
+		var logErrors []error
+		c := New("localhost:11211",
+			WithDialFunc(d.dial),
+			WithBackoffPolicy(netconn.NewConstantBackoff(0)),
+			WithErrorLogger(func(err error) {
+				logErrors = append(logErrors, err)
+			}),
+		)
+		defer func() { _ = c.Close() }()
+
+		_, err := c.GetGeneralStats()
+		assert.Equal(t, dialErr, err)
+
+		general, err := c.GetGeneralStats()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, uint64(11), general.PID)
+
+		assert.Equal(t, []error{dialErr, dialErr}, logErrors)
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		d := &dialTest{}
+		c := New("localhost:11211", WithDialFunc(d.dial))
+
+		assert.Equal(t, nil, c.Close())
+		assert.Equal(t, nil, c.Close())
+
+		_, err := c.GetGeneralStats()
+		assert.Equal(t, ErrClientClosed, err)
+
+		err = c.MetaDumpAll(func(key MetaDumpKey) {})
+		assert.Equal(t, ErrClientClosed, err)
+
+		assert.Equal(t, 1, d.numConns())
+	})
+}
+
+func TestClient_With_Password_Auth(t *testing.T) {
+	d := &dialTest{
+		inputs: []string{
+			"STORED\r\n" + buildResponse("STAT pid 11"),
+		},
+	}
+
+	auth, err := netconn.NewPasswordAuth("user01", "password01")
+	assert.Equal(t, nil, err)
+
+	c := New("localhost:11211", WithDialFunc(auth.GetDialFunc(d.dial)))
+	defer func() { _ = c.Close() }()
+
+	general, err := c.GetGeneralStats()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(11), general.PID)
+
+	assert.Equal(t,
+		"set memcached_auth 0 0 17\r\nuser01 password01\r\nstats\r\n",
+		string(d.conns[0].writeBytes),
+	)
+}
+
+// servePipeStats responds "stats" commands on the server side of a net.Pipe
+func servePipeStats(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		if scanner.Text() != "stats" {
+			return
+		}
+		if _, err := conn.Write([]byte(buildResponse("STAT pid 123", "STAT uptime 3"))); err != nil {
+			return
+		}
+	}
+}
+
+func TestClient_Concurrent_Requests(t *testing.T) {
+	dialFunc := func(_, _ string, _ time.Duration) (net.Conn, error) {
+		client, server := net.Pipe()
+		go servePipeStats(server)
+		return client, nil
+	}
+
+	c := New("localhost:11211", WithDialFunc(dialFunc))
+	defer func() { _ = c.Close() }()
+
+	const numThreads = 8
+	const numRequests = 50
+
+	var wg sync.WaitGroup
+	wg.Add(numThreads)
+	for i := 0; i < numThreads; i++ {
+		go func() {
+			defer wg.Done()
+			for k := 0; k < numRequests; k++ {
+				general, err := c.GetGeneralStats()
+				assert.Equal(t, nil, err)
+				assert.Equal(t, uint64(123), general.PID)
+				assert.Equal(t, uint64(3), general.Uptime)
+			}
+		}()
+	}
+	wg.Wait()
+}
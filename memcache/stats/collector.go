@@ -0,0 +1,358 @@
+package stats
+
+import (
+	"log"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Sample is the result of a single poll of Collector
+type Sample struct {
+	Time    time.Time
+	General GeneralStats
+	Slabs   SlabsStats
+	Items   SlabItemsStats
+
+	// Interval is the duration since the previous sample, zero if there is no previous sample
+	// (the first sample, the server was restarted or the counters were reset by the "stats reset" command)
+	Interval time.Duration
+
+	// Rates are computed from the previous sample, zero if Interval is zero
+	Rates Rates
+
+	// SlabDeltas are the changes of each slab class since the previous sample, nil if Interval is zero.
+	// Slab classes that are new in this sample are compared with zero values
+	SlabDeltas map[uint32]SlabDelta
+}
+
+// Rates are computed from the cumulative counters of GeneralStats
+type Rates struct {
+	// HitRatio is get_hits / (get_hits + get_misses) in the interval, zero if there is no get
+	HitRatio float64
+
+	GetsPerSec      float64
+	SetsPerSec      float64
+	HitsPerSec      float64
+	MissesPerSec    float64
+	EvictionsPerSec float64
+
+	BytesReadPerSec    float64 // bytes received from clients
+	BytesWrittenPerSec float64 // bytes sent to clients
+}
+
+// SlabDelta is the changes of a slab class, negative if decreased
+type SlabDelta struct {
+	TotalPages int64
+	UsedChunks int64
+	Items      int64
+
+	Evicted          int64
+	Reclaimed        int64
+	OutOfMemory      int64
+	ExpiredUnfetched int64
+	EvictedUnfetched int64
+}
+
+type collectorConfig struct {
+	errorHandler func(err error)
+	nowFunc      func() time.Time
+}
+
+// CollectorOption ...
+type CollectorOption func(conf *collectorConfig)
+
+// WithCollectorErrorHandler is called when a poll failed, the failed poll is skipped
+// and the next sample is computed from the last successful one. Default logs the error
+func WithCollectorErrorHandler(fn func(err error)) CollectorOption {
+	return func(conf *collectorConfig) {
+		conf.errorHandler = fn
+	}
+}
+
+// Collector polls a server on an interval using a Client, and computes rates & deltas between samples
+type Collector struct {
+	client   *Client
+	interval time.Duration
+	conf     collectorConfig
+
+	closeOnce sync.Once
+	closeChan chan struct{}
+	wg        sync.WaitGroup
+
+	mut         sync.Mutex
+	latest      *Sample
+	nextSubID   uint64
+	subscribers map[uint64]func(sample Sample)
+}
+
+// ErrInvalidCollectorInterval is returned by NewCollector when the interval is NOT positive
+var ErrInvalidCollectorInterval = NewError("collector interval must be positive")
+
+// NewCollector starts polling with the first sample taken right away.
+// The **client** is NOT closed by the collector
+func NewCollector(client *Client, interval time.Duration, options ...CollectorOption) (*Collector, error) {
+	if interval <= 0 {
+		return nil, ErrInvalidCollectorInterval
+	}
+
+	conf := collectorConfig{
+		errorHandler: func(err error) {
+			log.Println("[ERROR] Collect memcache stats with error:", err)
+		},
+		nowFunc: time.Now,
+	}
+	for _, fn := range options {
+		fn(&conf)
+	}
+
+	c := newCollectorWithConfig(client, interval, conf)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.run()
+	}()
+
+	return c, nil
+}
+
+func newCollectorWithConfig(client *Client, interval time.Duration, conf collectorConfig) *Collector {
+	return &Collector{
+		client:   client,
+		interval: interval,
+		conf:     conf,
+
+		closeChan:   make(chan struct{}),
+		subscribers: map[uint64]func(sample Sample){},
+	}
+}
+
+func (c *Collector) run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.collect()
+
+		select {
+		case <-ticker.C:
+		case <-c.closeChan:
+			return
+		}
+	}
+}
+
+// Subscribe registers **fn** to be called with every new sample, in the polling goroutine.
+// Each subscriber receives its own copy of the sample.
+// Calling the returned function to unsubscribe
+func (c *Collector) Subscribe(fn func(sample Sample)) (unsubscribe func()) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.nextSubID++
+	id := c.nextSubID
+	c.subscribers[id] = fn
+
+	return func() {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+		delete(c.subscribers, id)
+	}
+}
+
+// Latest returns the latest sample, false if there is no successful poll yet
+func (c *Collector) Latest() (Sample, bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.latest == nil {
+		return Sample{}, false
+	}
+	return c.latest.clone(), true
+}
+
+// Close stops polling and waits for the polling goroutine to finish, can be called multiple times
+func (c *Collector) Close() {
+	c.closeOnce.Do(func() {
+		close(c.closeChan)
+	})
+	c.wg.Wait()
+}
+
+// clone copies the maps & slices, such that the subscribers can NOT modify the samples of each other
+func (s Sample) clone() Sample {
+	s.General.Unknown = maps.Clone(s.General.Unknown)
+	s.Slabs.SlabIDs = slices.Clone(s.Slabs.SlabIDs)
+	s.Slabs.Slabs = maps.Clone(s.Slabs.Slabs)
+	s.Items.SlabIDs = slices.Clone(s.Items.SlabIDs)
+	s.Items.Slabs = maps.Clone(s.Items.Slabs)
+	s.SlabDeltas = maps.Clone(s.SlabDeltas)
+	return s
+}
+
+func (c *Collector) poll() (Sample, error) {
+	general, err := c.client.GetGeneralStats()
+	if err != nil {
+		return Sample{}, err
+	}
+
+	slabs, err := c.client.GetSlabsStats()
+	if err != nil {
+		return Sample{}, err
+	}
+
+	items, err := c.client.GetSlabItemsStats()
+	if err != nil {
+		return Sample{}, err
+	}
+
+	return Sample{
+		Time:    c.conf.nowFunc(),
+		General: general,
+		Slabs:   slabs,
+		Items:   items,
+	}, nil
+}
+
+func (c *Collector) collect() {
+	sample, err := c.poll()
+	if err != nil {
+		c.conf.errorHandler(err)
+		return
+	}
+
+	c.mut.Lock()
+	computeSampleChanges(c.latest, &sample)
+	c.latest = &sample
+
+	subscribers := make([]func(sample Sample), 0, len(c.subscribers))
+	for _, fn := range c.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	c.mut.Unlock()
+
+	for _, fn := range subscribers {
+		fn(sample.clone())
+	}
+}
+
+func isServerRestarted(prev *Sample, current *Sample) bool {
+	return prev.General.PID != current.General.PID || current.General.Uptime < prev.General.Uptime
+}
+
+func anyDecreased(pairs ...[2]uint64) bool {
+	for _, p := range pairs {
+		if p[1] < p[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// isCountersReset checks whether any cumulative counter decreased, e.g. by the "stats reset" command
+func isCountersReset(prev *Sample, current *Sample) bool {
+	p, c := prev.General, current.General
+	if anyDecreased(
+		[2]uint64{p.CmdGet, c.CmdGet},
+		[2]uint64{p.CmdSet, c.CmdSet},
+		[2]uint64{p.GetHits, c.GetHits},
+		[2]uint64{p.GetMisses, c.GetMisses},
+		[2]uint64{p.Evictions, c.Evictions},
+		[2]uint64{p.BytesRead, c.BytesRead},
+		[2]uint64{p.BytesWritten, c.BytesWritten},
+	) {
+		return true
+	}
+
+	for id, items := range current.Items.Slabs {
+		prevItems, ok := prev.Items.Slabs[id]
+		if !ok {
+			continue
+		}
+		if anyDecreased(
+			[2]uint64{prevItems.Evicted, items.Evicted},
+			[2]uint64{prevItems.Reclaimed, items.Reclaimed},
+			[2]uint64{prevItems.OutOfMemory, items.OutOfMemory},
+			[2]uint64{prevItems.ExpiredUnfetched, items.ExpiredUnfetched},
+			[2]uint64{prevItems.EvictedUnfetched, items.EvictedUnfetched},
+		) {
+			return true
+		}
+	}
+	return false
+}
+
+// computeSampleChanges computes the rates & the deltas of **current** compared to **prev**
+func computeSampleChanges(prev *Sample, current *Sample) {
+	if prev == nil || isServerRestarted(prev, current) || isCountersReset(prev, current) {
+		return
+	}
+
+	interval := current.Time.Sub(prev.Time)
+	if interval <= 0 {
+		return
+	}
+	current.Interval = interval
+	current.Rates = computeRates(prev.General, current.General, interval)
+
+	current.SlabDeltas = map[uint32]SlabDelta{}
+	for _, id := range current.Slabs.SlabIDs {
+		current.SlabDeltas[id] = computeSlabDelta(prev, current, id)
+	}
+	for _, id := range current.Items.SlabIDs {
+		if _, existed := current.SlabDeltas[id]; !existed {
+			current.SlabDeltas[id] = computeSlabDelta(prev, current, id)
+		}
+	}
+}
+
+func delta(prev uint64, current uint64) int64 {
+	return int64(current - prev)
+}
+
+func computeRates(prev GeneralStats, current GeneralStats, interval time.Duration) Rates {
+	secs := interval.Seconds()
+	perSec := func(prev uint64, current uint64) float64 {
+		return float64(delta(prev, current)) / secs
+	}
+
+	rates := Rates{
+		GetsPerSec:      perSec(prev.CmdGet, current.CmdGet),
+		SetsPerSec:      perSec(prev.CmdSet, current.CmdSet),
+		HitsPerSec:      perSec(prev.GetHits, current.GetHits),
+		MissesPerSec:    perSec(prev.GetMisses, current.GetMisses),
+		EvictionsPerSec: perSec(prev.Evictions, current.Evictions),
+
+		BytesReadPerSec:    perSec(prev.BytesRead, current.BytesRead),
+		BytesWrittenPerSec: perSec(prev.BytesWritten, current.BytesWritten),
+	}
+
+	hits := delta(prev.GetHits, current.GetHits)
+	misses := delta(prev.GetMisses, current.GetMisses)
+	if hits+misses > 0 {
+		rates.HitRatio = float64(hits) / float64(hits+misses)
+	}
+	return rates
+}
+
+func computeSlabDelta(prev *Sample, current *Sample, id uint32) SlabDelta {
+	prevSlab := prev.Slabs.Slabs[id]
+	slab := current.Slabs.Slabs[id]
+
+	prevItems := prev.Items.Slabs[id]
+	items := current.Items.Slabs[id]
+
+	return SlabDelta{
+		TotalPages: delta(uint64(prevSlab.TotalPages), uint64(slab.TotalPages)),
+		UsedChunks: delta(prevSlab.UsedChunks, slab.UsedChunks),
+		Items:      delta(prevItems.Number, items.Number),
+
+		Evicted:          delta(prevItems.Evicted, items.Evicted),
+		Reclaimed:        delta(prevItems.Reclaimed, items.Reclaimed),
+		OutOfMemory:      delta(prevItems.OutOfMemory, items.OutOfMemory),
+		ExpiredUnfetched: delta(prevItems.ExpiredUnfetched, items.ExpiredUnfetched),
+		EvictedUnfetched: delta(prevItems.EvictedUnfetched, items.EvictedUnfetched),
+	}
+}
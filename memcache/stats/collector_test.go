@@ -0,0 +1,370 @@
+package stats
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// collectorServerTest responds the stats commands on the server side of a net.Pipe with the current counters
+type collectorServerTest struct {
+	mut       sync.Mutex
+	pid       uint64
+	uptime    uint64
+	getHits   uint64
+	getMisses uint64
+	evicted   uint64
+}
+
+func (s *collectorServerTest) update(fn func(s *collectorServerTest)) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	fn(s)
+}
+
+func (s *collectorServerTest) response(cmd string) string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	num := func(n uint64) string {
+		return strconv.FormatUint(n, 10)
+	}
+
+	switch cmd {
+	case "stats":
+		return buildResponse(
+			"STAT pid "+num(s.pid),
+			"STAT uptime "+num(s.uptime),
+			"STAT cmd_get "+num(s.getHits+s.getMisses),
+			"STAT cmd_set "+num(s.getMisses),
+			"STAT get_hits "+num(s.getHits),
+			"STAT get_misses "+num(s.getMisses),
+			"STAT evictions "+num(s.evicted),
+			"STAT bytes_read "+num(s.getMisses*100),
+			"STAT bytes_written "+num(s.getHits*100),
+		)
+	case "stats slabs":
+		return buildResponse(
+			"STAT 6:total_pages "+num(s.getMisses/10),
+			"STAT 6:used_chunks "+num(s.getMisses),
+		)
+	case "stats items":
+		return buildResponse(
+			"STAT items:6:number "+num(s.getMisses-s.evicted),
+			"STAT items:6:evicted "+num(s.evicted),
+		)
+	default:
+		return "ERROR\r\n"
+	}
+}
+
+func (s *collectorServerTest) dial(_, _ string, _ time.Duration) (net.Conn, error) {
+	client, server := net.Pipe()
+	go func() {
+		defer func() { _ = server.Close() }()
+
+		scanner := bufio.NewScanner(server)
+		for scanner.Scan() {
+			if _, err := server.Write([]byte(s.response(scanner.Text()))); err != nil {
+				return
+			}
+		}
+	}()
+	return client, nil
+}
+
+func newCollectorTest(t *testing.T, server *collectorServerTest) (*Collector, *time.Time) {
+	client := New("localhost:11211", WithDialFunc(server.dial))
+	t.Cleanup(func() { _ = client.Close() })
+
+	now := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	c := newCollectorWithConfig(client, time.Second, collectorConfig{
+		errorHandler: func(err error) {},
+		nowFunc: func() time.Time {
+			return now
+		},
+	})
+	return c, &now
+}
+
+func TestCollector(t *testing.T) {
+	t.Run("rates and deltas", func(t *testing.T) {
+		server := &collectorServerTest{
+			pid:       100,
+			uptime:    50,
+			getHits:   1000,
+			getMisses: 200,
+		}
+		c, now := newCollectorTest(t, server)
+
+		var samples []Sample
+		c.Subscribe(func(sample Sample) {
+			samples = append(samples, sample)
+		})
+
+		_, ok := c.Latest()
+		assert.Equal(t, false, ok)
+
+		c.collect()
+
+		assert.Equal(t, 1, len(samples))
+		assert.Equal(t, time.Duration(0), samples[0].Interval)
+		assert.Equal(t, Rates{}, samples[0].Rates)
+		assert.Nil(t, samples[0].SlabDeltas)
+		assert.Equal(t, uint64(1000), samples[0].General.GetHits)
+
+		server.update(func(s *collectorServerTest) {
+			s.uptime += 2
+			s.getHits += 300
+			s.getMisses += 100
+			s.evicted += 10
+		})
+		*now = now.Add(2 * time.Second)
+
+		c.collect()
+
+		assert.Equal(t, 2, len(samples))
+		assert.Equal(t, 2*time.Second, samples[1].Interval)
+		assert.Equal(t, Rates{
+			HitRatio:           0.75,
+			GetsPerSec:         200,
+			SetsPerSec:         50,
+			HitsPerSec:         150,
+			MissesPerSec:       50,
+			EvictionsPerSec:    5,
+			BytesReadPerSec:    5000,
+			BytesWrittenPerSec: 15000,
+		}, samples[1].Rates)
+		assert.Equal(t, map[uint32]SlabDelta{
+			6: {
+				TotalPages: 10,
+				UsedChunks: 100,
+				Items:      90,
+				Evicted:    10,
+			},
+		}, samples[1].SlabDeltas)
+
+		latest, ok := c.Latest()
+		assert.Equal(t, true, ok)
+		assert.Equal(t, samples[1], latest)
+	})
+
+	t.Run("server restarted", func(t *testing.T) {
+		server := &collectorServerTest{
+			pid:       100,
+			uptime:    50,
+			getHits:   1000,
+			getMisses: 200,
+		}
+		c, now := newCollectorTest(t, server)
+
+		c.collect()
+
+		server.update(func(s *collectorServerTest) {
+			s.pid = 101
+			s.uptime = 1
+			s.getHits = 10
+			s.getMisses = 20
+		})
+		*now = now.Add(time.Second)
+
+		c.collect()
+
+		latest, ok := c.Latest()
+		assert.Equal(t, true, ok)
+		assert.Equal(t, time.Duration(0), latest.Interval)
+		assert.Equal(t, Rates{}, latest.Rates)
+		assert.Equal(t, uint64(10), latest.General.GetHits)
+	})
+
+	t.Run("stats reset", func(t *testing.T) {
+		server := &collectorServerTest{
+			pid:       100,
+			uptime:    50,
+			getHits:   1000,
+			getMisses: 200,
+			evicted:   30,
+		}
+		c, now := newCollectorTest(t, server)
+
+		c.collect()
+
+		server.update(func(s *collectorServerTest) {
+			s.uptime = 51
+			s.getHits = 10
+			s.getMisses = 20
+			s.evicted = 0
+		})
+		*now = now.Add(time.Second)
+
+		c.collect()
+
+		latest, ok := c.Latest()
+		assert.Equal(t, true, ok)
+		assert.Equal(t, time.Duration(0), latest.Interval)
+		assert.Equal(t, Rates{}, latest.Rates)
+		assert.Nil(t, latest.SlabDeltas)
+
+		server.update(func(s *collectorServerTest) {
+			s.uptime = 52
+			s.getHits = 30
+			s.getMisses = 30
+		})
+		*now = now.Add(time.Second)
+
+		c.collect()
+
+		latest, ok = c.Latest()
+		assert.Equal(t, true, ok)
+		assert.Equal(t, time.Second, latest.Interval)
+		assert.Equal(t, float64(20), latest.Rates.HitsPerSec)
+	})
+
+	t.Run("evicted reset only", func(t *testing.T) {
+		server := &collectorServerTest{pid: 100, uptime: 50, getMisses: 200, evicted: 30}
+		c, now := newCollectorTest(t, server)
+
+		c.collect()
+
+		server.update(func(s *collectorServerTest) {
+			s.uptime = 51
+			s.evicted = 0
+		})
+		*now = now.Add(time.Second)
+
+		c.collect()
+
+		latest, _ := c.Latest()
+		assert.Equal(t, time.Duration(0), latest.Interval)
+		assert.Nil(t, latest.SlabDeltas)
+	})
+
+	t.Run("subscribers receive copies", func(t *testing.T) {
+		server := &collectorServerTest{pid: 100, uptime: 50, getMisses: 200}
+		c, now := newCollectorTest(t, server)
+
+		c.Subscribe(func(sample Sample) {
+			sample.Slabs.Slabs[6] = SingleSlabStats{}
+			sample.Items.Slabs[6] = SingleSlabItemStats{}
+			delete(sample.SlabDeltas, 6)
+		})
+
+		var received Sample
+		c.Subscribe(func(sample Sample) {
+			received = sample
+		})
+
+		c.collect()
+		server.update(func(s *collectorServerTest) {
+			s.uptime = 51
+		})
+		*now = now.Add(time.Second)
+		c.collect()
+
+		latest, ok := c.Latest()
+		assert.Equal(t, true, ok)
+		assert.Equal(t, uint64(200), latest.Slabs.Slabs[6].UsedChunks)
+		assert.Equal(t, uint64(200), latest.Items.Slabs[6].Number)
+		assert.Equal(t, 1, len(latest.SlabDeltas))
+
+		assert.Equal(t, uint64(200), received.Slabs.Slabs[6].UsedChunks)
+		assert.Equal(t, 1, len(received.SlabDeltas))
+	})
+
+	t.Run("close twice", func(t *testing.T) {
+		client := New("localhost:11211", WithDialFunc((&collectorServerTest{pid: 100}).dial))
+		defer func() { _ = client.Close() }()
+
+		c, err := NewCollector(client, time.Hour)
+		assert.Equal(t, nil, err)
+		c.Close()
+		c.Close()
+	})
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		c, _ := newCollectorTest(t, &collectorServerTest{pid: 100})
+
+		count := 0
+		unsubscribe := c.Subscribe(func(sample Sample) {
+			count++
+		})
+
+		c.collect()
+		unsubscribe()
+		c.collect()
+
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("poll error", func(t *testing.T) {
+		dialErr := errors.New("dial error")
+		client := New("localhost:11211", WithDialFunc(func(_, _ string, _ time.Duration) (net.Conn, error) {
+			return nil, dialErr
+		}), WithErrorLogger(func(err error) {}))
+		defer func() { _ = client.Close() }()
+
+		var errs []error
+		c := newCollectorWithConfig(client, time.Second, collectorConfig{
+			errorHandler: func(err error) {
+				errs = append(errs, err)
+			},
+			nowFunc: time.Now,
+		})
+
+		c.collect()
+
+		assert.Equal(t, []error{dialErr}, errs)
+		_, ok := c.Latest()
+		assert.Equal(t, false, ok)
+	})
+}
+
+func TestNewCollector(t *testing.T) {
+	server := &collectorServerTest{pid: 100}
+
+	client := New("localhost:11211", WithDialFunc(server.dial))
+	defer func() { _ = client.Close() }()
+
+	c, err := NewCollector(client, 5*time.Millisecond)
+	assert.Equal(t, nil, err)
+
+	sampleCh := make(chan Sample, 16)
+	c.Subscribe(func(sample Sample) {
+		select {
+		case sampleCh <- sample:
+		default:
+		}
+	})
+
+	for i := 0; i < 2; i++ {
+		server.update(func(s *collectorServerTest) {
+			s.uptime++
+			s.getHits += 10
+		})
+		sample := <-sampleCh
+		assert.Equal(t, uint64(100), sample.General.PID)
+	}
+
+	c.Close()
+
+	latest, ok := c.Latest()
+	assert.Equal(t, true, ok)
+	assert.Equal(t, uint64(100), latest.General.PID)
+}
+
+func TestNewCollector_Invalid_Interval(t *testing.T) {
+	client := New("localhost:11211", WithDialFunc((&collectorServerTest{pid: 100}).dial))
+	defer func() { _ = client.Close() }()
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		c, err := NewCollector(client, interval)
+		assert.Equal(t, ErrInvalidCollectorInterval, err)
+		assert.Nil(t, c)
+	}
+}
@@ -0,0 +1,44 @@
+package stats
+
+// ConnsStats is the result of the "stats conns" command, connections are identified by their file descriptors
+type ConnsStats struct {
+	FDs   []uint32
+	Conns map[uint32]SingleConnStats
+}
+
+// SingleConnStats ...
+type SingleConnStats struct {
+	Addr             string // e.g. tcp:127.0.0.1:54321
+	ListenAddr       string
+	State            string // e.g. conn_parse_cmd
+	SecsSinceLastCmd uint64
+}
+
+var connStatsFields = statFields[SingleConnStats]{
+	"addr":                func(s *SingleConnStats) any { return &s.Addr },
+	"listen_addr":         func(s *SingleConnStats) any { return &s.ListenAddr },
+	"state":               func(s *SingleConnStats) any { return &s.State },
+	"secs_since_last_cmd": func(s *SingleConnStats) any { return &s.SecsSinceLastCmd },
+}
+
+// GetConnsStats ...
+func (c *Client) GetConnsStats() (ConnsStats, error) {
+	return doRequest(c, c.getConnsStatsUnsafe)
+}
+
+func (c *Client) getConnsStatsUnsafe() (ConnsStats, error) {
+	if err := c.writeCommand("stats conns\r\n"); err != nil {
+		return ConnsStats{}, err
+	}
+
+	result := ConnsStats{}
+
+	for c.parser.next() {
+		err := connStatsFields.parseIndexed(&result.FDs, &result.Conns, c.parser.getItem())
+		if err != nil {
+			return ConnsStats{}, err
+		}
+	}
+
+	return result, c.parser.getError()
+}
@@ -0,0 +1,64 @@
+package stats
+
+import (
+	"strings"
+)
+
+// ExtstoreStats is the result of the "stats extstore" command, only available when extstore is enabled
+type ExtstoreStats struct {
+	PageCount uint64
+
+	PageIDs []uint32
+	Pages   map[uint32]ExtstorePageStats
+
+	// Unknown contains the stats with keys not in the list above, nil if there is no such key
+	Unknown map[string]string
+}
+
+// ExtstorePageStats ...
+type ExtstorePageStats struct {
+	Version    uint64
+	Bytes      uint64
+	Bucket     uint64
+	FreeBucket uint64
+}
+
+var extstoreStatsFields = statFields[ExtstoreStats]{
+	"page_count": func(s *ExtstoreStats) any { return &s.PageCount },
+}
+
+var extstorePageStatsFields = statFields[ExtstorePageStats]{
+	"version":     func(s *ExtstorePageStats) any { return &s.Version },
+	"bytes":       func(s *ExtstorePageStats) any { return &s.Bytes },
+	"bucket":      func(s *ExtstorePageStats) any { return &s.Bucket },
+	"free_bucket": func(s *ExtstorePageStats) any { return &s.FreeBucket },
+}
+
+// GetExtstoreStats ...
+func (c *Client) GetExtstoreStats() (ExtstoreStats, error) {
+	return doRequest(c, c.getExtstoreStatsUnsafe)
+}
+
+func (c *Client) getExtstoreStatsUnsafe() (ExtstoreStats, error) {
+	if err := c.writeCommand("stats extstore\r\n"); err != nil {
+		return ExtstoreStats{}, err
+	}
+
+	result := ExtstoreStats{}
+
+	for c.parser.next() {
+		item := c.parser.getItem()
+
+		var err error
+		if strings.Contains(item.key, ":") {
+			err = extstorePageStatsFields.parseIndexed(&result.PageIDs, &result.Pages, item)
+		} else {
+			err = extstoreStatsFields.parse(&result, &result.Unknown, item)
+		}
+		if err != nil {
+			return ExtstoreStats{}, err
+		}
+	}
+
+	return result, c.parser.getError()
+}
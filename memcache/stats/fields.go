@@ -0,0 +1,92 @@
+package stats
+
+import (
+	"strconv"
+	"strings"
+)
+
+// statFields maps from the stat key to the pointer of the field of T, with types: *uint64, *float64, *string, *bool
+type statFields[T any] map[string]func(s *T) any
+
+// parse sets the field of the stat item, or adds to the **unknown** map if the key is not in the list
+func (f statFields[T]) parse(s *T, unknown *map[string]string, item statItem) error {
+	getField, ok := f[item.key]
+	if !ok {
+		if *unknown == nil {
+			*unknown = map[string]string{}
+		}
+		(*unknown)[item.key] = item.value
+		return nil
+	}
+	return setStatValue(getField(s), item.value)
+}
+
+// parseIndexed parses the stat item with key in the form <id>:<name>, unknown names are ignored.
+// The **values** map is created on the first known stat
+func (f statFields[T]) parseIndexed(ids *[]uint32, values *map[uint32]T, item statItem) error {
+	idStr, name, ok := strings.Cut(item.key, ":")
+	if !ok {
+		return NewError("missing id of stat key: " + item.key)
+	}
+
+	idValue, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return err
+	}
+	id := uint32(idValue)
+
+	getField, ok := f[name]
+	if !ok {
+		return nil
+	}
+
+	value, existed := (*values)[id]
+	if err := setStatValue(getField(&value), item.value); err != nil {
+		return err
+	}
+
+	if !existed {
+		*ids = append(*ids, id)
+	}
+	if *values == nil {
+		*values = map[uint32]T{}
+	}
+	(*values)[id] = value
+	return nil
+}
+
+// setStatValue parses **value** and sets to the field pointed by **ptr**
+func setStatValue(ptr any, value string) error {
+	switch p := ptr.(type) {
+	case *uint64:
+		num, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		*p = num
+
+	case *float64:
+		num, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		*p = num
+
+	case *bool:
+		switch value {
+		case "1", "yes", "on":
+			*p = true
+		case "0", "no", "off":
+			*p = false
+		default:
+			return NewError("invalid boolean value: " + value)
+		}
+
+	case *string:
+		*p = value
+
+	default:
+		panic("invalid stat field type")
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package stats
+
+// GeneralStats is the result of the "stats" command.
+// Keys that are not known by this library (e.g. added by newer memcached versions) are stored in Unknown
+type GeneralStats struct {
+	PID          uint64
+	Uptime       uint64
+	Time         uint64
+	Version      string
+	Libevent     string
+	PointerSize  uint64
+	RusageUser   float64 // seconds
+	RusageSystem float64 // seconds
+
+	MaxConnections       uint64
+	CurrConnections      uint64
+	TotalConnections     uint64
+	RejectedConnections  uint64
+	ConnectionStructures uint64
+	ResponseObjOOM       uint64
+	ResponseObjCount     uint64
+	ResponseObjBytes     uint64
+	ReadBufOOM           uint64
+	ReservedFDs          uint64
+
+	CmdGet        uint64
+	CmdSet        uint64
+	CmdFlush      uint64
+	CmdTouch      uint64
+	CmdMeta       uint64
+	GetHits       uint64
+	GetMisses     uint64
+	GetExpired    uint64
+	GetFlushed    uint64
+	DeleteMisses  uint64
+	DeleteHits    uint64
+	IncrMisses    uint64
+	IncrHits      uint64
+	DecrMisses    uint64
+	DecrHits      uint64
+	CasMisses     uint64
+	CasHits       uint64
+	CasBadval     uint64
+	TouchHits     uint64
+	TouchMisses   uint64
+	StoreTooLarge uint64
+	StoreNoMemory uint64
+	AuthCmds      uint64
+	AuthErrors    uint64
+
+	BytesRead              uint64
+	BytesWritten           uint64
+	LimitMaxbytes          uint64
+	AcceptingConns         bool
+	ListenDisabledNum      uint64
+	TimeInListenDisabledUs uint64
+	Threads                uint64
+	ConnYields             uint64
+
+	HashPowerLevel  uint64
+	HashBytes       uint64
+	HashIsExpanding bool
+
+	SlabReassignRescues        uint64
+	SlabReassignChunkRescues   uint64
+	SlabReassignEvictionsNomem uint64
+	SlabReassignInlineReclaim  uint64
+	SlabReassignBusyItems      uint64
+	SlabReassignBusyDeletes    uint64
+	SlabReassignRunning        bool
+	SlabsMoved                 uint64
+
+	LRUCrawlerRunning    bool
+	LRUCrawlerStarts     uint64
+	LRUMaintainerJuggles uint64
+	MallocFails          uint64
+	LogWorkerDropped     uint64
+	LogWorkerWritten     uint64
+	LogWatcherSkipped    uint64
+	LogWatcherSent       uint64
+	LogWatchers          uint64
+
+	Bytes               uint64
+	CurrItems           uint64
+	TotalItems          uint64
+	SlabGlobalPagePool  uint64
+	ExpiredUnfetched    uint64
+	EvictedUnfetched    uint64
+	EvictedActive       uint64
+	Evictions           uint64
+	Reclaimed           uint64
+	CrawlerReclaimed    uint64
+	CrawlerItemsChecked uint64
+	LRUTailRefLocked    uint64
+	MovesToCold         uint64
+	MovesToWarm         uint64
+	MovesWithinLRU      uint64
+	DirectReclaims      uint64
+	LRUBumpsDropped     uint64
+
+	// Unknown contains the stats with keys not in the list above, nil if there is no such key
+	Unknown map[string]string
+}
+
+var generalStatsFields = statFields[GeneralStats]{
+	"pid":           func(s *GeneralStats) any { return &s.PID },
+	"uptime":        func(s *GeneralStats) any { return &s.Uptime },
+	"time":          func(s *GeneralStats) any { return &s.Time },
+	"version":       func(s *GeneralStats) any { return &s.Version },
+	"libevent":      func(s *GeneralStats) any { return &s.Libevent },
+	"pointer_size":  func(s *GeneralStats) any { return &s.PointerSize },
+	"rusage_user":   func(s *GeneralStats) any { return &s.RusageUser },
+	"rusage_system": func(s *GeneralStats) any { return &s.RusageSystem },
+
+	"max_connections":       func(s *GeneralStats) any { return &s.MaxConnections },
+	"curr_connections":      func(s *GeneralStats) any { return &s.CurrConnections },
+	"total_connections":     func(s *GeneralStats) any { return &s.TotalConnections },
+	"rejected_connections":  func(s *GeneralStats) any { return &s.RejectedConnections },
+	"connection_structures": func(s *GeneralStats) any { return &s.ConnectionStructures },
+	"response_obj_oom":      func(s *GeneralStats) any { return &s.ResponseObjOOM },
+	"response_obj_count":    func(s *GeneralStats) any { return &s.ResponseObjCount },
+	"response_obj_bytes":    func(s *GeneralStats) any { return &s.ResponseObjBytes },
+	"read_buf_oom":          func(s *GeneralStats) any { return &s.ReadBufOOM },
+	"reserved_fds":          func(s *GeneralStats) any { return &s.ReservedFDs },
+
+	"cmd_get":         func(s *GeneralStats) any { return &s.CmdGet },
+	"cmd_set":         func(s *GeneralStats) any { return &s.CmdSet },
+	"cmd_flush":       func(s *GeneralStats) any { return &s.CmdFlush },
+	"cmd_touch":       func(s *GeneralStats) any { return &s.CmdTouch },
+	"cmd_meta":        func(s *GeneralStats) any { return &s.CmdMeta },
+	"get_hits":        func(s *GeneralStats) any { return &s.GetHits },
+	"get_misses":      func(s *GeneralStats) any { return &s.GetMisses },
+	"get_expired":     func(s *GeneralStats) any { return &s.GetExpired },
+	"get_flushed":     func(s *GeneralStats) any { return &s.GetFlushed },
+	"delete_misses":   func(s *GeneralStats) any { return &s.DeleteMisses },
+	"delete_hits":     func(s *GeneralStats) any { return &s.DeleteHits },
+	"incr_misses":     func(s *GeneralStats) any { return &s.IncrMisses },
+	"incr_hits":       func(s *GeneralStats) any { return &s.IncrHits },
+	"decr_misses":     func(s *GeneralStats) any { return &s.DecrMisses },
+	"decr_hits":       func(s *GeneralStats) any { return &s.DecrHits },
+	"cas_misses":      func(s *GeneralStats) any { return &s.CasMisses },
+	"cas_hits":        func(s *GeneralStats) any { return &s.CasHits },
+	"cas_badval":      func(s *GeneralStats) any { return &s.CasBadval },
+	"touch_hits":      func(s *GeneralStats) any { return &s.TouchHits },
+	"touch_misses":    func(s *GeneralStats) any { return &s.TouchMisses },
+	"store_too_large": func(s *GeneralStats) any { return &s.StoreTooLarge },
+	"store_no_memory": func(s *GeneralStats) any { return &s.StoreNoMemory },
+	"auth_cmds":       func(s *GeneralStats) any { return &s.AuthCmds },
+	"auth_errors":     func(s *GeneralStats) any { return &s.AuthErrors },
+
+	"bytes_read":                 func(s *GeneralStats) any { return &s.BytesRead },
+	"bytes_written":              func(s *GeneralStats) any { return &s.BytesWritten },
+	"limit_maxbytes":             func(s *GeneralStats) any { return &s.LimitMaxbytes },
+	"accepting_conns":            func(s *GeneralStats) any { return &s.AcceptingConns },
+	"listen_disabled_num":        func(s *GeneralStats) any { return &s.ListenDisabledNum },
+	"time_in_listen_disabled_us": func(s *GeneralStats) any { return &s.TimeInListenDisabledUs },
+	"threads":                    func(s *GeneralStats) any { return &s.Threads },
+	"conn_yields":                func(s *GeneralStats) any { return &s.ConnYields },
+
+	"hash_power_level":  func(s *GeneralStats) any { return &s.HashPowerLevel },
+	"hash_bytes":        func(s *GeneralStats) any { return &s.HashBytes },
+	"hash_is_expanding": func(s *GeneralStats) any { return &s.HashIsExpanding },
+
+	"slab_reassign_rescues":         func(s *GeneralStats) any { return &s.SlabReassignRescues },
+	"slab_reassign_chunk_rescues":   func(s *GeneralStats) any { return &s.SlabReassignChunkRescues },
+	"slab_reassign_evictions_nomem": func(s *GeneralStats) any { return &s.SlabReassignEvictionsNomem },
+	"slab_reassign_inline_reclaim":  func(s *GeneralStats) any { return &s.SlabReassignInlineReclaim },
+	"slab_reassign_busy_items":      func(s *GeneralStats) any { return &s.SlabReassignBusyItems },
+	"slab_reassign_busy_deletes":    func(s *GeneralStats) any { return &s.SlabReassignBusyDeletes },
+	"slab_reassign_running":         func(s *GeneralStats) any { return &s.SlabReassignRunning },
+	"slabs_moved":                   func(s *GeneralStats) any { return &s.SlabsMoved },
+
+	"lru_crawler_running":    func(s *GeneralStats) any { return &s.LRUCrawlerRunning },
+	"lru_crawler_starts":     func(s *GeneralStats) any { return &s.LRUCrawlerStarts },
+	"lru_maintainer_juggles": func(s *GeneralStats) any { return &s.LRUMaintainerJuggles },
+	"malloc_fails":           func(s *GeneralStats) any { return &s.MallocFails },
+	"log_worker_dropped":     func(s *GeneralStats) any { return &s.LogWorkerDropped },
+	"log_worker_written":     func(s *GeneralStats) any { return &s.LogWorkerWritten },
+	"log_watcher_skipped":    func(s *GeneralStats) any { return &s.LogWatcherSkipped },
+	"log_watcher_sent":       func(s *GeneralStats) any { return &s.LogWatcherSent },
+	"log_watchers":           func(s *GeneralStats) any { return &s.LogWatchers },
+
+	"bytes":                 func(s *GeneralStats) any { return &s.Bytes },
+	"curr_items":            func(s *GeneralStats) any { return &s.CurrItems },
+	"total_items":           func(s *GeneralStats) any { return &s.TotalItems },
+	"slab_global_page_pool": func(s *GeneralStats) any { return &s.SlabGlobalPagePool },
+	"expired_unfetched":     func(s *GeneralStats) any { return &s.ExpiredUnfetched },
+	"evicted_unfetched":     func(s *GeneralStats) any { return &s.EvictedUnfetched },
+	"evicted_active":        func(s *GeneralStats) any { return &s.EvictedActive },
+	"evictions":             func(s *GeneralStats) any { return &s.Evictions },
+	"reclaimed":             func(s *GeneralStats) any { return &s.Reclaimed },
+	"crawler_reclaimed":     func(s *GeneralStats) any { return &s.CrawlerReclaimed },
+	"crawler_items_checked": func(s *GeneralStats) any { return &s.CrawlerItemsChecked },
+	"lrutail_reflocked":     func(s *GeneralStats) any { return &s.LRUTailRefLocked },
+	"moves_to_cold":         func(s *GeneralStats) any { return &s.MovesToCold },
+	"moves_to_warm":         func(s *GeneralStats) any { return &s.MovesToWarm },
+	"moves_within_lru":      func(s *GeneralStats) any { return &s.MovesWithinLRU },
+	"direct_reclaims":       func(s *GeneralStats) any { return &s.DirectReclaims },
+	"lru_bumps_dropped":     func(s *GeneralStats) any { return &s.LRUBumpsDropped },
+}
+
+func (s *GeneralStats) parseStat(item statItem) error {
+	return generalStatsFields.parse(s, &s.Unknown, item)
+}
+
+// GetGeneralStats ...
+func (c *Client) GetGeneralStats() (GeneralStats, error) {
+	return doRequest(c, c.getGeneralStatsUnsafe)
+}
+
+func (c *Client) getGeneralStatsUnsafe() (GeneralStats, error) {
+	if err := c.writeCommand("stats\r\n"); err != nil {
+		return GeneralStats{}, err
+	}
+
+	result := GeneralStats{}
+
+	for c.parser.next() {
+		if err := result.parseStat(c.parser.getItem()); err != nil {
+			return GeneralStats{}, err
+		}
+	}
+
+	return result, c.parser.getError()
+}
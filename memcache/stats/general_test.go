@@ -0,0 +1,36 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneralStatsFields(t *testing.T) {
+	var s GeneralStats
+	base := reflect.ValueOf(&s).Elem()
+
+	// every field except Unknown is parsed from exactly one key
+	fieldNames := map[string]string{}
+	for key, getField := range generalStatsFields {
+		ptr := reflect.ValueOf(getField(&s)).Pointer()
+
+		name := ""
+		for i := 0; i < base.NumField(); i++ {
+			if base.Field(i).Addr().Pointer() == ptr {
+				name = base.Type().Field(i).Name
+			}
+		}
+		assert.NotEqual(t, "", name, key)
+
+		prevKey, existed := fieldNames[name]
+		assert.False(t, existed, "field %s of both %s and %s", name, key, prevKey)
+		fieldNames[name] = key
+
+		// panics if the field type is not supported
+		assert.Equal(t, nil, setStatValue(getField(&GeneralStats{}), "1"), key)
+	}
+
+	assert.Equal(t, base.NumField()-1, len(fieldNames))
+}
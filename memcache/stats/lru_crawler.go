@@ -0,0 +1,35 @@
+package stats
+
+import (
+	"strconv"
+	"time"
+)
+
+// LRUCrawlerEnable starts the LRU crawler background thread
+func (c *AdminClient) LRUCrawlerEnable() error {
+	return c.runCommand("lru_crawler enable\r\n", "OK")
+}
+
+// LRUCrawlerDisable stops the LRU crawler background thread
+func (c *AdminClient) LRUCrawlerDisable() error {
+	return c.runCommand("lru_crawler disable\r\n", "OK")
+}
+
+// LRUCrawlerCrawl schedules a crawl of the slab classes for reclaiming expired items, empty means all classes.
+// The crawl runs in background, the server can reply BUSY, BADCLASS or NOTSTARTED (no items to crawl),
+// returned as ReplyError
+func (c *AdminClient) LRUCrawlerCrawl(classes ...uint32) error {
+	return c.runCommand("lru_crawler crawl "+formatSlabClasses(classes)+"\r\n", "OK")
+}
+
+// LRUCrawlerSleep sets the sleep time between items of the crawler (in microseconds on the server),
+// to reduce the CPU usage of the crawler
+func (c *AdminClient) LRUCrawlerSleep(d time.Duration) error {
+	micros := int64(d / time.Microsecond)
+	return c.runCommand("lru_crawler sleep "+strconv.FormatInt(micros, 10)+"\r\n", "OK")
+}
+
+// LRUCrawlerToCrawl sets the max number of items to be inspected in each slab class per crawl, zero means unlimited
+func (c *AdminClient) LRUCrawlerToCrawl(numItems uint32) error {
+	return c.runCommand("lru_crawler tocrawl "+strconv.FormatUint(uint64(numItems), 10)+"\r\n", "OK")
+}
@@ -0,0 +1,79 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminClient_LRUCrawler(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(c *AdminClient) error
+		cmd  string
+	}{
+		{
+			name: "enable",
+			call: func(c *AdminClient) error { return c.LRUCrawlerEnable() },
+			cmd:  "lru_crawler enable\r\n",
+		},
+		{
+			name: "disable",
+			call: func(c *AdminClient) error { return c.LRUCrawlerDisable() },
+			cmd:  "lru_crawler disable\r\n",
+		},
+		{
+			name: "crawl all",
+			call: func(c *AdminClient) error { return c.LRUCrawlerCrawl() },
+			cmd:  "lru_crawler crawl all\r\n",
+		},
+		{
+			name: "crawl classes",
+			call: func(c *AdminClient) error { return c.LRUCrawlerCrawl(1, 3, 12) },
+			cmd:  "lru_crawler crawl 1,3,12\r\n",
+		},
+		{
+			name: "sleep",
+			call: func(c *AdminClient) error { return c.LRUCrawlerSleep(2 * time.Millisecond) },
+			cmd:  "lru_crawler sleep 2000\r\n",
+		},
+		{
+			name: "tocrawl",
+			call: func(c *AdminClient) error { return c.LRUCrawlerToCrawl(5000) },
+			cmd:  "lru_crawler tocrawl 5000\r\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, d := newAdminClientTest(t, "OK\r\n")
+
+			err := tc.call(c)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tc.cmd, string(d.conns[0].writeBytes))
+		})
+	}
+
+	t.Run("crawl not started", func(t *testing.T) {
+		c, _ := newAdminClientTest(t, "NOTSTARTED no items to crawl\r\n")
+
+		err := c.LRUCrawlerCrawl(2)
+		assert.Equal(t, &ReplyError{
+			Command: "lru_crawler crawl 2",
+			Kind:    "NOTSTARTED",
+			Message: "no items to crawl",
+		}, err)
+	})
+
+	t.Run("enable failed", func(t *testing.T) {
+		c, _ := newAdminClientTest(t, "ERROR failed to start lru crawler thread\r\n")
+
+		err := c.LRUCrawlerEnable()
+		assert.Equal(t, &ReplyError{
+			Command: "lru_crawler enable",
+			Kind:    "ERROR",
+			Message: "failed to start lru crawler thread",
+		}, err)
+	})
+}
@@ -0,0 +1,147 @@
+package stats
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// MetaDumpOptions selects the keys to be dumped by MetaDump, the default dumps all keys
+type MetaDumpOptions struct {
+	// Classes only dumps keys of these slab classes, empty means all classes
+	Classes []uint32
+
+	// Hash dumps keys by walking the hash table (lru_crawler metadump hash) instead of the LRUs,
+	// can NOT be used together with Classes
+	Hash bool
+}
+
+func (o MetaDumpOptions) buildCommand() (string, error) {
+	if o.Hash {
+		if len(o.Classes) > 0 {
+			return "", NewError("metadump hash can not be used with slab classes")
+		}
+		return "lru_crawler metadump hash\r\n", nil
+	}
+
+	return "lru_crawler metadump " + formatSlabClasses(o.Classes) + "\r\n", nil
+}
+
+// formatSlabClasses returns the comma separated list of slab classes, "all" if empty
+func formatSlabClasses(classes []uint32) string {
+	if len(classes) == 0 {
+		return "all"
+	}
+
+	var b strings.Builder
+	for i, class := range classes {
+		if i > 0 {
+			_ = b.WriteByte(',')
+		}
+		_, _ = b.WriteString(strconv.FormatUint(uint64(class), 10))
+	}
+	return b.String()
+}
+
+// metaDumpErrorPrefixes are the replies of the server when the metadump can NOT be started,
+// e.g. "BUSY currently processing crawler request" or "BADCLASS invalid class id"
+var metaDumpErrorPrefixes = []string{
+	"BUSY", "BADCLASS", "ERROR", "CLIENT_ERROR", "SERVER_ERROR",
+}
+
+func isMetaDumpErrorReply(line string) bool {
+	for _, prefix := range metaDumpErrorPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// MetaDump dumps the keys selected by **options**, calling **scanFunc** for each key.
+// Dumping stops early when **scanFunc** returns false (MetaDump returns nil)
+// or when **ctx** is cancelled (MetaDump returns ctx.Err()).
+//
+// The server can NOT be asked to stop a metadump, so after stopping early the connection is closed,
+// and the next request of the client dials a new connection.
+func (c *Client) MetaDump(
+	ctx context.Context, options MetaDumpOptions, scanFunc func(key MetaDumpKey) bool,
+) error {
+	cmd, err := options.buildCommand()
+	if err != nil {
+		return err
+	}
+
+	return c.do(func() error {
+		return c.metaDumpUnsafe(ctx, cmd, scanFunc)
+	})
+}
+
+func (c *Client) metaDumpUnsafe(ctx context.Context, cmd string, scanFunc func(key MetaDumpKey) bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := c.writeCommand(cmd); err != nil {
+		return err
+	}
+
+	// closing the connection unblocks the reading when ctx is cancelled
+	closer := c.nc.Closer
+	stopCloseFunc := context.AfterFunc(ctx, func() {
+		_ = closer.Close()
+	})
+	defer func() {
+		if !stopCloseFunc() {
+			_ = c.disconnectUnsafe()
+		}
+	}()
+
+	return c.scanMetaDumpKeys(ctx, scanFunc)
+}
+
+func (c *Client) scanMetaDumpKeys(ctx context.Context, scanFunc func(key MetaDumpKey) bool) error {
+	first := true
+	for c.scanner.Scan() {
+		line := c.scanner.Text()
+		if line == "END" {
+			return nil
+		}
+
+		if first && isMetaDumpErrorReply(line) {
+			c.keepConn = true
+			return NewError("metadump failed: " + line)
+		}
+		first = false
+
+		key, err := parseMetaDumpKey(line)
+		if err != nil {
+			return err
+		}
+
+		if !scanFunc(key) {
+			return c.stopMetaDump(nil)
+		}
+		if err := ctx.Err(); err != nil {
+			return c.stopMetaDump(err)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return c.stopMetaDump(err)
+	}
+	return c.scanner.Err()
+}
+
+// stopMetaDump closes the connection instead of reading the remaining keys, which can take minutes,
+// the next request dials a new connection. Then returns **stopErr**
+func (c *Client) stopMetaDump(stopErr error) error {
+	_ = c.disconnectUnsafe()
+	return stopErr
+}
+
+// MetaDumpAll dumps all keys, same as MetaDump with the default options
+func (c *Client) MetaDumpAll(scanFunc func(key MetaDumpKey)) error {
+	return c.MetaDump(context.Background(), MetaDumpOptions{}, func(key MetaDumpKey) bool {
+		scanFunc(key)
+		return true
+	})
+}
@@ -0,0 +1,247 @@
+package stats
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/QuangTung97/go-memcache/memcache/netconn"
+)
+
+func metaDumpLine(key string, class int) string {
+	return "key=" + key + " exp=-1 la=1675839370 cas=12 fetch=no cls=" + strconv.Itoa(class) + " size=70"
+}
+
+func TestStatsClient_MetaDump(t *testing.T) {
+	t.Run("all", func(t *testing.T) {
+		input := buildResponse(
+			metaDumpLine("key01", 1),
+			metaDumpLine("key02", 2),
+		)
+		c := newClientTest(t, strings.NewReader(input))
+
+		var keys []MetaDumpKey
+		err := c.client.MetaDumpAll(func(key MetaDumpKey) {
+			keys = append(keys, key)
+		})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []MetaDumpKey{
+			{Key: "key01", Exp: -1, LA: 1675839370, CAS: 12, Class: 1, Size: 70},
+			{Key: "key02", Exp: -1, LA: 1675839370, CAS: 12, Class: 2, Size: 70},
+		}, keys)
+
+		assert.Equal(t, "lru_crawler metadump all\r\n", string(c.nc.writeBytes))
+	})
+
+	t.Run("classes", func(t *testing.T) {
+		input := buildResponse(
+			metaDumpLine("key01", 3),
+		)
+		c := newClientTest(t, strings.NewReader(input))
+
+		var keys []string
+		err := c.client.MetaDump(context.Background(), MetaDumpOptions{
+			Classes: []uint32{3, 12},
+		}, func(key MetaDumpKey) bool {
+			keys = append(keys, key.Key)
+			return true
+		})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []string{"key01"}, keys)
+
+		assert.Equal(t, "lru_crawler metadump 3,12\r\n", string(c.nc.writeBytes))
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		c := newClientTest(t, strings.NewReader(buildResponse()))
+
+		err := c.client.MetaDump(context.Background(), MetaDumpOptions{Hash: true}, func(key MetaDumpKey) bool {
+			return true
+		})
+		assert.Equal(t, nil, err)
+
+		assert.Equal(t, "lru_crawler metadump hash\r\n", string(c.nc.writeBytes))
+	})
+
+	t.Run("hash with classes", func(t *testing.T) {
+		c := newClientTest(t, strings.NewReader(""))
+
+		err := c.client.MetaDump(context.Background(), MetaDumpOptions{
+			Hash:    true,
+			Classes: []uint32{1},
+		}, func(key MetaDumpKey) bool {
+			return true
+		})
+		assert.Equal(t, NewError("metadump hash can not be used with slab classes"), err)
+		assert.Equal(t, 0, len(c.nc.writeBytes))
+	})
+
+	t.Run("busy", func(t *testing.T) {
+		input := "BUSY currently processing crawler request\r\n" + buildResponse("STAT pid 123")
+		c := newClientTest(t, strings.NewReader(input))
+
+		err := c.client.MetaDump(context.Background(), MetaDumpOptions{}, func(key MetaDumpKey) bool {
+			return true
+		})
+		assert.Equal(t, NewError("metadump failed: BUSY currently processing crawler request"), err)
+
+		general, err := c.client.GetGeneralStats()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, uint64(123), general.PID)
+	})
+
+	t.Run("bad class", func(t *testing.T) {
+		c := newClientTest(t, strings.NewReader("BADCLASS invalid class id\r\n"))
+
+		err := c.client.MetaDump(context.Background(), MetaDumpOptions{
+			Classes: []uint32{100},
+		}, func(key MetaDumpKey) bool {
+			return true
+		})
+		assert.Equal(t, NewError("metadump failed: BADCLASS invalid class id"), err)
+	})
+}
+
+// startScriptServer replies **responses[i]** to the first command of the i-th accepted connection,
+// then keeps the connection open without replying
+func startScriptServer(t *testing.T, responses ...string) (addr string, numAccepted func() int) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, nil, err)
+
+	var mut sync.Mutex
+	var conns []net.Conn
+
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		_ = lis.Close()
+		<-done
+
+		mut.Lock()
+		defer mut.Unlock()
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	})
+
+	go func() {
+		defer close(done)
+		for _, resp := range responses {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+
+			mut.Lock()
+			conns = append(conns, conn)
+			mut.Unlock()
+
+			go func(conn net.Conn, resp string) {
+				reader := bufio.NewReader(conn)
+				if _, err := reader.ReadString('\n'); err != nil {
+					return
+				}
+				_, _ = conn.Write([]byte(resp))
+				_, _ = io.Copy(io.Discard, reader)
+			}(conn, resp)
+		}
+	}()
+
+	return lis.Addr().String(), func() int {
+		mut.Lock()
+		defer mut.Unlock()
+		return len(conns)
+	}
+}
+
+func TestStatsClient_MetaDump_Stop(t *testing.T) {
+	// the remaining keys of the dump are never sent
+	dumpResponse := metaDumpLine("key01", 1) + "\r\n" + metaDumpLine("key02", 1) + "\r\n"
+
+	t.Run("stop early closes the connection", func(t *testing.T) {
+		addr, numAccepted := startScriptServer(t, dumpResponse, buildResponse("STAT pid 123"))
+
+		c := New(addr)
+		defer func() { _ = c.Close() }()
+
+		var keys []string
+		err := c.MetaDump(context.Background(), MetaDumpOptions{}, func(key MetaDumpKey) bool {
+			keys = append(keys, key.Key)
+			return false
+		})
+		assert.Equal(t, nil, err)
+		assert.Equal(t, []string{"key01"}, keys)
+
+		general, err := c.GetGeneralStats()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, uint64(123), general.PID)
+		assert.Equal(t, 2, numAccepted())
+	})
+
+	t.Run("context cancelled between keys", func(t *testing.T) {
+		addr, numAccepted := startScriptServer(t, dumpResponse, buildResponse("STAT pid 123"))
+
+		c := New(addr)
+		defer func() { _ = c.Close() }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var keys []string
+		err := c.MetaDump(ctx, MetaDumpOptions{}, func(key MetaDumpKey) bool {
+			keys = append(keys, key.Key)
+			cancel()
+			return true
+		})
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, []string{"key01"}, keys)
+
+		general, err := c.GetGeneralStats()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, uint64(123), general.PID)
+		assert.Equal(t, 2, numAccepted())
+	})
+
+	t.Run("context cancelled while waiting for keys", func(t *testing.T) {
+		addr, _ := startScriptServer(t, dumpResponse)
+
+		c := New(addr, WithNetConnOptions(netconn.WithReadTimeout(time.Minute)))
+		defer func() { _ = c.Close() }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		var keys []string
+		err := c.MetaDump(ctx, MetaDumpOptions{}, func(key MetaDumpKey) bool {
+			keys = append(keys, key.Key)
+			return true
+		})
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, []string{"key01", "key02"}, keys)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("context already cancelled", func(t *testing.T) {
+		addr, numAccepted := startScriptServer(t, dumpResponse)
+
+		c := New(addr)
+		defer func() { _ = c.Close() }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := c.MetaDump(ctx, MetaDumpOptions{}, func(key MetaDumpKey) bool {
+			return true
+		})
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, 1, numAccepted())
+	})
+}
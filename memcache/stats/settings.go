@@ -0,0 +1,114 @@
+package stats
+
+// SettingsStats is the result of the "stats settings" command.
+// Settings that are not known by this library are stored in Unknown
+type SettingsStats struct {
+	MaxBytes        uint64
+	MaxConns        uint64
+	TCPPort         uint64
+	UDPPort         uint64
+	Verbosity       uint64
+	Oldest          uint64
+	Evictions       bool
+	GrowthFactor    float64
+	ChunkSize       uint64
+	NumThreads      uint64
+	CasEnabled      bool
+	TCPBacklog      uint64
+	BindingProtocol string
+	ItemSizeMax     uint64
+	SlabChunkMax    uint64
+	HashAlgorithm   string
+	FlushEnabled    bool
+	DumpEnabled     bool
+	TrackSizes      bool
+	IdleTimeout     uint64
+
+	AuthEnabledSASL  bool
+	AuthEnabledASCII bool
+	SSLEnabled       bool
+
+	SlabReassign bool
+	SlabAutomove uint64
+
+	LRUCrawler          bool
+	LRUCrawlerSleep     uint64
+	LRUCrawlerToCrawl   uint64
+	LRUMaintainerThread bool
+	LRUSegmented        bool
+	HotLRUPct           uint64
+	WarmLRUPct          uint64
+	HotMaxFactor        float64
+	WarmMaxFactor       float64
+	TempLRU             bool
+	TemporaryTTL        uint64
+	TailRepairTime      uint64
+
+	// Unknown contains the settings with keys not in the list above, nil if there is no such key
+	Unknown map[string]string
+}
+
+var settingsStatsFields = statFields[SettingsStats]{
+	"maxbytes":         func(s *SettingsStats) any { return &s.MaxBytes },
+	"maxconns":         func(s *SettingsStats) any { return &s.MaxConns },
+	"tcpport":          func(s *SettingsStats) any { return &s.TCPPort },
+	"udpport":          func(s *SettingsStats) any { return &s.UDPPort },
+	"verbosity":        func(s *SettingsStats) any { return &s.Verbosity },
+	"oldest":           func(s *SettingsStats) any { return &s.Oldest },
+	"evictions":        func(s *SettingsStats) any { return &s.Evictions },
+	"growth_factor":    func(s *SettingsStats) any { return &s.GrowthFactor },
+	"chunk_size":       func(s *SettingsStats) any { return &s.ChunkSize },
+	"num_threads":      func(s *SettingsStats) any { return &s.NumThreads },
+	"cas_enabled":      func(s *SettingsStats) any { return &s.CasEnabled },
+	"tcp_backlog":      func(s *SettingsStats) any { return &s.TCPBacklog },
+	"binding_protocol": func(s *SettingsStats) any { return &s.BindingProtocol },
+	"item_size_max":    func(s *SettingsStats) any { return &s.ItemSizeMax },
+	"slab_chunk_max":   func(s *SettingsStats) any { return &s.SlabChunkMax },
+	"hash_algorithm":   func(s *SettingsStats) any { return &s.HashAlgorithm },
+	"flush_enabled":    func(s *SettingsStats) any { return &s.FlushEnabled },
+	"dump_enabled":     func(s *SettingsStats) any { return &s.DumpEnabled },
+	"track_sizes":      func(s *SettingsStats) any { return &s.TrackSizes },
+	"idle_timeout":     func(s *SettingsStats) any { return &s.IdleTimeout },
+
+	"auth_enabled_sasl":  func(s *SettingsStats) any { return &s.AuthEnabledSASL },
+	"auth_enabled_ascii": func(s *SettingsStats) any { return &s.AuthEnabledASCII },
+	"ssl_enabled":        func(s *SettingsStats) any { return &s.SSLEnabled },
+
+	"slab_reassign": func(s *SettingsStats) any { return &s.SlabReassign },
+	"slab_automove": func(s *SettingsStats) any { return &s.SlabAutomove },
+
+	"lru_crawler":           func(s *SettingsStats) any { return &s.LRUCrawler },
+	"lru_crawler_sleep":     func(s *SettingsStats) any { return &s.LRUCrawlerSleep },
+	"lru_crawler_tocrawl":   func(s *SettingsStats) any { return &s.LRUCrawlerToCrawl },
+	"lru_maintainer_thread": func(s *SettingsStats) any { return &s.LRUMaintainerThread },
+	"lru_segmented":         func(s *SettingsStats) any { return &s.LRUSegmented },
+	"hot_lru_pct":           func(s *SettingsStats) any { return &s.HotLRUPct },
+	"warm_lru_pct":          func(s *SettingsStats) any { return &s.WarmLRUPct },
+	"hot_max_factor":        func(s *SettingsStats) any { return &s.HotMaxFactor },
+	"warm_max_factor":       func(s *SettingsStats) any { return &s.WarmMaxFactor },
+	"temp_lru":              func(s *SettingsStats) any { return &s.TempLRU },
+	"temporary_ttl":         func(s *SettingsStats) any { return &s.TemporaryTTL },
+	"tail_repair_time":      func(s *SettingsStats) any { return &s.TailRepairTime },
+}
+
+// GetSettingsStats ...
+func (c *Client) GetSettingsStats() (SettingsStats, error) {
+	return doRequest(c, c.getSettingsStatsUnsafe)
+}
+
+func (c *Client) getSettingsStatsUnsafe() (SettingsStats, error) {
+	if err := c.writeCommand("stats settings\r\n"); err != nil {
+		return SettingsStats{}, err
+	}
+
+	result := SettingsStats{}
+
+	for c.parser.next() {
+		err := settingsStatsFields.parse(&result, &result.Unknown, c.parser.getItem())
+		if err != nil {
+			return SettingsStats{}, err
+		}
+	}
+
+	return result, c.parser.getError()
+}
@@ -0,0 +1,53 @@
+package stats
+
+import (
+	"strconv"
+)
+
+// SizesStats is the result of the "stats sizes" command, the histogram of item sizes.
+// The histogram is only available when memcached is started with "-o track_sizes"
+type SizesStats struct {
+	Enabled bool
+	Sizes   []SizeCount // in the order returned by memcached
+}
+
+// SizeCount is the number of items with sizes in the 32 bytes bucket ending at Size
+type SizeCount struct {
+	Size  uint64
+	Count uint64
+}
+
+// GetSizesStats ...
+func (c *Client) GetSizesStats() (SizesStats, error) {
+	return doRequest(c, c.getSizesStatsUnsafe)
+}
+
+func (c *Client) getSizesStatsUnsafe() (SizesStats, error) {
+	if err := c.writeCommand("stats sizes\r\n"); err != nil {
+		return SizesStats{}, err
+	}
+
+	result := SizesStats{
+		Enabled: true,
+	}
+
+	for c.parser.next() {
+		item := c.parser.getItem()
+		if item.key == "sizes_status" {
+			result.Enabled = item.value != "disabled"
+			continue
+		}
+
+		size, err := strconv.ParseUint(item.key, 10, 64)
+		if err != nil {
+			return SizesStats{}, err
+		}
+		count, err := strconv.ParseUint(item.value, 10, 64)
+		if err != nil {
+			return SizesStats{}, err
+		}
+		result.Sizes = append(result.Sizes, SizeCount{Size: size, Count: count})
+	}
+
+	return result, c.parser.getError()
+}
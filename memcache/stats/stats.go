@@ -6,21 +6,44 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/QuangTung97/go-memcache/memcache/netconn"
 )
 
 // Client is a client for statistics information & support dump all keys
 // checkout https://github.com/memcached/memcached/blob/master/doc/protocol.txt
-// for more information
+// for more information.
+//
+// It is safe for concurrent use, requests are serialized on a single connection.
+// When the connection is broken (or a response can NOT be parsed), it is closed
+// and a new connection is dialed lazily by the next request, with backoff between failed dials.
 type Client struct {
+	addr string
+	conf *dialConfig
+
+	mut sync.Mutex
+
+	closed    bool
+	connected bool
+
 	nc      netconn.NetConn
 	scanner *bufio.Scanner
 	parser  *statsParser
+
+	dialErr      error
+	dialAttempt  int
+	nextDialTime time.Time
+
+	// keepConn is set when a failed request has read its whole response,
+	// such that the connection can still be used
+	keepConn bool
 }
 
 type dialConfig struct {
-	errorLogger func(err error)
+	errorLogger   func(err error)
+	backoffPolicy netconn.BackoffPolicy
 
 	connOptions []netconn.Option
 }
@@ -38,82 +61,143 @@ func WithErrorLogger(logger func(err error)) Option {
 // WithNetConnOptions ...
 func WithNetConnOptions(options ...netconn.Option) Option {
 	return func(conf *dialConfig) {
-		conf.connOptions = options
+		conf.connOptions = append(conf.connOptions, options...)
 	}
 }
 
-// New ...
+// WithDialFunc sets the dial function, e.g. for using PasswordAuth:
+//
+//	auth, err := netconn.NewPasswordAuth(username, password)
+//	stats.New(addr, stats.WithDialFunc(auth.GetDialFunc(net.DialTimeout)))
+func WithDialFunc(dialFunc netconn.DialFunc) Option {
+	return WithNetConnOptions(netconn.WithDialFunc(dialFunc))
+}
+
+// WithBackoffPolicy specifies the policy for computing the duration between failed dials,
+// requests in that duration return the last dial error without dialing.
+// Default is exponential backoff with full jitter, from 50ms up to 10 seconds
+func WithBackoffPolicy(policy netconn.BackoffPolicy) Option {
+	return func(conf *dialConfig) {
+		conf.backoffPolicy = policy
+	}
+}
+
+// ErrClientClosed is returned when calling methods of a closed Client
+var ErrClientClosed = NewError("client is closed")
+
+// New creates a stats client, **addr** can be a TCP address (host:port) or a unix domain socket (unix:///path).
+// The first connection is dialed right away, if it fails the error is logged and the next requests will redial
 func New(addr string, options ...Option) *Client {
 	conf := &dialConfig{
 		errorLogger: func(err error) {
 			log.Println("[ERROR] Dial memcache for stats with error:", err)
 		},
+		backoffPolicy: netconn.DefaultBackoffPolicy(),
 	}
 
 	for _, opt := range options {
 		opt(conf)
 	}
 
-	nc, err := netconn.DialNewConn(addr, conf.connOptions...)
-	if err != nil {
-		conf.errorLogger(err)
-		nc = netconn.ErrorNetConn(err)
+	c := &Client{
+		addr: addr,
+		conf: conf,
 	}
+	_ = c.dialUnsafe()
+	return c
+}
 
-	scanner := bufio.NewScanner(nc.Reader)
+func (c *Client) dialUnsafe() error {
+	nc, err := netconn.DialNewConn(c.addr, c.conf.connOptions...)
+	if err != nil {
+		c.conf.errorLogger(err)
 
-	return &Client{
-		nc:      nc,
-		scanner: scanner,
-		parser:  newStatsParser(scanner),
+		c.dialErr = err
+		c.nextDialTime = time.Now().Add(c.conf.backoffPolicy.Backoff(c.dialAttempt))
+		c.dialAttempt++
+		return err
 	}
+
+	c.dialErr = nil
+	c.dialAttempt = 0
+
+	c.connected = true
+	c.nc = nc
+	c.scanner = bufio.NewScanner(nc.Reader)
+	c.parser = newStatsParser(c.scanner)
+	return nil
 }
 
-// GeneralStats ...
-type GeneralStats struct {
-	PID    uint64
-	Uptime uint64
+func (c *Client) disconnectUnsafe() error {
+	if !c.connected {
+		return nil
+	}
+	c.connected = false
+	return c.nc.Closer.Close()
 }
 
-func (c *Client) writeCommand(cmd string) error {
-	_, err := c.nc.Writer.Write([]byte(cmd))
-	if err != nil {
-		return err
+// acquireConn locks the client and makes sure the connection is ready,
+// releaseConn must be called after the request if returning nil
+func (c *Client) acquireConn() error {
+	c.mut.Lock()
+
+	if c.closed {
+		c.mut.Unlock()
+		return ErrClientClosed
 	}
 
-	return c.nc.Writer.Flush()
+	if c.connected {
+		return nil
+	}
+
+	if time.Now().Before(c.nextDialTime) {
+		c.mut.Unlock()
+		return c.dialErr
+	}
+
+	if err := c.dialUnsafe(); err != nil {
+		c.mut.Unlock()
+		return err
+	}
+	return nil
 }
 
-// GetGeneralStats ...
-func (c *Client) GetGeneralStats() (GeneralStats, error) {
-	if err := c.writeCommand("stats\r\n"); err != nil {
-		return GeneralStats{}, err
+// releaseConn closes the connection if the request failed in the middle of its response, then unlocks the client
+func (c *Client) releaseConn(err error) {
+	if err != nil && !c.keepConn {
+		_ = c.disconnectUnsafe()
 	}
+	c.keepConn = false
+	c.mut.Unlock()
+}
 
-	result := GeneralStats{}
+// do runs **fn** with the connection ready, the requests of a client are serialized
+func (c *Client) do(fn func() error) (err error) {
+	if err := c.acquireConn(); err != nil {
+		return err
+	}
+	defer func() { c.releaseConn(err) }()
 
-	for c.parser.next() {
-		item := c.parser.getItem()
-		switch item.key {
-		case "pid":
-			pid, err := strconv.ParseUint(item.value, 10, 64)
-			if err != nil {
-				return GeneralStats{}, err
-			}
-			result.PID = pid
+	return fn()
+}
 
-		case "uptime":
-			uptime, err := strconv.ParseUint(item.value, 10, 64)
-			if err != nil {
-				return GeneralStats{}, err
-			}
-			result.Uptime = uptime
+func doRequest[T any](c *Client, fn func() (T, error)) (T, error) {
+	var result T
+	err := c.do(func() error {
+		var err error
+		result, err = fn()
+		return err
+	})
+	return result, err
+}
 
-		default:
-		}
+func (c *Client) writeCommand(cmd string) error {
+	_, err := c.nc.Writer.Write([]byte(cmd))
+	if err != nil {
+		return err
 	}
 
-	return result, c.parser.getError()
+	return c.nc.Writer.Flush()
 }
 
 // SingleSlabStats ...
@@ -198,6 +282,10 @@ func (s *SlabsStats) parseSlabStat(item statItem) error {
 
 // GetSlabsStats ...
 func (c *Client) GetSlabsStats() (SlabsStats, error) {
+	return doRequest(c, c.getSlabsStatsUnsafe)
+}
+
+func (c *Client) getSlabsStatsUnsafe() (SlabsStats, error) {
 	if err := c.writeCommand("stats slabs\r\n"); err != nil {
 		return SlabsStats{}, err
 	}
@@ -382,6 +470,10 @@ func (s *SlabItemsStats) parseSlabItemStat(item statItem) error {
 
 // GetSlabItemsStats ...
 func (c *Client) GetSlabItemsStats() (SlabItemsStats, error) {
+	return doRequest(c, c.getSlabItemsStatsUnsafe)
+}
+
+func (c *Client) getSlabItemsStatsUnsafe() (SlabItemsStats, error) {
 	if err := c.writeCommand("stats items\r\n"); err != nil {
 		return SlabItemsStats{}, err
 	}
@@ -480,33 +572,19 @@ func parseMetaDumpKey(line string) (MetaDumpKey, error) {
 	return result, nil
 }
 
-// MetaDumpAll ...
-func (c *Client) MetaDumpAll(scanFunc func(key MetaDumpKey)) error {
-	if err := c.writeCommand("lru_crawler metadump all\r\n"); err != nil {
-		return err
-	}
-
-	for c.scanner.Scan() {
-		line := c.scanner.Text()
-		if line == "END" {
-			return nil
-		}
-
-		key, err := parseMetaDumpKey(line)
-		if err != nil {
-			return err
-		}
-		scanFunc(key)
-	}
-	return c.scanner.Err()
-}
-
 type statItem struct {
 	key   string
 	value string
 }
 
-// Close ...
+// Close closes the connection, requests after closed return ErrClientClosed
 func (c *Client) Close() error {
-	return c.nc.Closer.Close()
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.disconnectUnsafe()
 }
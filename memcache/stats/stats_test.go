@@ -29,6 +29,10 @@ type connTest struct {
 	writeTimeout time.Duration
 }
 
+func (c *connTest) SetDeadline(time.Time) error {
+	return nil
+}
+
 func (c *connTest) SetReadDeadline(t time.Time) error {
 	c.readTimeout = t.Sub(time.Now())
 	return nil
@@ -102,6 +106,126 @@ func TestStatsClient(t *testing.T) {
 		assert.Equal(t, []byte("stats\r\n"), c.nc.writeBytes)
 	})
 
+	t.Run("general-full", func(t *testing.T) {
+		input := buildResponse(
+			"STAT pid 1",
+			"STAT uptime 3512",
+			"STAT time 1700000000",
+			"STAT version 1.6.21",
+			"STAT libevent 2.1.12-stable",
+			"STAT pointer_size 64",
+			"STAT rusage_user 0.410000",
+			"STAT rusage_system 1.250312",
+			"STAT max_connections 1024",
+			"STAT curr_connections 3",
+			"STAT total_connections 120",
+			"STAT rejected_connections 2",
+			"STAT connection_structures 5",
+			"STAT cmd_get 1000",
+			"STAT cmd_set 300",
+			"STAT get_hits 800",
+			"STAT get_misses 200",
+			"STAT get_expired 7",
+			"STAT bytes_read 123456",
+			"STAT bytes_written 654321",
+			"STAT limit_maxbytes 67108864",
+			"STAT accepting_conns 1",
+			"STAT threads 4",
+			"STAT hash_is_expanding 0",
+			"STAT slab_reassign_running 0",
+			"STAT lru_crawler_running 1",
+			"STAT bytes 4096",
+			"STAT curr_items 21",
+			"STAT total_items 300",
+			"STAT expired_unfetched 3",
+			"STAT evicted_unfetched 4",
+			"STAT evictions 5",
+			"STAT reclaimed 6",
+		)
+		c := newClientTest(t, bytes.NewBuffer([]byte(input)))
+
+		general, err := c.client.GetGeneralStats()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, GeneralStats{
+			PID:          1,
+			Uptime:       3512,
+			Time:         1700000000,
+			Version:      "1.6.21",
+			Libevent:     "2.1.12-stable",
+			PointerSize:  64,
+			RusageUser:   0.41,
+			RusageSystem: 1.250312,
+
+			MaxConnections:       1024,
+			CurrConnections:      3,
+			TotalConnections:     120,
+			RejectedConnections:  2,
+			ConnectionStructures: 5,
+
+			CmdGet:     1000,
+			CmdSet:     300,
+			GetHits:    800,
+			GetMisses:  200,
+			GetExpired: 7,
+
+			BytesRead:      123456,
+			BytesWritten:   654321,
+			LimitMaxbytes:  67108864,
+			AcceptingConns: true,
+			Threads:        4,
+
+			LRUCrawlerRunning: true,
+
+			Bytes:            4096,
+			CurrItems:        21,
+			TotalItems:       300,
+			ExpiredUnfetched: 3,
+			EvictedUnfetched: 4,
+			Evictions:        5,
+			Reclaimed:        6,
+		}, general)
+	})
+
+	t.Run("general-unknown-keys", func(t *testing.T) {
+		input := buildResponse(
+			"STAT pid 12",
+			"STAT new_stat_of_future_version 55",
+			"STAT another_one abc",
+		)
+		c := newClientTest(t, bytes.NewBuffer([]byte(input)))
+
+		general, err := c.client.GetGeneralStats()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, GeneralStats{
+			PID: 12,
+			Unknown: map[string]string{
+				"new_stat_of_future_version": "55",
+				"another_one":                "abc",
+			},
+		}, general)
+	})
+
+	t.Run("general-invalid-bool", func(t *testing.T) {
+		input := buildResponse(
+			"STAT accepting_conns 2",
+		)
+		c := newClientTest(t, bytes.NewBuffer([]byte(input)))
+
+		general, err := c.client.GetGeneralStats()
+		assert.Equal(t, NewError("invalid boolean value: 2"), err)
+		assert.Equal(t, GeneralStats{}, general)
+	})
+
+	t.Run("general-invalid-float", func(t *testing.T) {
+		input := buildResponse(
+			"STAT rusage_user 0:41",
+		)
+		c := newClientTest(t, bytes.NewBuffer([]byte(input)))
+
+		_, err := c.client.GetGeneralStats()
+		assert.Error(t, err)
+	})
+
 	t.Run("general-write-error", func(t *testing.T) {
 		input := buildResponse(
 			"STAT pid",
@@ -312,6 +436,201 @@ func TestStatsClient(t *testing.T) {
 	})
 }
 
+func TestStatsClient_Settings_Conns_Sizes_Extstore(t *testing.T) {
+	t.Run("settings", func(t *testing.T) {
+		input := buildResponse(
+			"STAT maxbytes 67108864",
+			"STAT maxconns 1024",
+			"STAT tcpport 11211",
+			"STAT inter NULL",
+			"STAT evictions on",
+			"STAT growth_factor 1.25",
+			"STAT cas_enabled yes",
+			"STAT binding_protocol auto-negotiate",
+			"STAT item_size_max 1048576",
+			"STAT hash_algorithm murmur3",
+			"STAT lru_crawler yes",
+			"STAT lru_crawler_sleep 100",
+			"STAT lru_crawler_tocrawl 0",
+			"STAT hot_max_factor 0.20",
+			"STAT temp_lru no",
+			"STAT slab_automove_ratio 0.80",
+		)
+		c := newClientTest(t, bytes.NewBuffer([]byte(input)))
+
+		settings, err := c.client.GetSettingsStats()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, SettingsStats{
+			MaxBytes:        67108864,
+			MaxConns:        1024,
+			TCPPort:         11211,
+			Evictions:       true,
+			GrowthFactor:    1.25,
+			CasEnabled:      true,
+			BindingProtocol: "auto-negotiate",
+			ItemSizeMax:     1048576,
+			HashAlgorithm:   "murmur3",
+			LRUCrawler:      true,
+			LRUCrawlerSleep: 100,
+			HotMaxFactor:    0.2,
+			Unknown: map[string]string{
+				"inter":               "NULL",
+				"slab_automove_ratio": "0.80",
+			},
+		}, settings)
+
+		assert.Equal(t, []byte("stats settings\r\n"), c.nc.writeBytes)
+	})
+
+	t.Run("settings-invalid-bool", func(t *testing.T) {
+		input := buildResponse(
+			"STAT evictions maybe",
+		)
+		c := newClientTest(t, bytes.NewBuffer([]byte(input)))
+
+		settings, err := c.client.GetSettingsStats()
+		assert.Equal(t, NewError("invalid boolean value: maybe"), err)
+		assert.Equal(t, SettingsStats{}, settings)
+	})
+
+	t.Run("conns", func(t *testing.T) {
+		input := buildResponse(
+			"STAT 26:addr tcp:0.0.0.0:11211",
+			"STAT 26:state conn_listening",
+			"STAT 26:secs_since_last_cmd 120",
+			"STAT 30:addr tcp:127.0.0.1:54321",
+			"STAT 30:listen_addr tcp:0.0.0.0:11211",
+			"STAT 30:state conn_parse_cmd",
+			"STAT 30:secs_since_last_cmd 0",
+			"STAT 30:some_new_stat 12",
+		)
+		c := newClientTest(t, bytes.NewBuffer([]byte(input)))
+
+		conns, err := c.client.GetConnsStats()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, ConnsStats{
+			FDs: []uint32{26, 30},
+			Conns: map[uint32]SingleConnStats{
+				26: {
+					Addr:             "tcp:0.0.0.0:11211",
+					State:            "conn_listening",
+					SecsSinceLastCmd: 120,
+				},
+				30: {
+					Addr:       "tcp:127.0.0.1:54321",
+					ListenAddr: "tcp:0.0.0.0:11211",
+					State:      "conn_parse_cmd",
+				},
+			},
+		}, conns)
+
+		assert.Equal(t, []byte("stats conns\r\n"), c.nc.writeBytes)
+	})
+
+	t.Run("conns-missing-fd", func(t *testing.T) {
+		input := buildResponse(
+			"STAT addr tcp:127.0.0.1:54321",
+		)
+		c := newClientTest(t, bytes.NewBuffer([]byte(input)))
+
+		conns, err := c.client.GetConnsStats()
+		assert.Equal(t, NewError("missing id of stat key: addr"), err)
+		assert.Equal(t, ConnsStats{}, conns)
+	})
+
+	t.Run("conns-fd-not-number", func(t *testing.T) {
+		input := buildResponse(
+			"STAT x:addr tcp:127.0.0.1:54321",
+		)
+		c := newClientTest(t, bytes.NewBuffer([]byte(input)))
+
+		_, err := c.client.GetConnsStats()
+		assert.Error(t, err)
+	})
+
+	t.Run("sizes", func(t *testing.T) {
+		input := buildResponse(
+			"STAT 96 12",
+			"STAT 128 3",
+		)
+		c := newClientTest(t, bytes.NewBuffer([]byte(input)))
+
+		sizes, err := c.client.GetSizesStats()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, SizesStats{
+			Enabled: true,
+			Sizes: []SizeCount{
+				{Size: 96, Count: 12},
+				{Size: 128, Count: 3},
+			},
+		}, sizes)
+
+		assert.Equal(t, []byte("stats sizes\r\n"), c.nc.writeBytes)
+	})
+
+	t.Run("sizes-disabled", func(t *testing.T) {
+		input := buildResponse(
+			"STAT sizes_status disabled",
+		)
+		c := newClientTest(t, bytes.NewBuffer([]byte(input)))
+
+		sizes, err := c.client.GetSizesStats()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, SizesStats{}, sizes)
+	})
+
+	t.Run("sizes-invalid-count", func(t *testing.T) {
+		input := buildResponse(
+			"STAT 96 x",
+		)
+		c := newClientTest(t, bytes.NewBuffer([]byte(input)))
+
+		sizes, err := c.client.GetSizesStats()
+		assert.Error(t, err)
+		assert.Equal(t, SizesStats{}, sizes)
+	})
+
+	t.Run("extstore", func(t *testing.T) {
+		input := buildResponse(
+			"STAT page_count 2",
+			"STAT 0:version 5",
+			"STAT 0:bytes 6710886",
+			"STAT 0:bucket 0",
+			"STAT 0:free_bucket 0",
+			"STAT 1:version 0",
+			"STAT 1:bytes 0",
+			"STAT 1:bucket 1",
+			"STAT 1:free_bucket 1",
+			"STAT page_size 67108864",
+		)
+		c := newClientTest(t, bytes.NewBuffer([]byte(input)))
+
+		extstore, err := c.client.GetExtstoreStats()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, ExtstoreStats{
+			PageCount: 2,
+			PageIDs:   []uint32{0, 1},
+			Pages: map[uint32]ExtstorePageStats{
+				0: {Version: 5, Bytes: 6710886},
+				1: {Bucket: 1, FreeBucket: 1},
+			},
+			Unknown: map[string]string{
+				"page_size": "67108864",
+			},
+		}, extstore)
+
+		assert.Equal(t, []byte("stats extstore\r\n"), c.nc.writeBytes)
+	})
+
+	t.Run("extstore-not-enabled", func(t *testing.T) {
+		c := newClientTest(t, strings.NewReader("ERROR\r\n"))
+
+		extstore, err := c.client.GetExtstoreStats()
+		assert.Equal(t, NewError("line not begin with STAT"), err)
+		assert.Equal(t, ExtstoreStats{}, extstore)
+	})
+}
+
 func TestMemcache__Connect_Error(t *testing.T) {
 	t.Run("without-logger", func(t *testing.T) {
 		c := New("localhost:2288")
@@ -337,6 +656,25 @@ func TestMemcache__Connect_Error(t *testing.T) {
 	})
 }
 
+func TestMemcache__Unix_Socket_Address(t *testing.T) {
+	var dialNetwork, dialAddress string
+	dialFunc := func(network, address string, timeout time.Duration) (net.Conn, error) {
+		dialNetwork = network
+		dialAddress = address
+		return &connTest{reader: strings.NewReader(buildResponse("STAT pid 1234", "END"))}, nil
+	}
+
+	c := New("unix:///var/run/memcached.sock", WithNetConnOptions(netconn.WithDialFunc(dialFunc)))
+	defer func() { _ = c.Close() }()
+
+	assert.Equal(t, "unix", dialNetwork)
+	assert.Equal(t, "/var/run/memcached.sock", dialAddress)
+
+	stats, err := c.GetGeneralStats()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(1234), stats.PID)
+}
+
 func TestParseMetaDumpKey(t *testing.T) {
 	t.Run("normal", func(t *testing.T) {
 		key, err := parseMetaDumpKey(`key=KEY01 exp=-1 la=1675839370 cas=34234 fetch=yes cls=5 size=76`)
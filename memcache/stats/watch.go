@@ -0,0 +1,263 @@
+package stats
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/QuangTung97/go-memcache/memcache/netconn"
+)
+
+// WatchType is a type of log streams of the "watch" command
+type WatchType string
+
+const (
+	// WatchFetchers streams the get commands (type=item_get)
+	WatchFetchers WatchType = "fetchers"
+
+	// WatchMutations streams the set & delete commands (type=item_store, type=item_delete)
+	WatchMutations WatchType = "mutations"
+
+	// WatchEvictions streams the evicted items (type=eviction)
+	WatchEvictions WatchType = "evictions"
+
+	// WatchConnEvents streams the opened & closed connections (type=conn_new, type=conn_close)
+	WatchConnEvents WatchType = "connevents"
+
+	// WatchDeletions streams the deleted items (type=deleted)
+	WatchDeletions WatchType = "deletions"
+)
+
+// WatchEvent is a log line of the "watch" command, for example:
+//
+//	ts=1675839370.112233 gid=7 type=item_get key=KEY01 status=found clsid=1 cfd=20 size=3
+//
+// Attributes that can NOT be parsed are kept only in Fields
+type WatchEvent struct {
+	Time time.Time // ts
+	GID  uint64    // increasing log id
+
+	Type    string // e.g. item_get, item_store, eviction, conn_new, conn_close
+	Key     string // url decoded
+	Status  string // e.g. found, not_found, stored
+	ClassID uint32 // clsid
+	Size    uint64
+	TTL     int64
+
+	// Skipped is the number of log lines dropped by the server because the watcher can NOT keep up
+	Skipped uint64
+
+	// Fields contains all the attributes of the log line, including the ones above
+	Fields map[string]string
+
+	Raw string
+}
+
+func parseWatchTime(value string) (time.Time, bool) {
+	secsStr, fraction, _ := strings.Cut(value, ".")
+
+	secs, err := strconv.ParseInt(secsStr, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var nanos int64
+	if fraction != "" {
+		if len(fraction) > 9 {
+			fraction = fraction[:9]
+		}
+		fraction += strings.Repeat("0", 9-len(fraction))
+		nanos, err = strconv.ParseInt(fraction, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+	}
+	return time.Unix(secs, nanos), true
+}
+
+func (e *WatchEvent) setField(name string, value string) {
+	switch name {
+	case "ts":
+		if t, ok := parseWatchTime(value); ok {
+			e.Time = t
+		}
+	case "gid":
+		e.GID, _ = strconv.ParseUint(value, 10, 64)
+	case "type":
+		e.Type = value
+	case "key":
+		if key, err := url.PathUnescape(value); err == nil {
+			e.Key = key
+		}
+	case "status":
+		e.Status = value
+	case "clsid":
+		if id, err := strconv.ParseUint(value, 10, 32); err == nil {
+			e.ClassID = uint32(id)
+		}
+	case "size":
+		e.Size, _ = strconv.ParseUint(value, 10, 64)
+	case "ttl":
+		e.TTL, _ = strconv.ParseInt(value, 10, 64)
+	case "skipped":
+		e.Skipped, _ = strconv.ParseUint(value, 10, 64)
+	}
+}
+
+func parseWatchEvent(line string) WatchEvent {
+	event := WatchEvent{
+		Fields: map[string]string{},
+		Raw:    line,
+	}
+	for _, kv := range strings.Fields(line) {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		event.Fields[name] = value
+		event.setField(name, value)
+	}
+	return event
+}
+
+// Watcher delivers the events of a "watch" command, see Client.Watch
+type Watcher struct {
+	events chan WatchEvent
+	err    error
+}
+
+// Events returns the channel of events, closed when the context is cancelled or the connection is broken
+func (w *Watcher) Events() <-chan WatchEvent {
+	return w.events
+}
+
+// Err returns the reason the events channel was closed, the error of the context if it was cancelled.
+// Must only be called after the events channel is closed
+func (w *Watcher) Err() error {
+	return w.err
+}
+
+func formatWatchCommand(types []WatchType) string {
+	var b strings.Builder
+	_, _ = b.WriteString("watch")
+	for _, t := range types {
+		_ = b.WriteByte(' ')
+		_, _ = b.WriteString(string(t))
+	}
+	_, _ = b.WriteString("\r\n")
+	return b.String()
+}
+
+// Watch issues the "watch" command on a new dedicated connection (with the same options of the client)
+// and delivers the log lines as events, until **ctx** is cancelled. Empty **types** means the default
+// of the server (fetchers). Read timeouts are ignored on this connection, since the log lines can be rare.
+//
+// Events are dropped by the server if they are NOT consumed fast enough, see WatchEvent.Skipped
+func (c *Client) Watch(ctx context.Context, types ...WatchType) (*Watcher, error) {
+	c.mut.Lock()
+	closed := c.closed
+	c.mut.Unlock()
+	if closed {
+		return nil, ErrClientClosed
+	}
+
+	nc, err := netconn.DialNewConn(c.addr, c.conf.connOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	readDone := make(chan struct{})
+	go func() {
+		// for unblocking the reading goroutine
+		select {
+		case <-ctx.Done():
+		case <-readDone:
+		}
+		_ = nc.Closer.Close()
+	}()
+
+	scanner, err := startWatch(nc, types)
+	if err != nil {
+		close(readDone)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	w := &Watcher{
+		events: make(chan WatchEvent, 128),
+	}
+
+	go func() {
+		defer close(w.events)
+		defer close(readDone)
+
+		w.err = readWatchEvents(ctx, scanner, w.events)
+		if ctx.Err() != nil {
+			w.err = ctx.Err()
+		}
+	}()
+
+	return w, nil
+}
+
+func startWatch(nc netconn.NetConn, types []WatchType) (*bufio.Scanner, error) {
+	cmd := formatWatchCommand(types)
+	if _, err := nc.Writer.Write([]byte(cmd)); err != nil {
+		return nil, err
+	}
+	if err := nc.Writer.Flush(); err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(noDeadlineReader{reader: nc.Reader})
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	if line := scanner.Text(); line != "OK" {
+		return nil, newReplyError(cmd, line)
+	}
+	return scanner, nil
+}
+
+// noDeadlineReader retries the reads that exceeded the read deadline, the connection is closed to stop reading
+type noDeadlineReader struct {
+	reader io.Reader
+}
+
+func (r noDeadlineReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.reader.Read(p)
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func readWatchEvents(ctx context.Context, scanner *bufio.Scanner, events chan<- WatchEvent) error {
+	for scanner.Scan() {
+		select {
+		case events <- parseWatchEvent(scanner.Text()):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return io.EOF
+}
@@ -0,0 +1,227 @@
+package stats
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/QuangTung97/go-memcache/memcache/netconn"
+)
+
+func TestParseWatchEvent(t *testing.T) {
+	t.Run("item get", func(t *testing.T) {
+		line := "ts=1675839370.112233 gid=7 type=item_get key=KEY01%3A40 status=found clsid=1 cfd=20 size=3"
+		event := parseWatchEvent(line)
+		assert.Equal(t, WatchEvent{
+			Time:    time.Unix(1675839370, 112233000),
+			GID:     7,
+			Type:    "item_get",
+			Key:     "KEY01:40",
+			Status:  "found",
+			ClassID: 1,
+			Size:    3,
+			Fields: map[string]string{
+				"ts":     "1675839370.112233",
+				"gid":    "7",
+				"type":   "item_get",
+				"key":    "KEY01%3A40",
+				"status": "found",
+				"clsid":  "1",
+				"cfd":    "20",
+				"size":   "3",
+			},
+			Raw: line,
+		}, event)
+	})
+
+	t.Run("item store", func(t *testing.T) {
+		event := parseWatchEvent(
+			"ts=1675839370.5 gid=8 type=item_store key=KEY02 status=stored cmd=set ttl=-1 clsid=2 cfd=20 size=70",
+		)
+		assert.Equal(t, time.Unix(1675839370, 500000000), event.Time)
+		assert.Equal(t, "item_store", event.Type)
+		assert.Equal(t, int64(-1), event.TTL)
+		assert.Equal(t, "set", event.Fields["cmd"])
+	})
+
+	t.Run("skipped", func(t *testing.T) {
+		event := parseWatchEvent("ts=1675839370.000001 gid=9 skipped=25")
+		assert.Equal(t, uint64(25), event.Skipped)
+		assert.Equal(t, "", event.Type)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		event := parseWatchEvent("ts=abc gid=x type=item_get key=a%zz clsid=-1 noequal")
+		assert.Equal(t, time.Time{}, event.Time)
+		assert.Equal(t, uint64(0), event.GID)
+		assert.Equal(t, "item_get", event.Type)
+		assert.Equal(t, "", event.Key)
+		assert.Equal(t, uint32(0), event.ClassID)
+		assert.Equal(t, "a%zz", event.Fields["key"])
+	})
+}
+
+// watchServerTest replies the watch command on the server side of a net.Pipe,
+// then writes the log lines sent to its channel
+type watchServerTest struct {
+	reply string
+	lines chan string
+	cmd   chan string
+}
+
+func newWatchServerTest(reply string) *watchServerTest {
+	return &watchServerTest{
+		reply: reply,
+		lines: make(chan string),
+		cmd:   make(chan string, 1),
+	}
+}
+
+func (s *watchServerTest) dial(_, _ string, _ time.Duration) (net.Conn, error) {
+	client, server := net.Pipe()
+	go func() {
+		defer func() { _ = server.Close() }()
+
+		scanner := bufio.NewScanner(server)
+		if !scanner.Scan() {
+			return
+		}
+		s.cmd <- scanner.Text()
+
+		if _, err := server.Write([]byte(s.reply + "\r\n")); err != nil {
+			return
+		}
+
+		for line := range s.lines {
+			if _, err := server.Write([]byte(line + "\r\n")); err != nil {
+				return
+			}
+		}
+	}()
+	return client, nil
+}
+
+func TestClient_Watch(t *testing.T) {
+	t.Run("events until cancelled", func(t *testing.T) {
+		server := newWatchServerTest("OK")
+
+		c := New("localhost:11211", WithDialFunc(server.dial))
+		defer func() { _ = c.Close() }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		w, err := c.Watch(ctx, WatchFetchers, WatchMutations)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "watch fetchers mutations", <-server.cmd)
+
+		server.lines <- "ts=1675839370.112233 gid=1 type=item_get key=KEY01 status=not_found clsid=0 cfd=20 size=0"
+		server.lines <- "ts=1675839370.112234 gid=2 type=item_store key=KEY01 status=stored cmd=set ttl=0 clsid=1"
+
+		event := <-w.Events()
+		assert.Equal(t, uint64(1), event.GID)
+		assert.Equal(t, "KEY01", event.Key)
+
+		event = <-w.Events()
+		assert.Equal(t, uint64(2), event.GID)
+		assert.Equal(t, "item_store", event.Type)
+
+		cancel()
+
+		numEvents := 0
+		for range w.Events() {
+			numEvents++
+		}
+		assert.Equal(t, 0, numEvents)
+		assert.Equal(t, context.Canceled, w.Err())
+
+		close(server.lines)
+	})
+
+	t.Run("events slower than read timeout", func(t *testing.T) {
+		server := newWatchServerTest("OK")
+
+		c := New("localhost:11211",
+			WithDialFunc(server.dial),
+			WithNetConnOptions(netconn.WithReadTimeout(5*time.Millisecond)),
+		)
+		defer func() { _ = c.Close() }()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		w, err := c.Watch(ctx)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "watch", <-server.cmd)
+
+		time.Sleep(30 * time.Millisecond)
+		server.lines <- "ts=1675839370.112233 gid=1 type=item_get key=KEY01 status=found clsid=1 cfd=20 size=3"
+
+		event := <-w.Events()
+		assert.Equal(t, uint64(1), event.GID)
+
+		cancel()
+		numEvents := 0
+		for range w.Events() {
+			numEvents++
+		}
+		assert.Equal(t, 0, numEvents)
+		assert.Equal(t, context.Canceled, w.Err())
+
+		close(server.lines)
+	})
+
+	t.Run("connection closed by server", func(t *testing.T) {
+		server := newWatchServerTest("OK")
+
+		c := New("localhost:11211", WithDialFunc(server.dial))
+		defer func() { _ = c.Close() }()
+
+		w, err := c.Watch(context.Background())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "watch", <-server.cmd)
+
+		server.lines <- "ts=1675839370.1 gid=1 type=conn_new rip=127.0.0.1 rport=4000 transport=tcp cfd=21"
+		close(server.lines)
+
+		var events []WatchEvent
+		for event := range w.Events() {
+			events = append(events, event)
+		}
+		assert.Equal(t, 1, len(events))
+		assert.Equal(t, "conn_new", events[0].Type)
+		assert.Equal(t, "127.0.0.1", events[0].Fields["rip"])
+		// net.Pipe returns io.ErrClosedPipe instead of io.EOF when setting the read deadline after closed by remote
+		assert.Error(t, w.Err())
+	})
+
+	t.Run("error reply", func(t *testing.T) {
+		server := newWatchServerTest("CLIENT_ERROR watch not enabled")
+		close(server.lines)
+
+		c := New("localhost:11211", WithDialFunc(server.dial))
+		defer func() { _ = c.Close() }()
+
+		w, err := c.Watch(context.Background(), WatchEvictions)
+		assert.Nil(t, w)
+		assert.Equal(t, &ReplyError{
+			Command: "watch evictions",
+			Kind:    "CLIENT_ERROR",
+			Message: "watch not enabled",
+		}, err)
+	})
+
+	t.Run("client closed", func(t *testing.T) {
+		server := newWatchServerTest("OK")
+
+		c := New("localhost:11211", WithDialFunc(server.dial))
+		assert.Equal(t, nil, c.Close())
+
+		_, err := c.Watch(context.Background())
+		assert.Equal(t, ErrClientClosed, err)
+	})
+}
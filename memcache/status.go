@@ -0,0 +1,167 @@
+package memcache
+
+import (
+	"time"
+)
+
+// ConnState is the state of a single TCP connection of the Client
+type ConnState int
+
+const (
+	// ConnStateConnected the connection is established and can be used for sending commands
+	ConnStateConnected ConnState = iota + 1
+
+	// ConnStateReconnecting the connection is broken and the background goroutine is trying to reconnect
+	ConnStateReconnecting
+
+	// ConnStateClosed the Client had been closed
+	ConnStateClosed
+
+	// ConnStateConnecting the first connection is not yet established (dialing or lazy dialing)
+	ConnStateConnecting
+)
+
+// String ...
+func (s ConnState) String() string {
+	switch s {
+	case ConnStateConnected:
+		return "connected"
+	case ConnStateReconnecting:
+		return "reconnecting"
+	case ConnStateClosed:
+		return "closed"
+	case ConnStateConnecting:
+		return "connecting"
+	default:
+		return "unknown"
+	}
+}
+
+// ConnStatus is a snapshot of the status of a single TCP connection
+type ConnStatus struct {
+	State ConnState
+
+	// LastError is the most recent error of the connection (dial error, network error or health check error),
+	// it is NOT cleared after the connection is reestablished
+	LastError error
+
+	// LastProbeTime is the time of the last successful health check, zero if there is no successful one yet
+	LastProbeTime time.Time
+
+	// ReconnectCount is the number of times the connection had been reestablished
+	ReconnectCount uint64
+
+	// InFlightCommands is the number of commands had been written to the connection but not yet received responses
+	InFlightCommands uint64
+}
+
+// ConnStateCallback is called on every state transition of a connection.
+// It is called from the background goroutines, so it should NOT block.
+type ConnStateCallback func(connIndex int, prev ConnState, status ConnStatus)
+
+// Status returns the status of every TCP connection in the pool, in the order of connection index
+func (c *Client) Status() []ConnStatus {
+	conns := c.getConns()
+	result := make([]ConnStatus, 0, len(conns))
+	for _, conn := range conns {
+		result = append(result, conn.status())
+	}
+	return result
+}
+
+func (c *clientConn) status() ConnStatus {
+	c.statusMut.Lock()
+	st := c.getStatusUnsafe()
+	c.statusMut.Unlock()
+
+	st.InFlightCommands = c.core.inFlightCount()
+	return st
+}
+
+func (c *clientConn) getStatusUnsafe() ConnStatus {
+	return ConnStatus{
+		State:          c.state,
+		LastError:      c.lastErr,
+		LastProbeTime:  c.lastProbeTime,
+		ReconnectCount: c.reconnectCount,
+	}
+}
+
+// updateStatus runs fn while holding the status lock, calls the state callback if the state is changed.
+// The status is not changed anymore after the connection is closed
+func (c *clientConn) updateStatus(fn func()) {
+	c.statusMut.Lock()
+	prev := c.state
+	if prev == ConnStateClosed {
+		c.statusMut.Unlock()
+		return
+	}
+	fn()
+	c.available.Store(c.state == ConnStateConnected || c.state == ConnStateConnecting)
+	st := c.getStatusUnsafe()
+	c.statusMut.Unlock()
+
+	if st.State == prev {
+		return
+	}
+
+	if c.stateNotifyCh != nil {
+		select {
+		case c.stateNotifyCh <- struct{}{}:
+		default:
+		}
+	}
+
+	if c.stateCallback == nil {
+		return
+	}
+	if c.core != nil {
+		st.InFlightCommands = c.core.inFlightCount()
+	}
+	c.stateCallback(c.index, prev, st)
+}
+
+func (c *clientConn) getState() ConnState {
+	c.statusMut.Lock()
+	defer c.statusMut.Unlock()
+	return c.state
+}
+
+func (c *clientConn) setConnected() {
+	c.updateStatus(func() {
+		c.state = ConnStateConnected
+	})
+}
+
+func (c *clientConn) setReconnected() {
+	c.updateStatus(func() {
+		c.state = ConnStateConnected
+		c.reconnectCount++
+	})
+}
+
+func (c *clientConn) setReconnecting(err error) {
+	c.updateStatus(func() {
+		c.state = ConnStateReconnecting
+		if err != nil {
+			c.lastErr = err
+		}
+	})
+}
+
+func (c *clientConn) setClosed() {
+	c.updateStatus(func() {
+		c.state = ConnStateClosed
+	})
+}
+
+// recordProbe stores the result of a health check
+func (c *clientConn) recordProbe(err error, now time.Time) {
+	c.updateStatus(func() {
+		if err != nil {
+			c.lastErr = err
+			return
+		}
+		c.lastProbeTime = now
+	})
+}
@@ -0,0 +1,191 @@
+package memcache
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnState_String(t *testing.T) {
+	assert.Equal(t, "connected", ConnStateConnected.String())
+	assert.Equal(t, "reconnecting", ConnStateReconnecting.String())
+	assert.Equal(t, "closed", ConnStateClosed.String())
+	assert.Equal(t, "connecting", ConnStateConnecting.String())
+	assert.Equal(t, "unknown", ConnState(0).String())
+}
+
+type stateTransition struct {
+	index  int
+	prev   ConnState
+	status ConnStatus
+}
+
+type stateRecorder struct {
+	mut         sync.Mutex
+	transitions []stateTransition
+}
+
+func (r *stateRecorder) callback(index int, prev ConnState, status ConnStatus) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	r.transitions = append(r.transitions, stateTransition{
+		index:  index,
+		prev:   prev,
+		status: status,
+	})
+}
+
+func (r *stateRecorder) getTransitions() []stateTransition {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return append([]stateTransition(nil), r.transitions...)
+}
+
+func TestClient_Status(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		var recorder stateRecorder
+
+		c, err := New("localhost:11211", 2, WithConnStateCallback(recorder.callback))
+		assert.Equal(t, nil, err)
+
+		assert.Equal(t, []ConnStatus{
+			{State: ConnStateConnected},
+			{State: ConnStateConnected},
+		}, c.Status())
+
+		err = c.Close()
+		assert.Equal(t, nil, err)
+
+		assert.Equal(t, []ConnStatus{
+			{State: ConnStateClosed},
+			{State: ConnStateClosed},
+		}, c.Status())
+
+		assert.Equal(t, []stateTransition{
+			{index: 0, prev: ConnStateConnecting, status: ConnStatus{State: ConnStateConnected}},
+			{index: 1, prev: ConnStateConnecting, status: ConnStatus{State: ConnStateConnected}},
+			{index: 0, prev: ConnStateConnected, status: ConnStatus{State: ConnStateClosed}},
+			{index: 1, prev: ConnStateConnected, status: ConnStatus{State: ConnStateClosed}},
+		}, recorder.getTransitions())
+	})
+
+	t.Run("dial error", func(t *testing.T) {
+		dialErr := errors.New("cannot connect to memcached")
+		dialFunc := func(network, address string, timeout time.Duration) (net.Conn, error) {
+			return nil, dialErr
+		}
+
+		c, err := New("localhost:11211", 1,
+			WithDialFunc(dialFunc),
+			WithDialErrorLogger(func(err error) {}),
+			WithRetryDuration(10*time.Millisecond),
+		)
+		assert.Equal(t, nil, err)
+
+		time.Sleep(30 * time.Millisecond)
+
+		assert.Equal(t, []ConnStatus{
+			{State: ConnStateReconnecting, LastError: dialErr},
+		}, c.Status())
+
+		err = c.Close()
+		assert.Equal(t, nil, err)
+
+		assert.Equal(t, []ConnStatus{
+			{State: ConnStateClosed, LastError: dialErr},
+		}, c.Status())
+	})
+
+	t.Run("connection closed and reconnected", func(t *testing.T) {
+		var recorder stateRecorder
+
+		counter := uint64(0)
+		var connection net.Conn
+		dialFunc := func(network, address string, timeout time.Duration) (net.Conn, error) {
+			if atomic.AddUint64(&counter, 1) == 2 {
+				return nil, errors.New("cannot connect to memcached")
+			}
+
+			conn, err := net.Dial(network, address)
+			if err != nil {
+				panic(err)
+			}
+			connection = conn
+			return connection, nil
+		}
+
+		c, err := New("localhost:11211", 1,
+			WithDialFunc(dialFunc),
+			WithDialErrorLogger(func(err error) {}),
+			WithRetryDuration(10*time.Millisecond),
+			WithConnStateCallback(recorder.callback),
+		)
+		assert.Equal(t, nil, err)
+		defer func() { _ = c.Close() }()
+
+		p := c.Pipeline()
+		defer p.Finish()
+
+		pipelineFlushAll(p)
+
+		err = connection.Close()
+		assert.Equal(t, nil, err)
+
+		_, err = p.MGet("key01", MGetOptions{})()
+		assert.NotNil(t, err)
+
+		time.Sleep(30 * time.Millisecond)
+
+		_, err = p.MGet("key01", MGetOptions{})()
+		assert.Equal(t, nil, err)
+
+		status := c.Status()
+		assert.Equal(t, 1, len(status))
+		assert.Equal(t, ConnStateConnected, status[0].State)
+		assert.Equal(t, uint64(1), status[0].ReconnectCount)
+		assert.Equal(t, errors.New("cannot connect to memcached"), status[0].LastError)
+		assert.Equal(t, uint64(0), status[0].InFlightCommands)
+
+		transitions := recorder.getTransitions()[1:]
+		assert.Equal(t, 2, len(transitions))
+
+		assert.Equal(t, ConnStateConnected, transitions[0].prev)
+		assert.Equal(t, ConnStateReconnecting, transitions[0].status.State)
+		assert.NotNil(t, transitions[0].status.LastError)
+
+		assert.Equal(t, ConnStateReconnecting, transitions[1].prev)
+		assert.Equal(t, ConnStateConnected, transitions[1].status.State)
+		assert.Equal(t, uint64(1), transitions[1].status.ReconnectCount)
+	})
+
+	t.Run("health check probe time", func(t *testing.T) {
+		c, err := New("localhost:11211", 1, WithHealthCheckDuration(50*time.Millisecond))
+		assert.Equal(t, nil, err)
+		defer func() { _ = c.Close() }()
+
+		beforeProbe := time.Now()
+		time.Sleep(80 * time.Millisecond)
+
+		status := c.Status()
+		assert.Equal(t, ConnStateConnected, status[0].State)
+		assert.Equal(t, nil, status[0].LastError)
+		assert.True(t, status[0].LastProbeTime.After(beforeProbe))
+	})
+}
+
+func TestConnWriteLimiter_InFlightCount(t *testing.T) {
+	var l connWriteLimiter
+
+	assert.Equal(t, uint64(0), l.inFlightCount())
+
+	l.addWriteCount(3)
+	assert.Equal(t, uint64(3), l.inFlightCount())
+
+	l.cmdReadCount.Add(2)
+	assert.Equal(t, uint64(1), l.inFlightCount())
+}
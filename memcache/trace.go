@@ -0,0 +1,232 @@
+package memcache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+type traceConfig struct {
+	logger *slog.Logger
+	level  slog.Level
+
+	redactKeys  bool
+	maxValueLen int
+	sampleRate  float64
+}
+
+// TraceOption configures the protocol trace logging of WithProtocolTrace
+type TraceOption func(conf *traceConfig)
+
+// WithTraceLevel sets the log level of trace records, default is slog.LevelDebug
+func WithTraceLevel(level slog.Level) TraceOption {
+	return func(conf *traceConfig) {
+		conf.level = level
+	}
+}
+
+// WithTraceRedactKeys replaces keys by their hashes, such that the same key is still recognizable across records
+func WithTraceRedactKeys() TraceOption {
+	return func(conf *traceConfig) {
+		conf.redactKeys = true
+	}
+}
+
+// WithTraceMaxValueLen truncates values longer than **n** bytes, default is 64. Zero hides the values completely
+func WithTraceMaxValueLen(n int) TraceOption {
+	return func(conf *traceConfig) {
+		conf.maxValueLen = n
+	}
+}
+
+// WithTraceSampleRate only logs a fraction (from 0 to 1) of the request batches, default is 1 (logs all batches).
+// The request & the response of a batch are sampled together.
+func WithTraceSampleRate(rate float64) TraceOption {
+	return func(conf *traceConfig) {
+		conf.sampleRate = rate
+	}
+}
+
+// WithProtocolTrace logs every request batch written to and every response batch read from the TCP connections.
+// Records of the requests have message "memcache: request", of the responses have message "memcache: response",
+// with attributes: "conn" (the connection index), "commands" (the number of commands in the batch) and "data".
+// The responses are logged after parsed, because a single network read can contain parts of many batches.
+//
+// Logging every byte is expensive, consider WithTraceSampleRate when enabling it in production.
+func WithProtocolTrace(logger *slog.Logger, options ...TraceOption) Option {
+	conf := &traceConfig{
+		logger:      logger,
+		level:       slog.LevelDebug,
+		maxValueLen: 64,
+		sampleRate:  1,
+	}
+	for _, fn := range options {
+		fn(conf)
+	}
+
+	return func(opts *memcacheOptions) {
+		opts.traceConf = conf
+	}
+}
+
+// protocolTracer logs the data of a single connection, nil means tracing is disabled
+type protocolTracer struct {
+	conf      *traceConfig
+	connIndex int
+}
+
+func newProtocolTracer(conf *traceConfig, connIndex int) *protocolTracer {
+	if conf == nil || conf.logger == nil {
+		return nil
+	}
+	return &protocolTracer{
+		conf:      conf,
+		connIndex: connIndex,
+	}
+}
+
+func (t *protocolTracer) enabled() bool {
+	return t.conf.logger.Enabled(context.Background(), t.conf.level)
+}
+
+func (t *protocolTracer) sample() bool {
+	if t == nil || !t.enabled() {
+		return false
+	}
+	if t.conf.sampleRate >= 1 {
+		return true
+	}
+	return rand.Float64() < t.conf.sampleRate
+}
+
+func (t *protocolTracer) traceRequest(cmd *commandListData) {
+	var buf bytes.Buffer
+	_ = cmd.writeToWriter(&buf)
+
+	t.conf.logger.LogAttrs(context.Background(), t.conf.level, "memcache: request",
+		slog.Int("conn", t.connIndex),
+		slog.Int("commands", cmd.cmdCount),
+		slog.String("data", t.formatRequest(buf.Bytes())),
+	)
+}
+
+func (t *protocolTracer) traceResponse(cmd *commandListData, err error) {
+	attrs := []slog.Attr{
+		slog.Int("conn", t.connIndex),
+		slog.Int("commands", cmd.cmdCount),
+		slog.String("data", t.formatResponse(cmd.responseData, cmd.responseBinaries)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	t.conf.logger.LogAttrs(context.Background(), t.conf.level, "memcache: response", attrs...)
+}
+
+// splitLine returns the first line including the CRLF, and the remaining data
+func splitLine(data []byte) ([]byte, []byte) {
+	index := bytes.Index(data, []byte("\r\n"))
+	if index < 0 {
+		return data, nil
+	}
+	return data[:index+2], data[index+2:]
+}
+
+func (t *protocolTracer) formatRequest(data []byte) string {
+	var b strings.Builder
+	for len(data) > 0 {
+		var line []byte
+		line, data = splitLine(data)
+
+		fields := strings.Fields(string(line))
+		if len(fields) >= 2 && (fields[0] == "mg" || fields[0] == "ms" || fields[0] == "md") {
+			fields[1] = t.redactKey(fields[1])
+		}
+		t.writeLine(&b, fields, line)
+
+		if len(fields) < 3 || fields[0] != "ms" {
+			continue
+		}
+
+		dataLen, err := strconv.Atoi(fields[2])
+		if err != nil || dataLen < 0 {
+			continue
+		}
+		dataLen += 2 // CR + LF
+		if dataLen > len(data) {
+			dataLen = len(data)
+		}
+		t.writeValue(&b, bytes.TrimSuffix(data[:dataLen], []byte("\r\n")))
+		data = data[dataLen:]
+	}
+	return b.String()
+}
+
+var respTypesWithFlags = map[string]struct{}{
+	"VA": {},
+	"HD": {},
+	"NF": {},
+	"NS": {},
+	"EX": {},
+}
+
+func (t *protocolTracer) formatResponse(data []byte, binaries [][]byte) string {
+	var b strings.Builder
+	for len(data) > 0 {
+		var line []byte
+		line, data = splitLine(data)
+
+		fields := strings.Fields(string(line))
+		if len(fields) > 0 {
+			if _, ok := respTypesWithFlags[fields[0]]; ok {
+				t.redactKeyFlags(fields[1:])
+			}
+		}
+		t.writeLine(&b, fields, line)
+
+		if len(fields) == 0 || fields[0] != "VA" || len(binaries) == 0 {
+			continue
+		}
+		t.writeValue(&b, binaries[0])
+		binaries = binaries[1:]
+	}
+	return b.String()
+}
+
+func (t *protocolTracer) redactKeyFlags(flags []string) {
+	for i, flag := range flags {
+		if strings.HasPrefix(flag, "k") {
+			flags[i] = "k" + t.redactKey(flag[1:])
+		}
+	}
+}
+
+func (t *protocolTracer) redactKey(key string) string {
+	if !t.conf.redactKeys {
+		return key
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return fmt.Sprintf("<redacted:%08x>", h.Sum32())
+}
+
+func (t *protocolTracer) writeLine(b *strings.Builder, fields []string, line []byte) {
+	_, _ = b.WriteString(strings.Join(fields, " "))
+	if bytes.HasSuffix(line, []byte("\r\n")) {
+		_, _ = b.WriteString("\r\n")
+	}
+}
+
+func (t *protocolTracer) writeValue(b *strings.Builder, value []byte) {
+	if len(value) <= t.conf.maxValueLen {
+		_, _ = b.Write(value)
+	} else {
+		_, _ = b.Write(value[:t.conf.maxValueLen])
+		_, _ = fmt.Fprintf(b, "...(%d bytes)", len(value))
+	}
+	_, _ = b.WriteString("\r\n")
+}
@@ -0,0 +1,188 @@
+package memcache
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTracer(options ...TraceOption) *protocolTracer {
+	opt := WithProtocolTrace(slog.Default(), options...)
+	opts := computeOptions(opt)
+	return newProtocolTracer(opts.traceConf, 2)
+}
+
+func TestProtocolTracer_Format_Request(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		tracer := newTestTracer()
+		result := tracer.formatRequest([]byte("mg key01 v\r\nms key02 5 T30\r\nhello\r\nmd key03\r\nmn\r\n"))
+		assert.Equal(t, "mg key01 v\r\nms key02 5 T30\r\nhello\r\nmd key03\r\nmn\r\n", result)
+	})
+
+	t.Run("redact keys", func(t *testing.T) {
+		tracer := newTestTracer(WithTraceRedactKeys())
+		result := tracer.formatRequest([]byte("mg key01 v\r\nms key01 5\r\nhello\r\nversion\r\n"))
+		assert.Equal(t,
+			"mg <redacted:5c23207f> v\r\nms <redacted:5c23207f> 5\r\nhello\r\nversion\r\n",
+			result,
+		)
+	})
+
+	t.Run("truncate values", func(t *testing.T) {
+		tracer := newTestTracer(WithTraceMaxValueLen(4))
+		result := tracer.formatRequest([]byte("ms key01 10\r\n0123456789\r\nms key02 3\r\nabc\r\n"))
+		assert.Equal(t, "ms key01 10\r\n0123...(10 bytes)\r\nms key02 3\r\nabc\r\n", result)
+	})
+
+	t.Run("value with CRLF inside", func(t *testing.T) {
+		tracer := newTestTracer()
+		result := tracer.formatRequest([]byte("ms key01 4\r\na\r\nb\r\nmn\r\n"))
+		assert.Equal(t, "ms key01 4\r\na\r\nb\r\nmn\r\n", result)
+	})
+}
+
+func TestProtocolTracer_Format_Response(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		tracer := newTestTracer()
+		result := tracer.formatResponse(
+			[]byte("VA 5 c12 kkey01\r\nEN\r\nHD\r\nVA 3\r\n"),
+			[][]byte{[]byte("hello"), []byte("abc")},
+		)
+		assert.Equal(t, "VA 5 c12 kkey01\r\nhello\r\nEN\r\nHD\r\nVA 3\r\nabc\r\n", result)
+	})
+
+	t.Run("redact keys and truncate values", func(t *testing.T) {
+		tracer := newTestTracer(WithTraceRedactKeys(), WithTraceMaxValueLen(0))
+		result := tracer.formatResponse(
+			[]byte("VA 5 kkey01\r\nHD kkey01\r\nSERVER_ERROR key too long\r\n"),
+			[][]byte{[]byte("hello")},
+		)
+		assert.Equal(t,
+			"VA 5 k<redacted:5c23207f>\r\n...(5 bytes)\r\nHD k<redacted:5c23207f>\r\nSERVER_ERROR key too long\r\n",
+			result,
+		)
+	})
+}
+
+type traceRecord struct {
+	msg   string
+	attrs map[string]string
+}
+
+type traceRecorder struct {
+	mut     sync.Mutex
+	records []traceRecord
+}
+
+func (r *traceRecorder) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (r *traceRecorder) Handle(_ context.Context, record slog.Record) error {
+	attrs := map[string]string{}
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.String()
+		return true
+	})
+
+	r.mut.Lock()
+	r.records = append(r.records, traceRecord{msg: record.Message, attrs: attrs})
+	r.mut.Unlock()
+	return nil
+}
+
+func (r *traceRecorder) WithAttrs([]slog.Attr) slog.Handler {
+	return r
+}
+
+func (r *traceRecorder) WithGroup(string) slog.Handler {
+	return r
+}
+
+func (r *traceRecorder) getRecords() []traceRecord {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return append([]traceRecord(nil), r.records...)
+}
+
+func TestClient_Protocol_Trace(t *testing.T) {
+	t.Run("log requests and responses", func(t *testing.T) {
+		recorder := &traceRecorder{}
+
+		c, err := New("localhost:11211", 1, WithProtocolTrace(slog.New(recorder), WithTraceRedactKeys()))
+		assert.Equal(t, nil, err)
+		defer func() { _ = c.Close() }()
+
+		p := c.Pipeline()
+		defer p.Finish()
+
+		fn1 := p.MSet("trace-key01", []byte("value01"), MSetOptions{})
+		fn2 := p.MGet("trace-key01", MGetOptions{})
+		_, err = fn1()
+		assert.Equal(t, nil, err)
+		_, err = fn2()
+		assert.Equal(t, nil, err)
+
+		records := recorder.getRecords()
+		assert.Equal(t, 2, len(records))
+
+		assert.Equal(t, "memcache: request", records[0].msg)
+		assert.Equal(t, "0", records[0].attrs["conn"])
+		assert.Equal(t, "2", records[0].attrs["commands"])
+		assert.Equal(t,
+			"ms <redacted:a00a4ec3> 7\r\nvalue01\r\nmg <redacted:a00a4ec3> v\r\n",
+			records[0].attrs["data"],
+		)
+
+		assert.Equal(t, "memcache: response", records[1].msg)
+		assert.Equal(t, "2", records[1].attrs["commands"])
+		assert.Equal(t, "HD\r\nVA 7\r\nvalue01\r\n", records[1].attrs["data"])
+	})
+
+	t.Run("sampling", func(t *testing.T) {
+		recorder := &traceRecorder{}
+
+		c, err := New("localhost:11211", 1, WithProtocolTrace(slog.New(recorder), WithTraceSampleRate(0.3)))
+		assert.Equal(t, nil, err)
+		defer func() { _ = c.Close() }()
+
+		const numBatches = 300
+		for i := 0; i < numBatches; i++ {
+			p := c.Pipeline()
+			_, err := p.MGet("trace-key02", MGetOptions{})()
+			assert.Equal(t, nil, err)
+			p.Finish()
+		}
+
+		records := recorder.getRecords()
+		numRequests := 0
+		for _, r := range records {
+			if r.msg == "memcache: request" {
+				numRequests++
+			}
+		}
+		assert.Equal(t, 2*numRequests, len(records))
+		assert.Greater(t, numRequests, 30)
+		assert.Less(t, numRequests, 180)
+	})
+
+	t.Run("disabled level", func(t *testing.T) {
+		var buf strings.Builder
+		logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+		c, err := New("localhost:11211", 1, WithProtocolTrace(logger))
+		assert.Equal(t, nil, err)
+		defer func() { _ = c.Close() }()
+
+		p := c.Pipeline()
+		_, err = p.MGet("trace-key03", MGetOptions{})()
+		assert.Equal(t, nil, err)
+		p.Finish()
+
+		assert.Equal(t, "", buf.String())
+	})
+}
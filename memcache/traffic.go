@@ -0,0 +1,316 @@
+package memcache
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const trafficFileHeader = "memcache-traffic v1\n"
+
+// TrafficRecordType ...
+type TrafficRecordType string
+
+const (
+	// TrafficRecordRequest is a request batch
+	TrafficRecordRequest TrafficRecordType = "req"
+
+	// TrafficRecordResponse is the response of a request batch
+	TrafficRecordResponse TrafficRecordType = "resp"
+
+	// TrafficRecordError is when a request batch failed, e.g. because of the connection was broken
+	TrafficRecordError TrafficRecordType = "err"
+)
+
+// TrafficRecord is a single record of a traffic file.
+//
+// A traffic file starts with the header line:
+//
+//	memcache-traffic v1\n
+//
+// followed by a sequence of records, each record is a header line and a payload:
+//
+//	<type> <batch id> <conn index> <offset> <command count> <payload length>\n<payload>\n
+//
+// in which:
+//   - type: "req" for a request batch, "resp" for its response, "err" when the batch failed without a response
+//   - batch id: increasing from 1, a "resp" or "err" record has the same batch id as its "req" record
+//   - conn index: the index of the TCP connection in the Client
+//   - offset: nanoseconds since the start of the recording
+//   - command count: the number of memcache commands in the batch
+//   - payload: the exact bytes on the wire for "req" and "resp", the error message for "err"
+//
+// All numbers are in decimal. Batches of the same connection are recorded in the order they were sent.
+type TrafficRecord struct {
+	Type      TrafficRecordType
+	BatchID   uint64
+	ConnIndex int
+	Offset    time.Duration // since the start of the recording
+	CmdCount  int
+	Data      []byte
+}
+
+// ErrInvalidTrafficFile is returned when reading a traffic file with bad format
+var ErrInvalidTrafficFile = errors.New("memcache: invalid traffic file")
+
+// maxTrafficDataLen is the max length of the data of a traffic record
+const maxTrafficDataLen = 1 << 30
+
+// TrafficRecorder records the traffic of a Client into a traffic file, see WithTrafficRecorder
+type TrafficRecorder struct {
+	start time.Time
+
+	mut         sync.Mutex
+	writer      *bufio.Writer
+	nextBatchID uint64
+	stopped     bool
+	err         error
+}
+
+// NewTrafficRecorder creates a recorder writing to **w**, the recording starts right away
+func NewTrafficRecorder(w io.Writer) *TrafficRecorder {
+	r := &TrafficRecorder{
+		start:  time.Now(),
+		writer: bufio.NewWriter(w),
+	}
+	_, r.err = r.writer.WriteString(trafficFileHeader)
+	return r
+}
+
+// WithTrafficRecorder records request & response batches of the client, until the recorder is stopped
+func WithTrafficRecorder(recorder *TrafficRecorder) Option {
+	return func(opts *memcacheOptions) {
+		opts.trafficRecorder = recorder
+	}
+}
+
+// Stop stops recording, flushes the buffered data and returns the first write error, if any.
+// Responses of the batches that are still in flight are NOT recorded.
+func (r *TrafficRecorder) Stop() error {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if !r.stopped {
+		r.stopped = true
+		if r.err == nil {
+			r.err = r.writer.Flush()
+		}
+	}
+	return r.err
+}
+
+func (r *TrafficRecorder) writeRecordUnsafe(record TrafficRecord) {
+	if r.err != nil {
+		return
+	}
+	_, r.err = r.writer.Write(appendTrafficRecord(nil, record))
+}
+
+func (r *TrafficRecorder) recordRequest(connIndex int, cmd *commandListData) uint64 {
+	offset := time.Since(r.start)
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if r.stopped {
+		return 0
+	}
+
+	var buf bytes.Buffer
+	_ = cmd.writeToWriter(&buf)
+
+	r.nextBatchID++
+	id := r.nextBatchID
+
+	r.writeRecordUnsafe(TrafficRecord{
+		Type:      TrafficRecordRequest,
+		BatchID:   id,
+		ConnIndex: connIndex,
+		Offset:    offset,
+		CmdCount:  cmd.cmdCount,
+		Data:      buf.Bytes(),
+	})
+	return id
+}
+
+func (r *TrafficRecorder) recordResponse(connIndex int, cmd *commandListData, err error) {
+	record := TrafficRecord{
+		Type:      TrafficRecordResponse,
+		BatchID:   cmd.recordID,
+		ConnIndex: connIndex,
+		Offset:    time.Since(r.start),
+		CmdCount:  cmd.cmdCount,
+	}
+	if err != nil {
+		record.Type = TrafficRecordError
+		record.Data = []byte(err.Error())
+	} else {
+		record.Data = rawResponseBytes(cmd.responseData, cmd.responseBinaries)
+	}
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if r.stopped {
+		return
+	}
+	r.writeRecordUnsafe(record)
+}
+
+// connTrafficRecorder records the traffic of a single connection, nil means recording is disabled
+type connTrafficRecorder struct {
+	recorder  *TrafficRecorder
+	connIndex int
+}
+
+func newConnTrafficRecorder(recorder *TrafficRecorder, connIndex int) *connTrafficRecorder {
+	if recorder == nil {
+		return nil
+	}
+	return &connTrafficRecorder{
+		recorder:  recorder,
+		connIndex: connIndex,
+	}
+}
+
+// recordRequest returns the batch id, zero if not recorded
+func (r *connTrafficRecorder) recordRequest(cmd *commandListData) uint64 {
+	if r == nil {
+		return 0
+	}
+	return r.recorder.recordRequest(r.connIndex, cmd)
+}
+
+func (r *connTrafficRecorder) recordResponse(cmd *commandListData, err error) {
+	r.recorder.recordResponse(r.connIndex, cmd, err)
+}
+
+// rawResponseBytes rebuilds the bytes on the wire from the response data framed by responseReader,
+// in which the values of VA responses are stored separately in **binaries**
+func rawResponseBytes(data []byte, binaries [][]byte) []byte {
+	var result []byte
+	for len(data) > 0 {
+		var line []byte
+		line, data = splitLine(data)
+		result = append(result, line...)
+
+		if !bytes.HasPrefix(line, []byte("VA ")) || len(binaries) == 0 {
+			continue
+		}
+		result = append(result, binaries[0]...)
+		result = append(result, "\r\n"...)
+		binaries = binaries[1:]
+	}
+	return result
+}
+
+func appendTrafficRecord(buf []byte, record TrafficRecord) []byte {
+	buf = append(buf, record.Type...)
+	buf = append(buf, ' ')
+	buf = strconv.AppendUint(buf, record.BatchID, 10)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(record.ConnIndex), 10)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(record.Offset), 10)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(record.CmdCount), 10)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, int64(len(record.Data)), 10)
+	buf = append(buf, '\n')
+	buf = append(buf, record.Data...)
+	buf = append(buf, '\n')
+	return buf
+}
+
+// TrafficReader reads records of a traffic file
+type TrafficReader struct {
+	reader *bufio.Reader
+}
+
+// NewTrafficReader checks the header of the traffic file
+func NewTrafficReader(r io.Reader) (*TrafficReader, error) {
+	reader := bufio.NewReader(r)
+
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrInvalidTrafficFile
+		}
+		return nil, err
+	}
+	if header != trafficFileHeader {
+		return nil, ErrInvalidTrafficFile
+	}
+
+	return &TrafficReader{reader: reader}, nil
+}
+
+func parseTrafficRecordHeader(line string) (TrafficRecord, int, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 6 {
+		return TrafficRecord{}, 0, ErrInvalidTrafficFile
+	}
+
+	recordType := TrafficRecordType(fields[0])
+	switch recordType {
+	case TrafficRecordRequest, TrafficRecordResponse, TrafficRecordError:
+	default:
+		return TrafficRecord{}, 0, ErrInvalidTrafficFile
+	}
+
+	var nums [5]int64
+	for i := range nums {
+		num, err := strconv.ParseInt(fields[i+1], 10, 64)
+		if err != nil || num < 0 {
+			return TrafficRecord{}, 0, ErrInvalidTrafficFile
+		}
+		nums[i] = num
+	}
+
+	if nums[4] > maxTrafficDataLen {
+		return TrafficRecord{}, 0, ErrInvalidTrafficFile
+	}
+
+	return TrafficRecord{
+		Type:      recordType,
+		BatchID:   uint64(nums[0]),
+		ConnIndex: int(nums[1]),
+		Offset:    time.Duration(nums[2]),
+		CmdCount:  int(nums[3]),
+	}, int(nums[4]), nil
+}
+
+// Next returns the next record, io.EOF at the end of the file
+func (r *TrafficReader) Next() (TrafficRecord, error) {
+	line, err := r.reader.ReadString('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(line) > 0 {
+			return TrafficRecord{}, ErrInvalidTrafficFile
+		}
+		return TrafficRecord{}, err
+	}
+
+	record, dataLen, err := parseTrafficRecordHeader(line)
+	if err != nil {
+		return TrafficRecord{}, err
+	}
+
+	// grows while reading, such that a truncated file can NOT force a large allocation
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, r.reader, int64(dataLen)+1); err != nil {
+		return TrafficRecord{}, ErrInvalidTrafficFile
+	}
+
+	data := buf.Bytes()
+	if data[dataLen] != '\n' {
+		return TrafficRecord{}, ErrInvalidTrafficFile
+	}
+
+	record.Data = data[:dataLen]
+	return record, nil
+}
@@ -0,0 +1,266 @@
+package memcache
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/QuangTung97/go-memcache/memcache/memcachetest"
+)
+
+func TestTrafficFile_Write_And_Read(t *testing.T) {
+	records := []TrafficRecord{
+		{
+			Type:      TrafficRecordRequest,
+			BatchID:   1,
+			ConnIndex: 2,
+			Offset:    1500 * time.Microsecond,
+			CmdCount:  2,
+			Data:      []byte("mg key01 v\r\nms key02 3\r\nabc\r\n"),
+		},
+		{
+			Type:      TrafficRecordResponse,
+			BatchID:   1,
+			ConnIndex: 2,
+			Offset:    2 * time.Millisecond,
+			CmdCount:  2,
+			Data:      []byte("EN\r\nHD\r\n"),
+		},
+		{
+			Type:     TrafficRecordError,
+			BatchID:  2,
+			CmdCount: 1,
+			Data:     []byte("memcache: connection closed"),
+		},
+	}
+
+	var buf []byte
+	buf = append(buf, trafficFileHeader...)
+	for _, r := range records {
+		buf = appendTrafficRecord(buf, r)
+	}
+
+	assert.Equal(t, "memcache-traffic v1\n"+
+		"req 1 2 1500000 2 29\nmg key01 v\r\nms key02 3\r\nabc\r\n\n"+
+		"resp 1 2 2000000 2 8\nEN\r\nHD\r\n\n"+
+		"err 2 0 0 1 27\nmemcache: connection closed\n",
+		string(buf),
+	)
+
+	reader, err := NewTrafficReader(bytes.NewReader(buf))
+	assert.Equal(t, nil, err)
+
+	for _, expected := range records {
+		record, err := reader.Next()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, expected, record)
+	}
+
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestTrafficReader_Invalid(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		_, err := NewTrafficReader(strings.NewReader(""))
+		assert.Equal(t, ErrInvalidTrafficFile, err)
+	})
+
+	t.Run("wrong header", func(t *testing.T) {
+		_, err := NewTrafficReader(strings.NewReader("memcache-traffic v2\n"))
+		assert.Equal(t, ErrInvalidTrafficFile, err)
+	})
+
+	invalidRecords := map[string]string{
+		"unknown type":      "abc 1 0 0 1 4\nmn\r\n\n",
+		"missing field":     "req 1 0 0 4\nmn\r\n\n",
+		"negative number":   "req 1 -1 0 1 4\nmn\r\n\n",
+		"truncated payload": "req 1 0 0 1 4\nmn\r",
+		"no trailing LF":    "req 1 0 0 1 4\nmn\r\nx",
+		"truncated header":  "req 1 0 0",
+		"too large payload": "req 1 0 0 1 2000000000\nmn\r\n\n",
+		"truncated large":   "req 1 0 0 1 1000000000\nmn\r\n\n",
+	}
+	for name, data := range invalidRecords {
+		t.Run(name, func(t *testing.T) {
+			reader, err := NewTrafficReader(strings.NewReader(trafficFileHeader + data))
+			assert.Equal(t, nil, err)
+
+			_, err = reader.Next()
+			assert.Equal(t, ErrInvalidTrafficFile, err)
+		})
+	}
+}
+
+func TestRawResponseBytes(t *testing.T) {
+	result := rawResponseBytes(
+		[]byte("VA 5 c12\r\nEN\r\nHD\r\nVA 0\r\n"),
+		[][]byte{[]byte("hello"), nil},
+	)
+	assert.Equal(t, "VA 5 c12\r\nhello\r\nEN\r\nHD\r\nVA 0\r\n\r\n", string(result))
+}
+
+func newTrafficTestServer(t *testing.T) *memcachetest.Server {
+	server, err := memcachetest.NewServer()
+	assert.Equal(t, nil, err)
+	t.Cleanup(func() { _ = server.Close() })
+	return server
+}
+
+func recordTestTraffic(t *testing.T, addr string) []byte {
+	var buf bytes.Buffer
+	recorder := NewTrafficRecorder(&buf)
+
+	c, err := New(addr, 2, WithTrafficRecorder(recorder))
+	assert.Equal(t, nil, err)
+
+	p := c.Pipeline()
+
+	_, err = p.MGet("key01", MGetOptions{})()
+	assert.Equal(t, nil, err)
+
+	fn1 := p.MSet("key01", []byte("value01"), MSetOptions{})
+	fn2 := p.MSet("key02", []byte(strings.Repeat("x", 1000)), MSetOptions{})
+	_, _ = fn1()
+	_, _ = fn2()
+
+	p.Finish()
+
+	p = c.Pipeline()
+	fn3 := p.MGet("key01", MGetOptions{})
+	fn4 := p.MGet("key02", MGetOptions{})
+	fn5 := p.MDel("key03", MDelOptions{})
+	_, _ = fn3()
+	_, _ = fn4()
+	_, _ = fn5()
+	p.Finish()
+
+	assert.Equal(t, nil, recorder.Stop())
+	assert.Equal(t, nil, c.Close())
+
+	return buf.Bytes()
+}
+
+func TestTrafficRecorder_Record_And_Replay(t *testing.T) {
+	server := newTrafficTestServer(t)
+	data := recordTestTraffic(t, server.Addr())
+
+	reader, err := NewTrafficReader(bytes.NewReader(data))
+	assert.Equal(t, nil, err)
+
+	var records []TrafficRecord
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Equal(t, nil, err)
+		records = append(records, record)
+	}
+
+	assert.Equal(t, 6, len(records))
+
+	assert.Equal(t, TrafficRecordRequest, records[0].Type)
+	assert.Equal(t, uint64(1), records[0].BatchID)
+	assert.Equal(t, "mg key01 v\r\n", string(records[0].Data))
+	assert.Equal(t, TrafficRecordResponse, records[1].Type)
+	assert.Equal(t, uint64(1), records[1].BatchID)
+	assert.Equal(t, "EN\r\n", string(records[1].Data))
+
+	assert.Equal(t, "ms key01 7\r\nvalue01\r\nms key02 1000\r\n"+strings.Repeat("x", 1000)+"\r\n",
+		string(records[2].Data))
+	assert.Equal(t, 2, records[2].CmdCount)
+	assert.Equal(t, "HD\r\nHD\r\n", string(records[3].Data))
+
+	assert.Equal(t, "VA 7\r\nvalue01\r\nVA 1000\r\n"+strings.Repeat("x", 1000)+"\r\nNF\r\n",
+		string(records[5].Data))
+	assert.Equal(t, 3, records[5].CmdCount)
+
+	assert.LessOrEqual(t, records[0].Offset, records[1].Offset)
+	assert.LessOrEqual(t, records[1].Offset, records[2].Offset)
+
+	t.Run("replay against a fresh server", func(t *testing.T) {
+		newServer := newTrafficTestServer(t)
+
+		var mut sync.Mutex
+		var mismatches []ReplayMismatch
+		stats, err := ReplayTraffic(newServer.Addr(), bytes.NewReader(data),
+			WithReplayMismatchHandler(func(m ReplayMismatch) {
+				mut.Lock()
+				mismatches = append(mismatches, m)
+				mut.Unlock()
+			}),
+		)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 0, len(mismatches))
+
+		assert.Equal(t, 3, stats.NumBatches)
+		assert.Equal(t, 6, stats.NumCommands)
+		assert.Equal(t, 0, stats.NumMismatches)
+		assert.Equal(t, 3, len(stats.Latencies))
+		for _, latency := range stats.Latencies {
+			assert.Greater(t, latency, time.Duration(0))
+		}
+	})
+
+	t.Run("replay against the same server", func(t *testing.T) {
+		var mut sync.Mutex
+		var mismatches []ReplayMismatch
+		stats, err := ReplayTraffic(server.Addr(), bytes.NewReader(data),
+			WithReplayMismatchHandler(func(m ReplayMismatch) {
+				mut.Lock()
+				mismatches = append(mismatches, m)
+				mut.Unlock()
+			}),
+		)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 1, stats.NumMismatches)
+		assert.Equal(t, []ReplayMismatch{
+			{
+				BatchID:   1,
+				ConnIndex: records[0].ConnIndex,
+				Request:   []byte("mg key01 v\r\n"),
+				Expected:  []byte("EN\r\n"),
+				Actual:    []byte("VA 7\r\nvalue01\r\n"),
+			},
+		}, mismatches)
+	})
+
+	t.Run("replay with speed", func(t *testing.T) {
+		newServer := newTrafficTestServer(t)
+
+		stats, err := ReplayTraffic(newServer.Addr(), bytes.NewReader(data), WithReplaySpeed(1))
+		assert.Equal(t, nil, err)
+		assert.GreaterOrEqual(t, stats.Duration, records[4].Offset)
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		_, err := ReplayTraffic(server.Addr(), strings.NewReader("abcd\n"))
+		assert.Equal(t, ErrInvalidTrafficFile, err)
+	})
+}
+
+func TestTrafficRecorder_Stop(t *testing.T) {
+	server := newTrafficTestServer(t)
+
+	var buf bytes.Buffer
+	recorder := NewTrafficRecorder(&buf)
+	assert.Equal(t, nil, recorder.Stop())
+	assert.Equal(t, nil, recorder.Stop())
+
+	c, err := New(server.Addr(), 1, WithTrafficRecorder(recorder))
+	assert.Equal(t, nil, err)
+	defer func() { _ = c.Close() }()
+
+	p := c.Pipeline()
+	_, err = p.MGet("key01", MGetOptions{})()
+	assert.Equal(t, nil, err)
+	p.Finish()
+
+	assert.Equal(t, trafficFileHeader, buf.String())
+}
@@ -8,12 +8,14 @@ import (
 	"hash/crc32"
 	"math"
 	"math/rand"
+	"os"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/QuangTung97/go-memcache/memcache"
+	"github.com/QuangTung97/go-memcache/memcache/memcachetest"
 )
 
 type propertyTest struct {
@@ -30,8 +32,24 @@ type propertyTest struct {
 	client *memcache.Client
 }
 
+// getMemcacheAddr returns the address in env MEMCACHE_ADDR if exists (e.g. localhost:11211 for real memcached),
+// otherwise starts an in-process fake memcached server
+func getMemcacheAddr(t *testing.T) string {
+	if addr := os.Getenv("MEMCACHE_ADDR"); addr != "" {
+		return addr
+	}
+
+	server, err := memcachetest.NewServer()
+	if err != nil {
+		panic(err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+
+	return server.Addr()
+}
+
 func newPropertyTest(t *testing.T, numConns int, options ...memcache.Option) *propertyTest {
-	client, err := memcache.New("localhost:11211", numConns, options...)
+	client, err := memcache.New(getMemcacheAddr(t), numConns, options...)
 	if err != nil {
 		panic(err)
 	}